@@ -0,0 +1,203 @@
+// Package auth описывает подпись запросов пира к трекеру.
+// Пир подписывает метод, адрес получателя, свою личность, время, nonce и тело запроса
+// ключом ed25519, трекер проверяет подпись в перехватчике.
+// Подписанный запрос нельзя переслать другому получателю: тот не узнает в нем свой адрес.
+// peer_id выводится из ключа, так что выступить под чужим peer_id нельзя.
+package auth
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+// ключи метаданных подписанного запроса
+const (
+	TargetKey    = "target"
+	PeerIDKey    = "peer_id"
+	AddressKey   = "address"
+	PublicKeyKey = "public_key"
+	TimestampKey = "timestamp"
+	NonceKey     = "nonce"
+	SignatureKey = "signature"
+)
+
+// MaxClockSkew - насколько время запроса может расходиться со временем трекера
+const MaxClockSkew = 5 * time.Minute
+
+// Identity - проверенная подписью личность отправителя
+type Identity struct {
+	Target    string // адрес, по которому отправитель обращался к получателю
+	PeerID    string
+	Address   string
+	PublicKey ed25519.PublicKey
+	Nonce     string
+	Timestamp time.Time
+}
+
+// PeerID - peer_id пира с ключом key: uuid из первых байт sha256 ключа.
+// Биты версии выставлены как у uuid версии 8, собственного формата.
+func PeerID(key ed25519.PublicKey) string {
+	sum := sha256.Sum256(key)
+	sum[6] = sum[6]&0x0f | 0x80
+	sum[8] = sum[8]&0x3f | 0x80
+
+	return hex.EncodeToString(sum[0:4]) + "-" + hex.EncodeToString(sum[4:6]) + "-" +
+		hex.EncodeToString(sum[6:8]) + "-" + hex.EncodeToString(sum[8:10]) + "-" + hex.EncodeToString(sum[10:16])
+}
+
+// payload - подписываемые байты: все поля через перевод строки и sha256 тела
+func payload(method string, id *Identity, req proto.Message) ([]byte, error) {
+	body, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(body)
+
+	var b bytes.Buffer
+	for _, v := range []string{
+		method,
+		id.Target,
+		id.PeerID,
+		id.Address,
+		hex.EncodeToString(id.PublicKey),
+		strconv.FormatInt(id.Timestamp.UnixNano(), 10),
+		id.Nonce,
+		hex.EncodeToString(sum[:]),
+	} {
+		b.WriteString(v)
+		b.WriteByte('\n')
+	}
+
+	return b.Bytes(), nil
+}
+
+// Sign возвращает метаданные запроса к получателю target, peer_id выводится из ключа
+func Sign(key ed25519.PrivateKey, address, target, method string, req proto.Message) (metadata.MD, error) {
+	nonce := make([]byte, 16)
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey := key.Public().(ed25519.PublicKey)
+
+	id := &Identity{
+		Target:    target,
+		PeerID:    PeerID(publicKey),
+		Address:   address,
+		PublicKey: publicKey,
+		Nonce:     hex.EncodeToString(nonce),
+		Timestamp: time.Now(),
+	}
+
+	data, err := payload(method, id, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return metadata.Pairs(
+		TargetKey, id.Target,
+		PeerIDKey, id.PeerID,
+		AddressKey, id.Address,
+		PublicKeyKey, base64.StdEncoding.EncodeToString(id.PublicKey),
+		TimestampKey, strconv.FormatInt(id.Timestamp.UnixNano(), 10),
+		NonceKey, id.Nonce,
+		SignatureKey, base64.StdEncoding.EncodeToString(ed25519.Sign(key, data)),
+	), nil
+}
+
+func single(md metadata.MD, key string) (string, error) {
+	v := md.Get(key)
+	if len(v) != 1 {
+		return "", errors.New("missing or repeated " + key)
+	}
+
+	return v[0], nil
+}
+
+// Verify проверяет подпись, свежесть запроса, то, что peer_id выведен из ключа,
+// и то, что запрос подписан для одного из адресов получателя targets.
+// Повтор nonce проверяет вызывающий.
+func Verify(md metadata.MD, targets []string, method string, req proto.Message, now time.Time) (*Identity, error) {
+	fields := make(map[string]string)
+	for _, key := range []string{TargetKey, PeerIDKey, AddressKey, PublicKeyKey, TimestampKey, NonceKey, SignatureKey} {
+		v, err := single(md, key)
+		if err != nil {
+			return nil, err
+		}
+		fields[key] = v
+	}
+
+	publicKey, err := base64.StdEncoding.DecodeString(fields[PublicKeyKey])
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return nil, errors.New("invalid public key")
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(fields[SignatureKey])
+	if err != nil {
+		return nil, errors.New("invalid signature encoding")
+	}
+
+	ts, err := strconv.ParseInt(fields[TimestampKey], 10, 64)
+	if err != nil {
+		return nil, errors.New("invalid timestamp")
+	}
+
+	id := &Identity{
+		Target:    fields[TargetKey],
+		PeerID:    fields[PeerIDKey],
+		Address:   fields[AddressKey],
+		PublicKey: publicKey,
+		Nonce:     fields[NonceKey],
+		Timestamp: time.Unix(0, ts),
+	}
+
+	if id.PeerID != PeerID(id.PublicKey) {
+		return nil, errors.New("peer id doesnt match public key")
+	}
+
+	if !isTarget(targets, id.Target) {
+		return nil, errors.New("request is signed for another receiver")
+	}
+
+	skew := now.Sub(id.Timestamp)
+	if skew > MaxClockSkew || skew < -MaxClockSkew {
+		return nil, errors.New("request timestamp out of range")
+	}
+
+	if id.Nonce == "" {
+		return nil, errors.New("empty nonce")
+	}
+
+	data, err := payload(method, id, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ed25519.Verify(id.PublicKey, data, signature) {
+		return nil, errors.New("bad signature")
+	}
+
+	return id, nil
+}
+
+func isTarget(targets []string, target string) bool {
+	for _, t := range targets {
+		if t == target {
+			return true
+		}
+	}
+
+	return false
+}
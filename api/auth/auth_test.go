@@ -0,0 +1,270 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/elizarpif/grpctorrent/api"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	method  = "/api.Tracker/GetPeers"
+	tracker = "localhost:9000"
+)
+
+var trackers = []string{tracker, "127.0.0.1:9000"}
+
+func newKey(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key
+}
+
+func TestSignVerify(t *testing.T) {
+	key := newKey(t)
+	peerID := PeerID(key.Public().(ed25519.PublicKey))
+	req := &api.GetPeersRequest{HashFile: "abc", PeerId: peerID}
+
+	md, err := Sign(key, "localhost:9002", tracker, method, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := Verify(md, trackers, method, req, time.Now())
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+
+	if id.PeerID != peerID || id.Target != tracker || id.Address != "localhost:9002" || !bytes.Equal(id.PublicKey, key.Public().(ed25519.PublicKey)) || id.Nonce == "" {
+		t.Errorf("unexpected identity %+v", id)
+	}
+
+	// каждый запрос подписывается со своим nonce
+	md2, err := Sign(key, "localhost:9002", tracker, method, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md.Get(NonceKey)[0] == md2.Get(NonceKey)[0] {
+		t.Error("nonce repeated")
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	key := newKey(t)
+	other := newKey(t)
+	peerID := PeerID(key.Public().(ed25519.PublicKey))
+	req := &api.GetPeersRequest{HashFile: "abc", PeerId: peerID}
+
+	sign := func() metadata.MD {
+		md, err := Sign(key, "localhost:9002", tracker, method, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return md
+	}
+
+	set := func(k, v string) func(metadata.MD) {
+		return func(md metadata.MD) { md.Set(k, v) }
+	}
+
+	tests := []struct {
+		name   string
+		change func(md metadata.MD)
+		method string
+		req    *api.GetPeersRequest
+		now    time.Time
+	}{
+		{name: "tampered body", req: &api.GetPeersRequest{HashFile: "abd", PeerId: peerID}},
+		{name: "other method", method: "/api.Tracker/Upload"},
+		{name: "other peer id", change: set(PeerIDKey, PeerID(other.Public().(ed25519.PublicKey)))},
+		{name: "other address", change: set(AddressKey, "localhost:6666")},
+		{name: "other target", change: set(TargetKey, "127.0.0.1:9000")},
+		{name: "missing target", change: func(md metadata.MD) { delete(md, TargetKey) }},
+		{name: "other nonce", change: set(NonceKey, "00")},
+		{name: "empty nonce", change: set(NonceKey, "")},
+		{name: "other key", change: set(PublicKeyKey, base64.StdEncoding.EncodeToString(other.Public().(ed25519.PublicKey)))},
+		{name: "short key", change: set(PublicKeyKey, base64.StdEncoding.EncodeToString([]byte("short")))},
+		{name: "bad signature", change: set(SignatureKey, base64.StdEncoding.EncodeToString(make([]byte, ed25519.SignatureSize)))},
+		{name: "signature encoding", change: set(SignatureKey, "%%%")},
+		{name: "timestamp encoding", change: set(TimestampKey, "yesterday")},
+		{name: "shifted timestamp", change: func(md metadata.MD) {
+			ts, _ := strconv.ParseInt(md.Get(TimestampKey)[0], 10, 64)
+			md.Set(TimestampKey, strconv.FormatInt(ts+1, 10))
+		}},
+		{name: "missing signature", change: func(md metadata.MD) { delete(md, SignatureKey) }},
+		{name: "repeated peer id", change: func(md metadata.MD) { md.Append(PeerIDKey, peerID) }},
+		{name: "stale", now: time.Now().Add(MaxClockSkew + time.Minute)},
+		{name: "from the future", now: time.Now().Add(-MaxClockSkew - time.Minute)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md := sign()
+			if tt.change != nil {
+				tt.change(md)
+			}
+
+			m, r, now := method, req, time.Now()
+			if tt.method != "" {
+				m = tt.method
+			}
+			if tt.req != nil {
+				r = tt.req
+			}
+			if !tt.now.IsZero() {
+				now = tt.now
+			}
+
+			if _, err := Verify(md, trackers, m, r, now); err == nil {
+				t.Error("Verify accepted the request")
+			}
+		})
+	}
+}
+
+// TestVerifyOtherReceiver - запрос, подписанный для чужого трекера, тот не может
+// переслать нашему: подпись верна, но адрес получателя не наш
+func TestVerifyOtherReceiver(t *testing.T) {
+	key := newKey(t)
+	req := &api.GetPeersRequest{HashFile: "abc"}
+
+	md, err := Sign(key, "localhost:9002", "evil.example:9000", method, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Verify(md, trackers, method, req, time.Now()); err == nil {
+		t.Error("request signed for another tracker accepted")
+	}
+
+	// у самого чужого трекера запрос проходит
+	if _, err := Verify(md, []string{"evil.example:9000"}, method, req, time.Now()); err != nil {
+		t.Errorf("request rejected by its own receiver: %v", err)
+	}
+}
+
+func TestVerifyClockSkew(t *testing.T) {
+	key := newKey(t)
+	req := &api.GetPeersRequest{HashFile: "abc"}
+
+	md, err := Sign(key, "", tracker, method, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// в пределах допустимого расхождения запрос принимается в обе стороны
+	for _, shift := range []time.Duration{-MaxClockSkew + time.Second, MaxClockSkew - time.Second} {
+		if _, err := Verify(md, trackers, method, req, time.Now().Add(shift)); err != nil {
+			t.Errorf("shift %v: %v", shift, err)
+		}
+	}
+}
+
+func TestPeerID(t *testing.T) {
+	key := newKey(t).Public().(ed25519.PublicKey)
+
+	id := PeerID(key)
+	if id != PeerID(append(ed25519.PublicKey(nil), key...)) {
+		t.Error("peer id of the same key changed")
+	}
+	if id == PeerID(newKey(t).Public().(ed25519.PublicKey)) {
+		t.Error("two keys got the same peer id")
+	}
+
+	// uuid: 8-4-4-4-12 шестнадцатеричных цифр, версия 8, вариант RFC 4122
+	if !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-8[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(id) {
+		t.Errorf("peer id %q is not a uuid", id)
+	}
+}
+
+// TestVerifyForgedPeerID - подпись своим ключом под чужим peer_id не проходит,
+// даже если трекер никогда не видел владельца этого peer_id
+func TestVerifyForgedPeerID(t *testing.T) {
+	victim := PeerID(newKey(t).Public().(ed25519.PublicKey))
+	key := newKey(t)
+	req := &api.GetPeersRequest{HashFile: "abc", PeerId: victim}
+
+	md, err := Sign(key, "localhost:9002", tracker, method, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// подписываем заново уже с чужим peer_id
+	id := &Identity{
+		Target:    tracker,
+		PeerID:    victim,
+		Address:   "localhost:9002",
+		PublicKey: key.Public().(ed25519.PublicKey),
+		Nonce:     md.Get(NonceKey)[0],
+		Timestamp: time.Now(),
+	}
+	data, err := payload(method, id, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	md.Set(PeerIDKey, victim)
+	md.Set(TimestampKey, strconv.FormatInt(id.Timestamp.UnixNano(), 10))
+	md.Set(SignatureKey, base64.StdEncoding.EncodeToString(ed25519.Sign(key, data)))
+
+	if _, err := Verify(md, trackers, method, req, time.Now()); err == nil {
+		t.Error("Verify accepted a peer id of another key")
+	}
+}
+
+func TestFileSignature(t *testing.T) {
+	key := newKey(t)
+	meta := &FileMeta{
+		Name:        "some.txt",
+		Length:      24,
+		PieceLength: 16,
+		Hash:        "9702842ac5824617babda6a32791ac2f",
+		PieceHashes: [][]byte{{1, 2}, {3, 4}},
+		PieceHash:   "sha256",
+	}
+
+	signature := SignFile(key, meta)
+	if err := VerifyFile(key.Public().(ed25519.PublicKey), meta, signature); err != nil {
+		t.Fatalf("VerifyFile: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		change func(m *FileMeta)
+	}{
+		{name: "name", change: func(m *FileMeta) { m.Name = "other.txt" }},
+		{name: "length", change: func(m *FileMeta) { m.Length++ }},
+		{name: "piece length", change: func(m *FileMeta) { m.PieceLength = 12 }},
+		{name: "hash", change: func(m *FileMeta) { m.Hash = "00" }},
+		{name: "piece hash", change: func(m *FileMeta) { m.PieceHashes = [][]byte{{1, 2}, {3, 5}} }},
+		{name: "dropped piece", change: func(m *FileMeta) { m.PieceHashes = m.PieceHashes[:1] }},
+		{name: "algorithm", change: func(m *FileMeta) { m.PieceHash = "sha1" }},
+	}
+
+	for _, tt := range tests {
+		changed := *meta
+		tt.change(&changed)
+
+		if err := VerifyFile(key.Public().(ed25519.PublicKey), &changed, signature); err == nil {
+			t.Errorf("%s: changed description accepted", tt.name)
+		}
+	}
+
+	if err := VerifyFile(newKey(t).Public().(ed25519.PublicKey), meta, signature); err == nil {
+		t.Error("signature accepted with another key")
+	}
+	if err := VerifyFile([]byte("short"), meta, signature); err == nil {
+		t.Error("short publisher key accepted")
+	}
+}
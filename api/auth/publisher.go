@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"strconv"
+)
+
+// FileMeta - описание файла, которое подписывает издатель.
+// Подпись связывает хэш файла с именем, размерами и хэшами кусков,
+// так что подменить описание на трекере незаметно нельзя.
+type FileMeta struct {
+	Name        string
+	Length      uint64
+	PieceLength uint64
+	Hash        string
+	PieceHashes [][]byte
+	PieceHash   string // алгоритм хэшей кусков
+}
+
+func (m *FileMeta) payload() []byte {
+	var b bytes.Buffer
+	for _, v := range []string{
+		"file",
+		strconv.Quote(m.Name),
+		strconv.FormatUint(m.Length, 10),
+		strconv.FormatUint(m.PieceLength, 10),
+		m.Hash,
+		m.PieceHash,
+		strconv.Itoa(len(m.PieceHashes)),
+	} {
+		b.WriteString(v)
+		b.WriteByte('\n')
+	}
+
+	for _, h := range m.PieceHashes {
+		b.WriteString(hex.EncodeToString(h))
+		b.WriteByte('\n')
+	}
+
+	return b.Bytes()
+}
+
+// SignFile подписывает описание файла ключом издателя
+func SignFile(key ed25519.PrivateKey, meta *FileMeta) []byte {
+	return ed25519.Sign(key, meta.payload())
+}
+
+// VerifyFile проверяет подпись издателя над описанием файла
+func VerifyFile(publisher ed25519.PublicKey, meta *FileMeta, signature []byte) error {
+	if len(publisher) != ed25519.PublicKeySize {
+		return errors.New("invalid publisher key")
+	}
+
+	if !ed25519.Verify(publisher, meta.payload(), signature) {
+		return errors.New("invalid publisher signature")
+	}
+
+	return nil
+}
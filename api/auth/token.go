@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrMalformedToken = errors.New("malformed access token")
+
+	// токен правильного вида не сошелся с ключом: возможно, у трекера новый ключ
+	ErrTokenSignature = errors.New("access token is not signed by the tracker")
+)
+
+// AccessToken - разрешение, выданное трекером пиру, качать куски приватного файла.
+// Токен годится только для запросов, подписанных ключом PublicKey.
+type AccessToken struct {
+	Hash      string
+	PeerID    string
+	PublicKey ed25519.PublicKey
+	Expires   time.Time
+}
+
+func (t *AccessToken) payload() []byte {
+	return []byte(t.Hash + "\n" + t.PeerID + "\n" + hex.EncodeToString(t.PublicKey) + "\n" +
+		strconv.FormatInt(t.Expires.Unix(), 10))
+}
+
+// IssueToken подписывает токен ключом трекера: base64(данные).base64(подпись)
+func IssueToken(key ed25519.PrivateKey, t *AccessToken) string {
+	data := t.payload()
+
+	return base64.RawURLEncoding.EncodeToString(data) + "." +
+		base64.RawURLEncoding.EncodeToString(ed25519.Sign(key, data))
+}
+
+// VerifyToken проверяет подпись трекера, файл и срок действия токена
+func VerifyToken(key ed25519.PublicKey, token, hash string, now time.Time) (*AccessToken, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return nil, ErrMalformedToken
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || len(signature) != ed25519.SignatureSize {
+		return nil, ErrMalformedToken
+	}
+
+	fields := strings.Split(string(data), "\n")
+	if len(fields) != 4 {
+		return nil, ErrMalformedToken
+	}
+
+	publicKey, err := hex.DecodeString(fields[2])
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return nil, ErrMalformedToken
+	}
+
+	expires, err := strconv.ParseInt(fields[3], 10, 64)
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	if len(key) != ed25519.PublicKeySize || !ed25519.Verify(key, data, signature) {
+		return nil, ErrTokenSignature
+	}
+
+	t := &AccessToken{Hash: fields[0], PeerID: fields[1], PublicKey: publicKey, Expires: time.Unix(expires, 0)}
+
+	if t.Hash != hash {
+		return nil, errors.New("access token is issued for another file")
+	}
+
+	if now.After(t.Expires) {
+		return nil, errors.New("access token expired")
+	}
+
+	return t, nil
+}
+
+// IssuedTo - выдан ли токен пиру с этим peer_id и ключом
+func (t *AccessToken) IssuedTo(peerID string, key ed25519.PublicKey) bool {
+	return t.PeerID == peerID && bytes.Equal(t.PublicKey, key)
+}
@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTokenRoundTrip(t *testing.T) {
+	tracker := newKey(t)
+	peer := newKey(t)
+	expires := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	token := IssueToken(tracker, &AccessToken{
+		Hash:      "abc",
+		PeerID:    "peer",
+		PublicKey: peer.Public().(ed25519.PublicKey),
+		Expires:   expires,
+	})
+
+	got, err := VerifyToken(tracker.Public().(ed25519.PublicKey), token, "abc", time.Now())
+	if err != nil {
+		t.Fatalf("VerifyToken: %v", err)
+	}
+
+	if got.Hash != "abc" || got.PeerID != "peer" || !got.Expires.Equal(expires) {
+		t.Errorf("unexpected token %+v", got)
+	}
+
+	if !got.IssuedTo("peer", peer.Public().(ed25519.PublicKey)) {
+		t.Error("token is not issued to its peer")
+	}
+	if got.IssuedTo("other", peer.Public().(ed25519.PublicKey)) {
+		t.Error("token is issued to another peer id")
+	}
+	if got.IssuedTo("peer", newKey(t).Public().(ed25519.PublicKey)) {
+		t.Error("token is issued to another key")
+	}
+}
+
+func TestVerifyTokenRejects(t *testing.T) {
+	tracker := newKey(t)
+	peer := newKey(t).Public().(ed25519.PublicKey)
+
+	issue := func(hash string, expires time.Time) string {
+		return IssueToken(tracker, &AccessToken{Hash: hash, PeerID: "peer", PublicKey: peer, Expires: expires})
+	}
+
+	valid := issue("abc", time.Now().Add(time.Hour))
+	parts := strings.Split(valid, ".")
+	data, signature := parts[0], parts[1]
+
+	enc := base64.RawURLEncoding.EncodeToString
+	forged := enc([]byte("abc\nmallory\n" + strings.Repeat("00", ed25519.PublicKeySize) + "\n9999999999"))
+
+	tests := []struct {
+		name  string
+		token string
+		key   ed25519.PublicKey
+		want  error // nil - любая ошибка
+	}{
+		{name: "empty", token: "", want: ErrMalformedToken},
+		{name: "no signature", token: data, want: ErrMalformedToken},
+		{name: "extra part", token: valid + ".x", want: ErrMalformedToken},
+		{name: "data encoding", token: "%%%." + signature, want: ErrMalformedToken},
+		{name: "signature encoding", token: data + ".%%%", want: ErrMalformedToken},
+		{name: "short signature", token: data + "." + enc([]byte("short")), want: ErrMalformedToken},
+		{name: "missing fields", token: enc([]byte("abc\npeer")) + "." + signature, want: ErrMalformedToken},
+		{name: "bad key field", token: enc([]byte("abc\npeer\nzz\n1")) + "." + signature, want: ErrMalformedToken},
+		{name: "bad expiry", token: enc([]byte("abc\npeer\n"+strings.Repeat("00", ed25519.PublicKeySize)+"\nsoon")) + "." + signature, want: ErrMalformedToken},
+		{name: "forged data", token: forged + "." + signature, want: ErrTokenSignature},
+		{name: "other tracker", token: valid, key: newKey(t).Public().(ed25519.PublicKey), want: ErrTokenSignature},
+		{name: "no tracker key", token: valid, key: ed25519.PublicKey{}, want: ErrTokenSignature},
+		{name: "other file", token: issue("abd", time.Now().Add(time.Hour))},
+		{name: "expired", token: issue("abc", time.Now().Add(-time.Minute))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := tt.key
+			if key == nil {
+				key = tracker.Public().(ed25519.PublicKey)
+			}
+
+			_, err := VerifyToken(key, tt.token, "abc", time.Now())
+			if err == nil {
+				t.Fatal("VerifyToken accepted the token")
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+// Package bencode кодирует и разбирает bencode - формат торрент-файлов и ответов http-трекеров.
+// Значения: int64 (целые), string (байтовые строки), []interface{} (списки)
+// и map[string]interface{} (словари, ключи пишутся по порядку).
+package bencode
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// Marshal кодирует значение. Кроме основных типов принимает int, uint64, []byte,
+// []string и []map[string]interface{}.
+func Marshal(v interface{}) ([]byte, error) {
+	var b bytes.Buffer
+
+	err := encode(&b, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+func encode(b *bytes.Buffer, v interface{}) error {
+	switch v := v.(type) {
+	case int:
+		return encode(b, int64(v))
+	case int64:
+		b.WriteByte('i')
+		b.WriteString(strconv.FormatInt(v, 10))
+		b.WriteByte('e')
+	case uint64:
+		b.WriteByte('i')
+		b.WriteString(strconv.FormatUint(v, 10))
+		b.WriteByte('e')
+	case string:
+		b.WriteString(strconv.Itoa(len(v)))
+		b.WriteByte(':')
+		b.WriteString(v)
+	case []byte:
+		return encode(b, string(v))
+	case []string:
+		list := make([]interface{}, len(v))
+		for i := range v {
+			list[i] = v[i]
+		}
+		return encode(b, list)
+	case []map[string]interface{}:
+		list := make([]interface{}, len(v))
+		for i := range v {
+			list[i] = v[i]
+		}
+		return encode(b, list)
+	case []interface{}:
+		b.WriteByte('l')
+		for _, item := range v {
+			if err := encode(b, item); err != nil {
+				return err
+			}
+		}
+		b.WriteByte('e')
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		b.WriteByte('d')
+		for _, k := range keys {
+			_ = encode(b, k)
+			if err := encode(b, v[k]); err != nil {
+				return err
+			}
+		}
+		b.WriteByte('e')
+	default:
+		return fmt.Errorf("bencode: unsupported type %T", v)
+	}
+
+	return nil
+}
+
+// maxDepth ограничивает вложенность списков и словарей во входных данных
+const maxDepth = 64
+
+// Unmarshal разбирает одно значение, после которого не должно быть данных
+func Unmarshal(data []byte) (interface{}, error) {
+	v, n, err := decode(data, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if n != len(data) {
+		return nil, errors.New("bencode: trailing data")
+	}
+
+	return v, nil
+}
+
+// decode разбирает значение с позиции pos и возвращает позицию за ним
+func decode(data []byte, pos, depth int) (interface{}, int, error) {
+	if pos >= len(data) {
+		return nil, pos, errors.New("bencode: unexpected end of data")
+	}
+
+	if depth > maxDepth {
+		return nil, pos, errors.New("bencode: nesting is too deep")
+	}
+
+	switch c := data[pos]; {
+	case c == 'i':
+		end := bytes.IndexByte(data[pos:], 'e')
+		if end < 0 {
+			return nil, pos, errors.New("bencode: unterminated integer")
+		}
+
+		n, err := strconv.ParseInt(string(data[pos+1:pos+end]), 10, 64)
+		if err != nil {
+			return nil, pos, fmt.Errorf("bencode: invalid integer at %d", pos)
+		}
+
+		return n, pos + end + 1, nil
+	case c >= '0' && c <= '9':
+		colon := bytes.IndexByte(data[pos:], ':')
+		if colon < 0 {
+			return nil, pos, errors.New("bencode: unterminated string length")
+		}
+
+		length, err := strconv.Atoi(string(data[pos : pos+colon]))
+		if err != nil || length < 0 {
+			return nil, pos, fmt.Errorf("bencode: invalid string length at %d", pos)
+		}
+
+		start := pos + colon + 1
+		if length > len(data)-start {
+			return nil, pos, errors.New("bencode: string out of range")
+		}
+
+		return string(data[start : start+length]), start + length, nil
+	case c == 'l':
+		list := []interface{}{}
+		pos++
+
+		for pos < len(data) && data[pos] != 'e' {
+			item, next, err := decode(data, pos, depth+1)
+			if err != nil {
+				return nil, pos, err
+			}
+
+			list = append(list, item)
+			pos = next
+		}
+
+		if pos >= len(data) {
+			return nil, pos, errors.New("bencode: unterminated list")
+		}
+
+		return list, pos + 1, nil
+	case c == 'd':
+		dict := map[string]interface{}{}
+		pos++
+
+		for pos < len(data) && data[pos] != 'e' {
+			key, next, err := decode(data, pos, depth+1)
+			if err != nil {
+				return nil, pos, err
+			}
+
+			k, ok := key.(string)
+			if !ok {
+				return nil, pos, fmt.Errorf("bencode: dictionary key at %d is not a string", pos)
+			}
+
+			value, next, err := decode(data, next, depth+1)
+			if err != nil {
+				return nil, pos, err
+			}
+
+			dict[k] = value
+			pos = next
+		}
+
+		if pos >= len(data) {
+			return nil, pos, errors.New("bencode: unterminated dictionary")
+		}
+
+		return dict, pos + 1, nil
+	default:
+		return nil, pos, fmt.Errorf("bencode: unexpected byte %q at %d", c, pos)
+	}
+}
+
+// Raw возвращает закодированное значение ключа словаря верхнего уровня как есть,
+// например словарь info торрент-файла, от байтов которого считается infohash
+func Raw(data []byte, key string) ([]byte, error) {
+	if len(data) == 0 || data[0] != 'd' {
+		return nil, errors.New("bencode: not a dictionary")
+	}
+
+	pos := 1
+	for pos < len(data) && data[pos] != 'e' {
+		k, next, err := decode(data, pos, 1)
+		if err != nil {
+			return nil, err
+		}
+
+		_, end, err := decode(data, next, 1)
+		if err != nil {
+			return nil, err
+		}
+
+		if k == key {
+			return data[next:end], nil
+		}
+
+		pos = end
+	}
+
+	return nil, fmt.Errorf("bencode: no key %q", key)
+}
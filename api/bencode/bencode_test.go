@@ -0,0 +1,147 @@
+package bencode
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMarshal(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{name: "int", v: 42, want: "i42e"},
+		{name: "negative", v: int64(-3), want: "i-3e"},
+		{name: "uint64", v: uint64(1 << 63), want: "i9223372036854775808e"},
+		{name: "string", v: "spam", want: "4:spam"},
+		{name: "empty string", v: "", want: "0:"},
+		{name: "bytes", v: []byte{0, 'e'}, want: "2:\x00e"},
+		{name: "strings", v: []string{"a", "bc"}, want: "l1:a2:bce"},
+		{name: "dicts", v: []map[string]interface{}{{"a": 1}}, want: "ld1:ai1eee"},
+		{name: "list", v: []interface{}{1, "x", []interface{}{}}, want: "li1e1:xlee"},
+		{name: "sorted keys", v: map[string]interface{}{"b": 2, "a": 1, "ab": "x"}, want: "d1:ai1e2:ab1:x1:bi2ee"},
+	}
+
+	for _, tt := range tests {
+		got, err := Marshal(tt.v)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+
+		if string(got) != tt.want {
+			t.Errorf("%s: Marshal = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMarshalUnsupported(t *testing.T) {
+	for _, v := range []interface{}{1.5, true, nil, map[string]interface{}{"a": struct{}{}}, []interface{}{int32(1)}} {
+		if _, err := Marshal(v); err == nil {
+			t.Errorf("Marshal(%#v) accepted an unsupported type", v)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	v := map[string]interface{}{
+		"announce": "localhost:9000",
+		"info": map[string]interface{}{
+			"length": int64(24),
+			"name":   "some.txt",
+			"pieces": "\x00\x01\xff",
+		},
+		"list":  []interface{}{int64(-1), "", []interface{}{}, map[string]interface{}{}},
+		"empty": map[string]interface{}{},
+	}
+
+	data, err := Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Unmarshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, v) {
+		t.Errorf("Unmarshal(Marshal(v)) = %#v, want %#v", got, v)
+	}
+
+	again, err := Marshal(got)
+	if err != nil || !bytes.Equal(again, data) {
+		t.Errorf("second Marshal = %q, %v, want %q", again, err, data)
+	}
+}
+
+func TestUnmarshalMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "empty", data: ""},
+		{name: "unknown type", data: "x"},
+		{name: "unterminated integer", data: "i42"},
+		{name: "empty integer", data: "ie"},
+		{name: "not an integer", data: "i4x2e"},
+		{name: "integer overflow", data: "i99999999999999999999e"},
+		{name: "unterminated string length", data: "4"},
+		{name: "negative length", data: "-1:a"},
+		{name: "string out of range", data: "5:spam"},
+		{name: "huge string length", data: "99999999999999999999:a"},
+		{name: "unterminated list", data: "li1e"},
+		{name: "unterminated dictionary", data: "d1:ai1e"},
+		{name: "dictionary without value", data: "d1:ae"},
+		{name: "integer key", data: "di1ei2ee"},
+		{name: "trailing data", data: "i1ei2e"},
+		{name: "too deep", data: strings.Repeat("l", maxDepth+2) + strings.Repeat("e", maxDepth+2)},
+	}
+
+	for _, tt := range tests {
+		if v, err := Unmarshal([]byte(tt.data)); err == nil {
+			t.Errorf("%s: Unmarshal(%q) = %#v, want error", tt.name, tt.data, v)
+		}
+	}
+}
+
+func TestUnmarshalDepthLimit(t *testing.T) {
+	data := strings.Repeat("l", maxDepth+1) + strings.Repeat("e", maxDepth+1)
+	if _, err := Unmarshal([]byte(data)); err != nil {
+		t.Errorf("nesting of %d lists rejected: %v", maxDepth+1, err)
+	}
+}
+
+func TestRaw(t *testing.T) {
+	data := []byte("d8:announce3:url4:infod6:lengthi24e4:name1:ae5:owner1:xe")
+
+	got, err := Raw(data, "info")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "d6:lengthi24e4:name1:ae" {
+		t.Errorf("Raw = %q", got)
+	}
+
+	got, err = Raw(data, "owner")
+	if err != nil || string(got) != "1:x" {
+		t.Errorf("Raw(owner) = %q, %v", got, err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		data string
+	}{
+		{name: "missing key", data: "d1:ai1ee"},
+		{name: "not a dictionary", data: "li1ee"},
+		{name: "empty", data: ""},
+		{name: "broken value", data: "d1:ai1"},
+	} {
+		if _, err := Raw([]byte(tt.data), "info"); err == nil {
+			t.Errorf("%s: Raw accepted %q", tt.name, tt.data)
+		}
+	}
+}
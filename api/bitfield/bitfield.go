@@ -0,0 +1,125 @@
+// Package bitfield хранит множество кусков файла и переводит его в сжатое
+// представление api.Bitfield: длины чередующихся серий отсутствующих и имеющихся кусков.
+// У почти скачанного файла или у сида это пара чисел вместо списка всех номеров.
+package bitfield
+
+import (
+	"errors"
+	"math/bits"
+
+	"github.com/elizarpif/grpctorrent/api"
+)
+
+// Bitfield - множество номеров кусков файла из size штук
+type Bitfield struct {
+	words []uint64
+	size  uint64
+}
+
+func New(size uint64) *Bitfield {
+	return &Bitfield{words: make([]uint64, (size+63)/64), size: size}
+}
+
+// FromPieces собирает множество из номеров кусков, номера за концом файла отбрасываются
+func FromPieces(size uint64, pieces []uint64) *Bitfield {
+	b := New(size)
+	for _, piece := range pieces {
+		b.Set(piece)
+	}
+
+	return b
+}
+
+func (b *Bitfield) Size() uint64 {
+	return b.size
+}
+
+func (b *Bitfield) Set(piece uint64) {
+	if piece < b.size {
+		b.words[piece/64] |= 1 << (piece % 64)
+	}
+}
+
+func (b *Bitfield) Has(piece uint64) bool {
+	return piece < b.size && b.words[piece/64]&(1<<(piece%64)) != 0
+}
+
+// Count - сколько кусков в множестве
+func (b *Bitfield) Count() uint64 {
+	var n int
+	for _, w := range b.words {
+		n += bits.OnesCount64(w)
+	}
+
+	return uint64(n)
+}
+
+// Pieces - номера кусков по возрастанию
+func (b *Bitfield) Pieces() []uint64 {
+	res := make([]uint64, 0, b.Count())
+	for piece := uint64(0); piece < b.size; piece++ {
+		if b.Has(piece) {
+			res = append(res, piece)
+		}
+	}
+
+	return res
+}
+
+// Encode сжимает множество в серии
+func (b *Bitfield) Encode() *api.Bitfield {
+	msg := &api.Bitfield{Size: b.size}
+
+	var run uint64
+	have := false // первая серия - отсутствующие куски
+
+	for piece := uint64(0); piece < b.size; piece++ {
+		if b.Has(piece) != have {
+			msg.Runs = append(msg.Runs, run)
+			run, have = 0, !have
+		}
+		run++
+	}
+
+	if run > 0 {
+		msg.Runs = append(msg.Runs, run)
+	}
+
+	return msg
+}
+
+// Decode разворачивает серии. size - сколько кусков в файле на самом деле:
+// поле с другим размером не принимается, так что чужое сообщение
+// не заставит выделить лишнюю память.
+func Decode(msg *api.Bitfield, size uint64) (*Bitfield, error) {
+	if msg == nil {
+		return nil, errors.New("empty bitfield")
+	}
+
+	if msg.Size != size {
+		return nil, errors.New("bitfield size doesnt match file")
+	}
+
+	b := New(size)
+
+	var pos uint64
+	for i, run := range msg.Runs {
+		if run > size-pos {
+			return nil, errors.New("bitfield runs overflow its size")
+		}
+
+		if i%2 == 1 {
+			for piece := pos; piece < pos+run; piece++ {
+				b.Set(piece)
+			}
+		}
+
+		pos += run
+	}
+
+	if pos != size {
+		return nil, errors.New("bitfield runs dont cover its size")
+	}
+
+	return b, nil
+}
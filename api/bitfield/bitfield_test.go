@@ -0,0 +1,107 @@
+package bitfield
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/elizarpif/grpctorrent/api"
+)
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name   string
+		size   uint64
+		pieces []uint64
+		runs   []uint64
+	}{
+		{name: "empty file", size: 0},
+		{name: "nothing", size: 5, runs: []uint64{5}},
+		{name: "seed", size: 5, pieces: []uint64{0, 1, 2, 3, 4}, runs: []uint64{0, 5}},
+		{name: "tail", size: 5, pieces: []uint64{3, 4}, runs: []uint64{3, 2}},
+		{name: "head", size: 5, pieces: []uint64{0, 1}, runs: []uint64{0, 2, 3}},
+		{name: "alternating", size: 4, pieces: []uint64{1, 3}, runs: []uint64{1, 1, 1, 1}},
+		{name: "word boundary", size: 130, pieces: []uint64{63, 64, 129}, runs: []uint64{63, 2, 64, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := FromPieces(tt.size, tt.pieces)
+
+			msg := b.Encode()
+			if msg.Size != tt.size || !reflect.DeepEqual(msg.Runs, tt.runs) {
+				t.Fatalf("Encode = %d %v, want %d %v", msg.Size, msg.Runs, tt.size, tt.runs)
+			}
+
+			got, err := Decode(msg, tt.size)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := tt.pieces
+			if want == nil {
+				want = []uint64{}
+			}
+			if !reflect.DeepEqual(got.Pieces(), want) {
+				t.Errorf("Decode(Encode) pieces = %v, want %v", got.Pieces(), want)
+			}
+			if got.Count() != uint64(len(tt.pieces)) {
+				t.Errorf("Count = %d, want %d", got.Count(), len(tt.pieces))
+			}
+		})
+	}
+}
+
+func TestSetHas(t *testing.T) {
+	b := FromPieces(10, []uint64{0, 9, 10, 1000}) // 10 и 1000 за концом файла
+
+	if b.Size() != 10 || b.Count() != 2 {
+		t.Errorf("size %d, count %d, want 10 and 2", b.Size(), b.Count())
+	}
+
+	for piece, want := range map[uint64]bool{0: true, 1: false, 9: true, 10: false, math.MaxUint64: false} {
+		if b.Has(piece) != want {
+			t.Errorf("Has(%d) = %v, want %v", piece, !want, want)
+		}
+	}
+
+	b.Set(1)
+	b.Set(1)
+	if b.Count() != 3 {
+		t.Errorf("count after Set = %d, want 3", b.Count())
+	}
+}
+
+func TestDecodeMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  *api.Bitfield
+		size uint64
+	}{
+		{name: "nil", msg: nil, size: 5},
+		{name: "other size", msg: &api.Bitfield{Size: 6, Runs: []uint64{6}}, size: 5},
+		{name: "huge size", msg: &api.Bitfield{Size: math.MaxUint64, Runs: []uint64{0, math.MaxUint64}}, size: 5},
+		{name: "runs too short", msg: &api.Bitfield{Size: 5, Runs: []uint64{2, 2}}, size: 5},
+		{name: "runs too long", msg: &api.Bitfield{Size: 5, Runs: []uint64{2, 4}}, size: 5},
+		{name: "run overflow", msg: &api.Bitfield{Size: 5, Runs: []uint64{3, math.MaxUint64 - 1}}, size: 5},
+		{name: "no runs", msg: &api.Bitfield{Size: 5}, size: 5},
+	}
+
+	for _, tt := range tests {
+		if b, err := Decode(tt.msg, tt.size); err == nil {
+			t.Errorf("%s: Decode = %v, want error", tt.name, b.Pieces())
+		}
+	}
+}
+
+func TestDecodeEmptyRuns(t *testing.T) {
+	// пустые серии в середине не мешают, лишь бы серии покрывали весь файл
+	b, err := Decode(&api.Bitfield{Size: 4, Runs: []uint64{1, 0, 0, 2, 1}}, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(b.Pieces(), []uint64{1, 2}) {
+		t.Errorf("pieces = %v, want [1 2]", b.Pieces())
+	}
+}
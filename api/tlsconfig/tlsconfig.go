@@ -0,0 +1,147 @@
+// Package tlsconfig собирает настройки TLS и взаимного TLS
+// для grpc-серверов, клиентов и http-шлюзов трекера и пиров.
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"io/ioutil"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// Config - пути к сертификатам и режим проверки клиентов
+type Config struct {
+	CertFile string // сертификат узла, им же узел представляется как клиент
+	KeyFile  string
+	CAFile   string // удостоверяющий центр для проверки собеседников
+	Mutual   bool   // сервер требует сертификат клиента, подписанный CAFile
+}
+
+// RegisterFlags добавляет флаги -tls-cert, -tls-key, -tls-ca и -mtls
+func (c *Config) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.CertFile, "tls-cert", "", "PEM certificate, enables TLS for grpc and http servers")
+	fs.StringVar(&c.KeyFile, "tls-key", "", "PEM private key for -tls-cert")
+	fs.StringVar(&c.CAFile, "tls-ca", "", "PEM CA bundle used to verify servers and, with -mtls, clients")
+	fs.BoolVar(&c.Mutual, "mtls", false, "require client certificates signed by -tls-ca")
+}
+
+// Enabled - серверы работают по TLS
+func (c *Config) Enabled() bool {
+	return c.CertFile != ""
+}
+
+func (c *Config) validate() error {
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return errors.New("tls-cert and tls-key must be set together")
+	}
+
+	if c.Mutual && (c.CAFile == "" || !c.Enabled()) {
+		return errors.New("mtls needs tls-cert, tls-key and tls-ca")
+	}
+
+	return nil
+}
+
+func (c *Config) caPool() (*x509.CertPool, error) {
+	if c.CAFile == "" {
+		return nil, nil
+	}
+
+	pem, err := ioutil.ReadFile(c.CAFile)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.New("no certificates in " + c.CAFile)
+	}
+
+	return pool, nil
+}
+
+// Server - настройки TLS для grpc и http серверов
+func (c *Config) Server() (*tls.Config, error) {
+	err := c.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if c.Mutual {
+		cfg.ClientCAs, err = c.caPool()
+		if err != nil {
+			return nil, err
+		}
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return cfg, nil
+}
+
+// Client - настройки TLS для исходящих соединений. Без -tls-ca
+// серверы проверяются по системным корневым сертификатам.
+func (c *Config) Client() (*tls.Config, error) {
+	err := c.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	cfg.RootCAs, err = c.caPool()
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Enabled() {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+// ServerOption - опция grpc-сервера, без TLS возвращает nil
+func (c *Config) ServerOption() (grpc.ServerOption, error) {
+	if !c.Enabled() {
+		return nil, c.validate()
+	}
+
+	cfg, err := c.Server()
+	if err != nil {
+		return nil, err
+	}
+
+	return grpc.Creds(credentials.NewTLS(cfg)), nil
+}
+
+// DialOption - опция соединения с grpc-сервером: TLS, если задан сертификат или CA, иначе без шифрования
+func (c *Config) DialOption() (grpc.DialOption, error) {
+	if !c.Enabled() && c.CAFile == "" {
+		return grpc.WithInsecure(), c.validate()
+	}
+
+	cfg, err := c.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	return grpc.WithTransportCredentials(credentials.NewTLS(cfg)), nil
+}
@@ -31,17 +31,122 @@ const (
 // of the legacy proto package is being used.
 const _ = proto.ProtoPackageIsVersion4
 
+// видимость файла в каталоге трекера
+type Visibility int32
+
+const (
+	Visibility_PUBLIC   Visibility = 0 // виден всем в каталоге
+	Visibility_UNLISTED Visibility = 1 // не показывается в каталоге, но доступен по хэшу
+	Visibility_PRIVATE  Visibility = 2 // виден и доступен только владельцу и допущенным пирам и группам
+)
+
+// Enum value maps for Visibility.
+var (
+	Visibility_name = map[int32]string{
+		0: "PUBLIC",
+		1: "UNLISTED",
+		2: "PRIVATE",
+	}
+	Visibility_value = map[string]int32{
+		"PUBLIC":   0,
+		"UNLISTED": 1,
+		"PRIVATE":  2,
+	}
+)
+
+func (x Visibility) Enum() *Visibility {
+	p := new(Visibility)
+	*p = x
+	return p
+}
+
+func (x Visibility) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Visibility) Descriptor() protoreflect.EnumDescriptor {
+	return file_torrent_proto_enumTypes[0].Descriptor()
+}
+
+func (Visibility) Type() protoreflect.EnumType {
+	return &file_torrent_proto_enumTypes[0]
+}
+
+func (x Visibility) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Visibility.Descriptor instead.
+func (Visibility) EnumDescriptor() ([]byte, []int) {
+	return file_torrent_proto_rawDescGZIP(), []int{0}
+}
+
+// чем хэшируются куски файла
+type PieceHash int32
+
+const (
+	PieceHash_SHA256 PieceHash = 0
+	PieceHash_SHA1   PieceHash = 1 // файлы из торрент-файлов BitTorrent v1
+)
+
+// Enum value maps for PieceHash.
+var (
+	PieceHash_name = map[int32]string{
+		0: "SHA256",
+		1: "SHA1",
+	}
+	PieceHash_value = map[string]int32{
+		"SHA256": 0,
+		"SHA1":   1,
+	}
+)
+
+func (x PieceHash) Enum() *PieceHash {
+	p := new(PieceHash)
+	*p = x
+	return p
+}
+
+func (x PieceHash) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PieceHash) Descriptor() protoreflect.EnumDescriptor {
+	return file_torrent_proto_enumTypes[1].Descriptor()
+}
+
+func (PieceHash) Type() protoreflect.EnumType {
+	return &file_torrent_proto_enumTypes[1]
+}
+
+func (x PieceHash) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PieceHash.Descriptor instead.
+func (PieceHash) EnumDescriptor() ([]byte, []int) {
+	return file_torrent_proto_rawDescGZIP(), []int{1}
+}
+
 type UploadFileRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	ClientId    string `protobuf:"bytes,1,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
-	Name        string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`                                   // имя файла
-	PieceLength uint64 `protobuf:"varint,3,opt,name=piece_length,json=pieceLength,proto3" json:"piece_length,omitempty"` // длина кусочка
-	Pieces      uint64 `protobuf:"varint,4,opt,name=pieces,proto3" json:"pieces,omitempty"`                              // всего кусочков
-	Length      uint64 `protobuf:"varint,5,opt,name=length,proto3" json:"length,omitempty"`                              // длина файла
-	Hash        string `protobuf:"bytes,6,opt,name=hash,proto3" json:"hash,omitempty"`                                   // хэш файла
+	ClientId      string     `protobuf:"bytes,1,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	Name          string     `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`                                         // имя файла
+	PieceLength   uint64     `protobuf:"varint,3,opt,name=piece_length,json=pieceLength,proto3" json:"piece_length,omitempty"`       // длина кусочка
+	Pieces        uint64     `protobuf:"varint,4,opt,name=pieces,proto3" json:"pieces,omitempty"`                                    // всего кусочков
+	Length        uint64     `protobuf:"varint,5,opt,name=length,proto3" json:"length,omitempty"`                                    // длина файла
+	Hash          string     `protobuf:"bytes,6,opt,name=hash,proto3" json:"hash,omitempty"`                                         // хэш файла
+	Private       bool       `protobuf:"varint,7,opt,name=private,proto3" json:"private,omitempty"`                                  // приватный рой: качать можно только с токеном трекера
+	AllowedPeers  []string   `protobuf:"bytes,8,rep,name=allowed_peers,json=allowedPeers,proto3" json:"allowed_peers,omitempty"`     // peer_id, которым кроме загрузившего разрешено качать приватный файл
+	Visibility    Visibility `protobuf:"varint,9,opt,name=visibility,proto3,enum=api.Visibility" json:"visibility,omitempty"`        // private = true равносилен PRIVATE
+	AllowedGroups []string   `protobuf:"bytes,10,rep,name=allowed_groups,json=allowedGroups,proto3" json:"allowed_groups,omitempty"` // группы пиров, которым разрешено качать приватный файл
+	PieceHashes   [][]byte   `protobuf:"bytes,11,rep,name=piece_hashes,json=pieceHashes,proto3" json:"piece_hashes,omitempty"`       // хэш каждого кусочка по алгоритму piece_hash
+	PublisherKey  []byte     `protobuf:"bytes,12,opt,name=publisher_key,json=publisherKey,proto3" json:"publisher_key,omitempty"`    // ed25519 ключ издателя
+	Signature     []byte     `protobuf:"bytes,13,opt,name=signature,proto3" json:"signature,omitempty"`                              // подпись издателя над описанием файла
+	PieceHash     PieceHash  `protobuf:"varint,14,opt,name=piece_hash,json=pieceHash,proto3,enum=api.PieceHash" json:"piece_hash,omitempty"`
 }
 
 func (x *UploadFileRequest) Reset() {
@@ -118,13 +223,72 @@ func (x *UploadFileRequest) GetHash() string {
 	return ""
 }
 
+func (x *UploadFileRequest) GetPrivate() bool {
+	if x != nil {
+		return x.Private
+	}
+	return false
+}
+
+func (x *UploadFileRequest) GetAllowedPeers() []string {
+	if x != nil {
+		return x.AllowedPeers
+	}
+	return nil
+}
+
+func (x *UploadFileRequest) GetVisibility() Visibility {
+	if x != nil {
+		return x.Visibility
+	}
+	return Visibility_PUBLIC
+}
+
+func (x *UploadFileRequest) GetAllowedGroups() []string {
+	if x != nil {
+		return x.AllowedGroups
+	}
+	return nil
+}
+
+func (x *UploadFileRequest) GetPieceHashes() [][]byte {
+	if x != nil {
+		return x.PieceHashes
+	}
+	return nil
+}
+
+func (x *UploadFileRequest) GetPublisherKey() []byte {
+	if x != nil {
+		return x.PublisherKey
+	}
+	return nil
+}
+
+func (x *UploadFileRequest) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+func (x *UploadFileRequest) GetPieceHash() PieceHash {
+	if x != nil {
+		return x.PieceHash
+	}
+	return PieceHash_SHA256
+}
+
 type GetPeersRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	HashFile string `protobuf:"bytes,1,opt,name=hash_file,json=hashFile,proto3" json:"hash_file,omitempty"` // хэш файла - файл который надо скачать
-	PeerId   string `protobuf:"bytes,2,opt,name=peer_id,json=peerId,proto3" json:"peer_id,omitempty"`       // сгенерированный uuid клиента - его пир
+	HashFile     string   `protobuf:"bytes,1,opt,name=hash_file,json=hashFile,proto3" json:"hash_file,omitempty"`                     // хэш файла - файл который надо скачать
+	PeerId       string   `protobuf:"bytes,2,opt,name=peer_id,json=peerId,proto3" json:"peer_id,omitempty"`                           // сгенерированный uuid клиента - его пир
+	MaxPeers     uint32   `protobuf:"varint,3,opt,name=max_peers,json=maxPeers,proto3" json:"max_peers,omitempty"`                    // сколько пиров вернуть, 0 - сколько решит трекер
+	WantedPieces []uint64 `protobuf:"varint,4,rep,packed,name=wanted_pieces,json=wantedPieces,proto3" json:"wanted_pieces,omitempty"` // нужные куски, пусто - любые
+	Bitfield     bool     `protobuf:"varint,5,opt,name=bitfield,proto3" json:"bitfield,omitempty"`                                    // куски пиров вернуть в pieces, а не в serial_pieces
 }
 
 func (x *GetPeersRequest) Reset() {
@@ -173,17 +337,41 @@ func (x *GetPeersRequest) GetPeerId() string {
 	return ""
 }
 
-type ListPeers struct {
+func (x *GetPeersRequest) GetMaxPeers() uint32 {
+	if x != nil {
+		return x.MaxPeers
+	}
+	return 0
+}
+
+func (x *GetPeersRequest) GetWantedPieces() []uint64 {
+	if x != nil {
+		return x.WantedPieces
+	}
+	return nil
+}
+
+func (x *GetPeersRequest) GetBitfield() bool {
+	if x != nil {
+		return x.Bitfield
+	}
+	return false
+}
+
+// Bitfield - множество кусков файла в виде длин чередующихся серий:
+// сначала отсутствующих кусков (может быть 0), потом имеющихся и так далее.
+// Сумма длин равна size.
+type Bitfield struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Count uint64            `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"`
-	Peers []*ListPeers_Peer `protobuf:"bytes,2,rep,name=peers,proto3" json:"peers,omitempty"`
+	Size uint64   `protobuf:"varint,1,opt,name=size,proto3" json:"size,omitempty"` // число кусков в файле
+	Runs []uint64 `protobuf:"varint,2,rep,packed,name=runs,proto3" json:"runs,omitempty"`
 }
 
-func (x *ListPeers) Reset() {
-	*x = ListPeers{}
+func (x *Bitfield) Reset() {
+	*x = Bitfield{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_torrent_proto_msgTypes[2]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -191,13 +379,13 @@ func (x *ListPeers) Reset() {
 	}
 }
 
-func (x *ListPeers) String() string {
+func (x *Bitfield) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListPeers) ProtoMessage() {}
+func (*Bitfield) ProtoMessage() {}
 
-func (x *ListPeers) ProtoReflect() protoreflect.Message {
+func (x *Bitfield) ProtoReflect() protoreflect.Message {
 	mi := &file_torrent_proto_msgTypes[2]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -209,36 +397,38 @@ func (x *ListPeers) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListPeers.ProtoReflect.Descriptor instead.
-func (*ListPeers) Descriptor() ([]byte, []int) {
+// Deprecated: Use Bitfield.ProtoReflect.Descriptor instead.
+func (*Bitfield) Descriptor() ([]byte, []int) {
 	return file_torrent_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *ListPeers) GetCount() uint64 {
+func (x *Bitfield) GetSize() uint64 {
 	if x != nil {
-		return x.Count
+		return x.Size
 	}
 	return 0
 }
 
-func (x *ListPeers) GetPeers() []*ListPeers_Peer {
+func (x *Bitfield) GetRuns() []uint64 {
 	if x != nil {
-		return x.Peers
+		return x.Runs
 	}
 	return nil
 }
 
-type PieceInfo struct {
+type ListPeers struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	HashFile string `protobuf:"bytes,1,opt,name=hash_file,json=hashFile,proto3" json:"hash_file,omitempty"`
-	Serial   uint64 `protobuf:"varint,2,opt,name=serial,proto3" json:"serial,omitempty"` // кусочек который скачан и раздается
+	Count        uint64            `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"`
+	Peers        []*ListPeers_Peer `protobuf:"bytes,2,rep,name=peers,proto3" json:"peers,omitempty"`
+	AccessToken  string            `protobuf:"bytes,3,opt,name=access_token,json=accessToken,proto3" json:"access_token,omitempty"`     // токен для GetPiece, выдается только для приватных файлов
+	TokenExpires int64             `protobuf:"varint,4,opt,name=token_expires,json=tokenExpires,proto3" json:"token_expires,omitempty"` // unix-время окончания действия токена
 }
 
-func (x *PieceInfo) Reset() {
-	*x = PieceInfo{}
+func (x *ListPeers) Reset() {
+	*x = ListPeers{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_torrent_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -246,13 +436,13 @@ func (x *PieceInfo) Reset() {
 	}
 }
 
-func (x *PieceInfo) String() string {
+func (x *ListPeers) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PieceInfo) ProtoMessage() {}
+func (*ListPeers) ProtoMessage() {}
 
-func (x *PieceInfo) ProtoReflect() protoreflect.Message {
+func (x *ListPeers) ProtoReflect() protoreflect.Message {
 	mi := &file_torrent_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -264,35 +454,51 @@ func (x *PieceInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PieceInfo.ProtoReflect.Descriptor instead.
-func (*PieceInfo) Descriptor() ([]byte, []int) {
+// Deprecated: Use ListPeers.ProtoReflect.Descriptor instead.
+func (*ListPeers) Descriptor() ([]byte, []int) {
 	return file_torrent_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *PieceInfo) GetHashFile() string {
+func (x *ListPeers) GetCount() uint64 {
 	if x != nil {
-		return x.HashFile
+		return x.Count
+	}
+	return 0
+}
+
+func (x *ListPeers) GetPeers() []*ListPeers_Peer {
+	if x != nil {
+		return x.Peers
+	}
+	return nil
+}
+
+func (x *ListPeers) GetAccessToken() string {
+	if x != nil {
+		return x.AccessToken
 	}
 	return ""
 }
 
-func (x *PieceInfo) GetSerial() uint64 {
+func (x *ListPeers) GetTokenExpires() int64 {
 	if x != nil {
-		return x.Serial
+		return x.TokenExpires
 	}
 	return 0
 }
 
-type DownloadFileRequest struct {
+type PieceInfo struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Hash string `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	HashFile string    `protobuf:"bytes,1,opt,name=hash_file,json=hashFile,proto3" json:"hash_file,omitempty"`
+	Serial   uint64    `protobuf:"varint,2,opt,name=serial,proto3" json:"serial,omitempty"` // кусочек который скачан и раздается
+	Pieces   *Bitfield `protobuf:"bytes,3,opt,name=pieces,proto3" json:"pieces,omitempty"`  // сразу несколько кусочков, тогда serial не смотрится
 }
 
-func (x *DownloadFileRequest) Reset() {
-	*x = DownloadFileRequest{}
+func (x *PieceInfo) Reset() {
+	*x = PieceInfo{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_torrent_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -300,13 +506,13 @@ func (x *DownloadFileRequest) Reset() {
 	}
 }
 
-func (x *DownloadFileRequest) String() string {
+func (x *PieceInfo) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DownloadFileRequest) ProtoMessage() {}
+func (*PieceInfo) ProtoMessage() {}
 
-func (x *DownloadFileRequest) ProtoReflect() protoreflect.Message {
+func (x *PieceInfo) ProtoReflect() protoreflect.Message {
 	mi := &file_torrent_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -318,32 +524,43 @@ func (x *DownloadFileRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DownloadFileRequest.ProtoReflect.Descriptor instead.
-func (*DownloadFileRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use PieceInfo.ProtoReflect.Descriptor instead.
+func (*PieceInfo) Descriptor() ([]byte, []int) {
 	return file_torrent_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *DownloadFileRequest) GetHash() string {
+func (x *PieceInfo) GetHashFile() string {
 	if x != nil {
-		return x.Hash
+		return x.HashFile
 	}
 	return ""
 }
 
-type FileInfo struct {
+func (x *PieceInfo) GetSerial() uint64 {
+	if x != nil {
+		return x.Serial
+	}
+	return 0
+}
+
+func (x *PieceInfo) GetPieces() *Bitfield {
+	if x != nil {
+		return x.Pieces
+	}
+	return nil
+}
+
+// PieceReport - накопленные пиром куски по нескольким файлам
+type PieceReport struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Name        string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`                                   // имя файла
-	PieceLength uint64 `protobuf:"varint,2,opt,name=piece_length,json=pieceLength,proto3" json:"piece_length,omitempty"` // длина кусочка
-	Pieces      uint64 `protobuf:"varint,3,opt,name=pieces,proto3" json:"pieces,omitempty"`                              // всего кусочков
-	Length      uint64 `protobuf:"varint,4,opt,name=length,proto3" json:"length,omitempty"`                              // длина файла
-	Hash        string `protobuf:"bytes,5,opt,name=hash,proto3" json:"hash,omitempty"`                                   // хэш файла
+	Files []*PieceInfo `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty"`
 }
 
-func (x *FileInfo) Reset() {
-	*x = FileInfo{}
+func (x *PieceReport) Reset() {
+	*x = PieceReport{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_torrent_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -351,13 +568,13 @@ func (x *FileInfo) Reset() {
 	}
 }
 
-func (x *FileInfo) String() string {
+func (x *PieceReport) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*FileInfo) ProtoMessage() {}
+func (*PieceReport) ProtoMessage() {}
 
-func (x *FileInfo) ProtoReflect() protoreflect.Message {
+func (x *PieceReport) ProtoReflect() protoreflect.Message {
 	mi := &file_torrent_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -369,57 +586,28 @@ func (x *FileInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use FileInfo.ProtoReflect.Descriptor instead.
-func (*FileInfo) Descriptor() ([]byte, []int) {
+// Deprecated: Use PieceReport.ProtoReflect.Descriptor instead.
+func (*PieceReport) Descriptor() ([]byte, []int) {
 	return file_torrent_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *FileInfo) GetName() string {
-	if x != nil {
-		return x.Name
-	}
-	return ""
-}
-
-func (x *FileInfo) GetPieceLength() uint64 {
-	if x != nil {
-		return x.PieceLength
-	}
-	return 0
-}
-
-func (x *FileInfo) GetPieces() uint64 {
-	if x != nil {
-		return x.Pieces
-	}
-	return 0
-}
-
-func (x *FileInfo) GetLength() uint64 {
-	if x != nil {
-		return x.Length
-	}
-	return 0
-}
-
-func (x *FileInfo) GetHash() string {
+func (x *PieceReport) GetFiles() []*PieceInfo {
 	if x != nil {
-		return x.Hash
+		return x.Files
 	}
-	return ""
+	return nil
 }
 
-type ListFiles struct {
+type PieceReportResult struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Count uint64      `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"`
-	Files []*FileInfo `protobuf:"bytes,2,rep,name=files,proto3" json:"files,omitempty"`
+	Rejected []string `protobuf:"bytes,1,rep,name=rejected,proto3" json:"rejected,omitempty"` // хэши файлов, куски которых трекер не принял
 }
 
-func (x *ListFiles) Reset() {
-	*x = ListFiles{}
+func (x *PieceReportResult) Reset() {
+	*x = PieceReportResult{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_torrent_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -427,13 +615,13 @@ func (x *ListFiles) Reset() {
 	}
 }
 
-func (x *ListFiles) String() string {
+func (x *PieceReportResult) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListFiles) ProtoMessage() {}
+func (*PieceReportResult) ProtoMessage() {}
 
-func (x *ListFiles) ProtoReflect() protoreflect.Message {
+func (x *PieceReportResult) ProtoReflect() protoreflect.Message {
 	mi := &file_torrent_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -445,36 +633,32 @@ func (x *ListFiles) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListFiles.ProtoReflect.Descriptor instead.
-func (*ListFiles) Descriptor() ([]byte, []int) {
+// Deprecated: Use PieceReportResult.ProtoReflect.Descriptor instead.
+func (*PieceReportResult) Descriptor() ([]byte, []int) {
 	return file_torrent_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *ListFiles) GetCount() uint64 {
-	if x != nil {
-		return x.Count
-	}
-	return 0
-}
-
-func (x *ListFiles) GetFiles() []*FileInfo {
+func (x *PieceReportResult) GetRejected() []string {
 	if x != nil {
-		return x.Files
+		return x.Rejected
 	}
 	return nil
 }
 
-type Piece struct {
+type DownloadFileRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Payload      []byte `protobuf:"bytes,1,opt,name=payload,proto3" json:"payload,omitempty"`                                // кусочек
-	SerialNumber uint64 `protobuf:"varint,2,opt,name=serial_number,json=serialNumber,proto3" json:"serial_number,omitempty"` // номер кусочка
+	Hash     string   `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	Metainfo []byte   `protobuf:"bytes,2,opt,name=metainfo,proto3" json:"metainfo,omitempty"` // bencoded описание файла, с ним каталог трекера не нужен
+	Uri      string   `protobuf:"bytes,3,opt,name=uri,proto3" json:"uri,omitempty"`           // magnet-ссылка вместо хэша
+	Torrent  []byte   `protobuf:"bytes,4,opt,name=torrent,proto3" json:"torrent,omitempty"`   // торрент-файл BitTorrent v1 с одним файлом
+	Peers    []string `protobuf:"bytes,5,rep,name=peers,proto3" json:"peers,omitempty"`       // grpc-адреса пиров: описание и куски берутся у них, трекер не нужен
 }
 
-func (x *Piece) Reset() {
-	*x = Piece{}
+func (x *DownloadFileRequest) Reset() {
+	*x = DownloadFileRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_torrent_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -482,13 +666,13 @@ func (x *Piece) Reset() {
 	}
 }
 
-func (x *Piece) String() string {
+func (x *DownloadFileRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Piece) ProtoMessage() {}
+func (*DownloadFileRequest) ProtoMessage() {}
 
-func (x *Piece) ProtoReflect() protoreflect.Message {
+func (x *DownloadFileRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_torrent_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -500,36 +684,68 @@ func (x *Piece) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Piece.ProtoReflect.Descriptor instead.
-func (*Piece) Descriptor() ([]byte, []int) {
+// Deprecated: Use DownloadFileRequest.ProtoReflect.Descriptor instead.
+func (*DownloadFileRequest) Descriptor() ([]byte, []int) {
 	return file_torrent_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *Piece) GetPayload() []byte {
+func (x *DownloadFileRequest) GetHash() string {
 	if x != nil {
-		return x.Payload
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *DownloadFileRequest) GetMetainfo() []byte {
+	if x != nil {
+		return x.Metainfo
 	}
 	return nil
 }
 
-func (x *Piece) GetSerialNumber() uint64 {
+func (x *DownloadFileRequest) GetUri() string {
 	if x != nil {
-		return x.SerialNumber
+		return x.Uri
 	}
-	return 0
+	return ""
 }
 
-type GetPieceRequest struct {
+func (x *DownloadFileRequest) GetTorrent() []byte {
+	if x != nil {
+		return x.Torrent
+	}
+	return nil
+}
+
+func (x *DownloadFileRequest) GetPeers() []string {
+	if x != nil {
+		return x.Peers
+	}
+	return nil
+}
+
+type FileInfo struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	SerialNumber uint64 `protobuf:"varint,1,opt,name=serial_number,json=serialNumber,proto3" json:"serial_number,omitempty"`
-	Hash         string `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
+	Name         string     `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`                                   // имя файла
+	PieceLength  uint64     `protobuf:"varint,2,opt,name=piece_length,json=pieceLength,proto3" json:"piece_length,omitempty"` // длина кусочка
+	Pieces       uint64     `protobuf:"varint,3,opt,name=pieces,proto3" json:"pieces,omitempty"`                              // всего кусочков
+	Length       uint64     `protobuf:"varint,4,opt,name=length,proto3" json:"length,omitempty"`                              // длина файла
+	Hash         string     `protobuf:"bytes,5,opt,name=hash,proto3" json:"hash,omitempty"`                                   // хэш файла
+	Private      bool       `protobuf:"varint,6,opt,name=private,proto3" json:"private,omitempty"`                            // приватный рой
+	Visibility   Visibility `protobuf:"varint,7,opt,name=visibility,proto3,enum=api.Visibility" json:"visibility,omitempty"`
+	PieceHashes  [][]byte   `protobuf:"bytes,8,rep,name=piece_hashes,json=pieceHashes,proto3" json:"piece_hashes,omitempty"`    // хэш каждого кусочка по алгоритму piece_hash
+	PublisherKey []byte     `protobuf:"bytes,9,opt,name=publisher_key,json=publisherKey,proto3" json:"publisher_key,omitempty"` // ed25519 ключ издателя
+	Signature    []byte     `protobuf:"bytes,10,opt,name=signature,proto3" json:"signature,omitempty"`                          // подпись издателя над описанием файла
+	Metainfo     []byte     `protobuf:"bytes,11,opt,name=metainfo,proto3" json:"metainfo,omitempty"`                            // bencoded описание файла для скачивания без каталога трекера, отдает только пир
+	Uri          string     `protobuf:"bytes,12,opt,name=uri,proto3" json:"uri,omitempty"`                                      // magnet-ссылка на файл, отдает только пир
+	PieceHash    PieceHash  `protobuf:"varint,13,opt,name=piece_hash,json=pieceHash,proto3,enum=api.PieceHash" json:"piece_hash,omitempty"`
 }
 
-func (x *GetPieceRequest) Reset() {
-	*x = GetPieceRequest{}
+func (x *FileInfo) Reset() {
+	*x = FileInfo{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_torrent_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -537,13 +753,13 @@ func (x *GetPieceRequest) Reset() {
 	}
 }
 
-func (x *GetPieceRequest) String() string {
+func (x *FileInfo) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetPieceRequest) ProtoMessage() {}
+func (*FileInfo) ProtoMessage() {}
 
-func (x *GetPieceRequest) ProtoReflect() protoreflect.Message {
+func (x *FileInfo) ProtoReflect() protoreflect.Message {
 	mi := &file_torrent_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -555,35 +771,113 @@ func (x *GetPieceRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetPieceRequest.ProtoReflect.Descriptor instead.
-func (*GetPieceRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use FileInfo.ProtoReflect.Descriptor instead.
+func (*FileInfo) Descriptor() ([]byte, []int) {
 	return file_torrent_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *GetPieceRequest) GetSerialNumber() uint64 {
+func (x *FileInfo) GetName() string {
 	if x != nil {
-		return x.SerialNumber
+		return x.Name
+	}
+	return ""
+}
+
+func (x *FileInfo) GetPieceLength() uint64 {
+	if x != nil {
+		return x.PieceLength
 	}
 	return 0
 }
 
-func (x *GetPieceRequest) GetHash() string {
+func (x *FileInfo) GetPieces() uint64 {
+	if x != nil {
+		return x.Pieces
+	}
+	return 0
+}
+
+func (x *FileInfo) GetLength() uint64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+func (x *FileInfo) GetHash() string {
 	if x != nil {
 		return x.Hash
 	}
 	return ""
 }
 
-type File struct {
+func (x *FileInfo) GetPrivate() bool {
+	if x != nil {
+		return x.Private
+	}
+	return false
+}
+
+func (x *FileInfo) GetVisibility() Visibility {
+	if x != nil {
+		return x.Visibility
+	}
+	return Visibility_PUBLIC
+}
+
+func (x *FileInfo) GetPieceHashes() [][]byte {
+	if x != nil {
+		return x.PieceHashes
+	}
+	return nil
+}
+
+func (x *FileInfo) GetPublisherKey() []byte {
+	if x != nil {
+		return x.PublisherKey
+	}
+	return nil
+}
+
+func (x *FileInfo) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+func (x *FileInfo) GetMetainfo() []byte {
+	if x != nil {
+		return x.Metainfo
+	}
+	return nil
+}
+
+func (x *FileInfo) GetUri() string {
+	if x != nil {
+		return x.Uri
+	}
+	return ""
+}
+
+func (x *FileInfo) GetPieceHash() PieceHash {
+	if x != nil {
+		return x.PieceHash
+	}
+	return PieceHash_SHA256
+}
+
+type ListFiles struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Count uint64      `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"`
+	Files []*FileInfo `protobuf:"bytes,2,rep,name=files,proto3" json:"files,omitempty"`
 }
 
-func (x *File) Reset() {
-	*x = File{}
+func (x *ListFiles) Reset() {
+	*x = ListFiles{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_torrent_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -591,13 +885,13 @@ func (x *File) Reset() {
 	}
 }
 
-func (x *File) String() string {
+func (x *ListFiles) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*File) ProtoMessage() {}
+func (*ListFiles) ProtoMessage() {}
 
-func (x *File) ProtoReflect() protoreflect.Message {
+func (x *ListFiles) ProtoReflect() protoreflect.Message {
 	mi := &file_torrent_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -609,28 +903,40 @@ func (x *File) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use File.ProtoReflect.Descriptor instead.
-func (*File) Descriptor() ([]byte, []int) {
+// Deprecated: Use ListFiles.ProtoReflect.Descriptor instead.
+func (*ListFiles) Descriptor() ([]byte, []int) {
 	return file_torrent_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *File) GetName() string {
+func (x *ListFiles) GetCount() uint64 {
 	if x != nil {
-		return x.Name
+		return x.Count
 	}
-	return ""
+	return 0
 }
 
-type DownloadFileResponse struct {
+func (x *ListFiles) GetFiles() []*FileInfo {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+// права доступа к файлу на трекере
+type FileACL struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	FilePath string `protobuf:"bytes,1,opt,name=file_path,json=filePath,proto3" json:"file_path,omitempty"`
+	Hash          string     `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	Owner         string     `protobuf:"bytes,2,opt,name=owner,proto3" json:"owner,omitempty"` // peer_id владельца
+	Visibility    Visibility `protobuf:"varint,3,opt,name=visibility,proto3,enum=api.Visibility" json:"visibility,omitempty"`
+	AllowedPeers  []string   `protobuf:"bytes,4,rep,name=allowed_peers,json=allowedPeers,proto3" json:"allowed_peers,omitempty"`
+	AllowedGroups []string   `protobuf:"bytes,5,rep,name=allowed_groups,json=allowedGroups,proto3" json:"allowed_groups,omitempty"`
 }
 
-func (x *DownloadFileResponse) Reset() {
-	*x = DownloadFileResponse{}
+func (x *FileACL) Reset() {
+	*x = FileACL{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_torrent_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -638,13 +944,13 @@ func (x *DownloadFileResponse) Reset() {
 	}
 }
 
-func (x *DownloadFileResponse) String() string {
+func (x *FileACL) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DownloadFileResponse) ProtoMessage() {}
+func (*FileACL) ProtoMessage() {}
 
-func (x *DownloadFileResponse) ProtoReflect() protoreflect.Message {
+func (x *FileACL) ProtoReflect() protoreflect.Message {
 	mi := &file_torrent_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -656,29 +962,57 @@ func (x *DownloadFileResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DownloadFileResponse.ProtoReflect.Descriptor instead.
-func (*DownloadFileResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use FileACL.ProtoReflect.Descriptor instead.
+func (*FileACL) Descriptor() ([]byte, []int) {
 	return file_torrent_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *DownloadFileResponse) GetFilePath() string {
+func (x *FileACL) GetHash() string {
 	if x != nil {
-		return x.FilePath
+		return x.Hash
 	}
 	return ""
 }
 
-type ListPeers_Peer struct {
+func (x *FileACL) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *FileACL) GetVisibility() Visibility {
+	if x != nil {
+		return x.Visibility
+	}
+	return Visibility_PUBLIC
+}
+
+func (x *FileACL) GetAllowedPeers() []string {
+	if x != nil {
+		return x.AllowedPeers
+	}
+	return nil
+}
+
+func (x *FileACL) GetAllowedGroups() []string {
+	if x != nil {
+		return x.AllowedGroups
+	}
+	return nil
+}
+
+type PeerGroups struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Address      string   `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`                                       // summary address
-	SerialPieces []uint64 `protobuf:"varint,2,rep,packed,name=serial_pieces,json=serialPieces,proto3" json:"serial_pieces,omitempty"` // номера доступных кусочков
+	PeerId string   `protobuf:"bytes,1,opt,name=peer_id,json=peerId,proto3" json:"peer_id,omitempty"`
+	Groups []string `protobuf:"bytes,2,rep,name=groups,proto3" json:"groups,omitempty"`
 }
 
-func (x *ListPeers_Peer) Reset() {
-	*x = ListPeers_Peer{}
+func (x *PeerGroups) Reset() {
+	*x = PeerGroups{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_torrent_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -686,13 +1020,13 @@ func (x *ListPeers_Peer) Reset() {
 	}
 }
 
-func (x *ListPeers_Peer) String() string {
+func (x *PeerGroups) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListPeers_Peer) ProtoMessage() {}
+func (*PeerGroups) ProtoMessage() {}
 
-func (x *ListPeers_Peer) ProtoReflect() protoreflect.Message {
+func (x *PeerGroups) ProtoReflect() protoreflect.Message {
 	mi := &file_torrent_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -704,758 +1038,3262 @@ func (x *ListPeers_Peer) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListPeers_Peer.ProtoReflect.Descriptor instead.
-func (*ListPeers_Peer) Descriptor() ([]byte, []int) {
-	return file_torrent_proto_rawDescGZIP(), []int{2, 0}
+// Deprecated: Use PeerGroups.ProtoReflect.Descriptor instead.
+func (*PeerGroups) Descriptor() ([]byte, []int) {
+	return file_torrent_proto_rawDescGZIP(), []int{11}
 }
 
-func (x *ListPeers_Peer) GetAddress() string {
+func (x *PeerGroups) GetPeerId() string {
 	if x != nil {
-		return x.Address
+		return x.PeerId
 	}
 	return ""
 }
 
-func (x *ListPeers_Peer) GetSerialPieces() []uint64 {
+func (x *PeerGroups) GetGroups() []string {
 	if x != nil {
-		return x.SerialPieces
+		return x.Groups
 	}
 	return nil
 }
 
-var File_torrent_proto protoreflect.FileDescriptor
+type GetPeerGroupsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
 
-var file_torrent_proto_rawDesc = []byte{
-	0x0a, 0x0d, 0x74, 0x6f, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12,
-	0x03, 0x61, 0x70, 0x69, 0x1a, 0x1b, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x1a, 0x1c, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x61, 0x6e,
-	0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22,
-	0xab, 0x01, 0x0a, 0x11, 0x55, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x5f,
-	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74,
-	0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x70, 0x69, 0x65, 0x63, 0x65, 0x5f,
-	0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x70, 0x69,
-	0x65, 0x63, 0x65, 0x4c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x69, 0x65,
-	0x63, 0x65, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x70, 0x69, 0x65, 0x63, 0x65,
-	0x73, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x18, 0x05, 0x20, 0x01, 0x28,
-	0x04, 0x52, 0x06, 0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x61, 0x73,
-	0x68, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x68, 0x61, 0x73, 0x68, 0x22, 0x47, 0x0a,
-	0x0f, 0x47, 0x65, 0x74, 0x50, 0x65, 0x65, 0x72, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x12, 0x1b, 0x0a, 0x09, 0x68, 0x61, 0x73, 0x68, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x08, 0x68, 0x61, 0x73, 0x68, 0x46, 0x69, 0x6c, 0x65, 0x12, 0x17, 0x0a,
-	0x07, 0x70, 0x65, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
-	0x70, 0x65, 0x65, 0x72, 0x49, 0x64, 0x22, 0x93, 0x01, 0x0a, 0x09, 0x4c, 0x69, 0x73, 0x74, 0x50,
-	0x65, 0x65, 0x72, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x04, 0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x29, 0x0a, 0x05, 0x70, 0x65,
-	0x65, 0x72, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x61, 0x70, 0x69, 0x2e,
-	0x4c, 0x69, 0x73, 0x74, 0x50, 0x65, 0x65, 0x72, 0x73, 0x2e, 0x50, 0x65, 0x65, 0x72, 0x52, 0x05,
-	0x70, 0x65, 0x65, 0x72, 0x73, 0x1a, 0x45, 0x0a, 0x04, 0x50, 0x65, 0x65, 0x72, 0x12, 0x18, 0x0a,
-	0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
-	0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x73, 0x65, 0x72, 0x69, 0x61,
-	0x6c, 0x5f, 0x70, 0x69, 0x65, 0x63, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x04, 0x52, 0x0c,
-	0x73, 0x65, 0x72, 0x69, 0x61, 0x6c, 0x50, 0x69, 0x65, 0x63, 0x65, 0x73, 0x22, 0x40, 0x0a, 0x09,
-	0x50, 0x69, 0x65, 0x63, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x1b, 0x0a, 0x09, 0x68, 0x61, 0x73,
-	0x68, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x68, 0x61,
-	0x73, 0x68, 0x46, 0x69, 0x6c, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x65, 0x72, 0x69, 0x61, 0x6c,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x73, 0x65, 0x72, 0x69, 0x61, 0x6c, 0x22, 0x29,
-	0x0a, 0x13, 0x44, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x61, 0x73, 0x68, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x04, 0x68, 0x61, 0x73, 0x68, 0x22, 0x85, 0x01, 0x0a, 0x08, 0x46, 0x69,
-	0x6c, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x70, 0x69,
-	0x65, 0x63, 0x65, 0x5f, 0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04,
-	0x52, 0x0b, 0x70, 0x69, 0x65, 0x63, 0x65, 0x4c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x12, 0x16, 0x0a,
-	0x06, 0x70, 0x69, 0x65, 0x63, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x70,
-	0x69, 0x65, 0x63, 0x65, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x18,
-	0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x12, 0x12, 0x0a,
-	0x04, 0x68, 0x61, 0x73, 0x68, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x68, 0x61, 0x73,
-	0x68, 0x22, 0x46, 0x0a, 0x09, 0x4c, 0x69, 0x73, 0x74, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x14,
-	0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x63,
-	0x6f, 0x75, 0x6e, 0x74, 0x12, 0x23, 0x0a, 0x05, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x02, 0x20,
-	0x03, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x49, 0x6e,
-	0x66, 0x6f, 0x52, 0x05, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x22, 0x46, 0x0a, 0x05, 0x50, 0x69, 0x65,
-	0x63, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x0c, 0x52, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x12, 0x23, 0x0a, 0x0d,
-	0x73, 0x65, 0x72, 0x69, 0x61, 0x6c, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x04, 0x52, 0x0c, 0x73, 0x65, 0x72, 0x69, 0x61, 0x6c, 0x4e, 0x75, 0x6d, 0x62, 0x65,
-	0x72, 0x22, 0x4a, 0x0a, 0x0f, 0x47, 0x65, 0x74, 0x50, 0x69, 0x65, 0x63, 0x65, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x12, 0x23, 0x0a, 0x0d, 0x73, 0x65, 0x72, 0x69, 0x61, 0x6c, 0x5f, 0x6e,
-	0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c, 0x73, 0x65, 0x72,
-	0x69, 0x61, 0x6c, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x61, 0x73,
-	0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x68, 0x61, 0x73, 0x68, 0x22, 0x1a, 0x0a,
-	0x04, 0x46, 0x69, 0x6c, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x22, 0x33, 0x0a, 0x14, 0x44, 0x6f, 0x77,
-	0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
-	0x65, 0x12, 0x1b, 0x0a, 0x09, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x50, 0x61, 0x74, 0x68, 0x32, 0xca,
-	0x02, 0x0a, 0x07, 0x54, 0x72, 0x61, 0x63, 0x6b, 0x65, 0x72, 0x12, 0x4b, 0x0a, 0x11, 0x47, 0x65,
-	0x74, 0x41, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c, 0x65, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x12,
-	0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
-	0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x0e, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x4c, 0x69,
-	0x73, 0x74, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x22, 0x0e, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x08, 0x12,
-	0x06, 0x2f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x4d, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x46, 0x69,
-	0x6c, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x18, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x44, 0x6f, 0x77,
-	0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x1a, 0x0d, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x22,
-	0x15, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x0f, 0x12, 0x0d, 0x2f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x2f,
-	0x7b, 0x68, 0x61, 0x73, 0x68, 0x7d, 0x12, 0x38, 0x0a, 0x06, 0x55, 0x70, 0x6c, 0x6f, 0x61, 0x64,
-	0x12, 0x16, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x55, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x46, 0x69, 0x6c,
-	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
-	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79,
-	0x12, 0x30, 0x0a, 0x08, 0x47, 0x65, 0x74, 0x50, 0x65, 0x65, 0x72, 0x73, 0x12, 0x14, 0x2e, 0x61,
-	0x70, 0x69, 0x2e, 0x47, 0x65, 0x74, 0x50, 0x65, 0x65, 0x72, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x1a, 0x0e, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x50, 0x65, 0x65,
-	0x72, 0x73, 0x12, 0x37, 0x0a, 0x0d, 0x50, 0x6f, 0x73, 0x74, 0x50, 0x69, 0x65, 0x63, 0x65, 0x49,
-	0x6e, 0x66, 0x6f, 0x12, 0x0e, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x50, 0x69, 0x65, 0x63, 0x65, 0x49,
-	0x6e, 0x66, 0x6f, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x32, 0x90, 0x02, 0x0a, 0x04,
-	0x50, 0x65, 0x65, 0x72, 0x12, 0x2c, 0x0a, 0x08, 0x47, 0x65, 0x74, 0x50, 0x69, 0x65, 0x63, 0x65,
-	0x12, 0x14, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x47, 0x65, 0x74, 0x50, 0x69, 0x65, 0x63, 0x65, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0a, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x50, 0x69, 0x65,
-	0x63, 0x65, 0x12, 0x43, 0x0a, 0x0a, 0x55, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x46, 0x69, 0x6c, 0x65,
-	0x12, 0x09, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x1a, 0x16, 0x2e, 0x67, 0x6f,
-	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d,
-	0x70, 0x74, 0x79, 0x22, 0x12, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x0c, 0x22, 0x07, 0x2f, 0x75, 0x70,
-	0x6c, 0x6f, 0x61, 0x64, 0x3a, 0x01, 0x2a, 0x12, 0x3e, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x46, 0x69,
-	0x6c, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x09, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x46, 0x69, 0x6c,
-	0x65, 0x1a, 0x0d, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x49, 0x6e, 0x66, 0x6f,
-	0x22, 0x15, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x0f, 0x12, 0x0d, 0x2f, 0x66, 0x69, 0x6c, 0x65, 0x73,
-	0x2f, 0x7b, 0x6e, 0x61, 0x6d, 0x65, 0x7d, 0x12, 0x55, 0x0a, 0x08, 0x44, 0x6f, 0x77, 0x6e, 0x6c,
-	0x6f, 0x61, 0x64, 0x12, 0x18, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x44, 0x6f, 0x77, 0x6e, 0x6c, 0x6f,
-	0x61, 0x64, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e,
-	0x61, 0x70, 0x69, 0x2e, 0x44, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x46, 0x69, 0x6c, 0x65,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x14, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x0e,
-	0x22, 0x09, 0x2f, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x3a, 0x01, 0x2a, 0x42, 0x07,
-	0x5a, 0x05, 0x3a, 0x3b, 0x61, 0x70, 0x69, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	PeerId string `protobuf:"bytes,1,opt,name=peer_id,json=peerId,proto3" json:"peer_id,omitempty"`
 }
 
-var (
-	file_torrent_proto_rawDescOnce sync.Once
-	file_torrent_proto_rawDescData = file_torrent_proto_rawDesc
-)
+func (x *GetPeerGroupsRequest) Reset() {
+	*x = GetPeerGroupsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_torrent_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
 
-func file_torrent_proto_rawDescGZIP() []byte {
-	file_torrent_proto_rawDescOnce.Do(func() {
-		file_torrent_proto_rawDescData = protoimpl.X.CompressGZIP(file_torrent_proto_rawDescData)
-	})
-	return file_torrent_proto_rawDescData
+func (x *GetPeerGroupsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-var file_torrent_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
-var file_torrent_proto_goTypes = []interface{}{
-	(*UploadFileRequest)(nil),    // 0: api.UploadFileRequest
-	(*GetPeersRequest)(nil),      // 1: api.GetPeersRequest
-	(*ListPeers)(nil),            // 2: api.ListPeers
-	(*PieceInfo)(nil),            // 3: api.PieceInfo
-	(*DownloadFileRequest)(nil),  // 4: api.DownloadFileRequest
-	(*FileInfo)(nil),             // 5: api.FileInfo
-	(*ListFiles)(nil),            // 6: api.ListFiles
-	(*Piece)(nil),                // 7: api.Piece
-	(*GetPieceRequest)(nil),      // 8: api.GetPieceRequest
-	(*File)(nil),                 // 9: api.File
-	(*DownloadFileResponse)(nil), // 10: api.DownloadFileResponse
-	(*ListPeers_Peer)(nil),       // 11: api.ListPeers.Peer
-	(*empty.Empty)(nil),          // 12: google.protobuf.Empty
+func (*GetPeerGroupsRequest) ProtoMessage() {}
+
+func (x *GetPeerGroupsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_torrent_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
-var file_torrent_proto_depIdxs = []int32{
-	11, // 0: api.ListPeers.peers:type_name -> api.ListPeers.Peer
-	5,  // 1: api.ListFiles.files:type_name -> api.FileInfo
-	12, // 2: api.Tracker.GetAvailableFiles:input_type -> google.protobuf.Empty
-	4,  // 3: api.Tracker.GetFileInfo:input_type -> api.DownloadFileRequest
-	0,  // 4: api.Tracker.Upload:input_type -> api.UploadFileRequest
-	1,  // 5: api.Tracker.GetPeers:input_type -> api.GetPeersRequest
-	3,  // 6: api.Tracker.PostPieceInfo:input_type -> api.PieceInfo
-	8,  // 7: api.Peer.GetPiece:input_type -> api.GetPieceRequest
-	9,  // 8: api.Peer.UploadFile:input_type -> api.File
-	9,  // 9: api.Peer.GetFileInfo:input_type -> api.File
-	4,  // 10: api.Peer.Download:input_type -> api.DownloadFileRequest
-	6,  // 11: api.Tracker.GetAvailableFiles:output_type -> api.ListFiles
-	5,  // 12: api.Tracker.GetFileInfo:output_type -> api.FileInfo
-	12, // 13: api.Tracker.Upload:output_type -> google.protobuf.Empty
-	2,  // 14: api.Tracker.GetPeers:output_type -> api.ListPeers
-	12, // 15: api.Tracker.PostPieceInfo:output_type -> google.protobuf.Empty
-	7,  // 16: api.Peer.GetPiece:output_type -> api.Piece
-	12, // 17: api.Peer.UploadFile:output_type -> google.protobuf.Empty
-	5,  // 18: api.Peer.GetFileInfo:output_type -> api.FileInfo
-	10, // 19: api.Peer.Download:output_type -> api.DownloadFileResponse
-	11, // [11:20] is the sub-list for method output_type
-	2,  // [2:11] is the sub-list for method input_type
-	2,  // [2:2] is the sub-list for extension type_name
-	2,  // [2:2] is the sub-list for extension extendee
-	0,  // [0:2] is the sub-list for field type_name
+
+// Deprecated: Use GetPeerGroupsRequest.ProtoReflect.Descriptor instead.
+func (*GetPeerGroupsRequest) Descriptor() ([]byte, []int) {
+	return file_torrent_proto_rawDescGZIP(), []int{12}
 }
 
-func init() { file_torrent_proto_init() }
-func file_torrent_proto_init() {
-	if File_torrent_proto != nil {
-		return
+func (x *GetPeerGroupsRequest) GetPeerId() string {
+	if x != nil {
+		return x.PeerId
 	}
-	if !protoimpl.UnsafeEnabled {
-		file_torrent_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*UploadFileRequest); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_torrent_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetPeersRequest); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+	return ""
+}
+
+type ScrapeRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Hashes []string `protobuf:"bytes,1,rep,name=hashes,proto3" json:"hashes,omitempty"` // пусто - все файлы каталога
+}
+
+func (x *ScrapeRequest) Reset() {
+	*x = ScrapeRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_torrent_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ScrapeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScrapeRequest) ProtoMessage() {}
+
+func (x *ScrapeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_torrent_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
-		file_torrent_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ListPeers); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScrapeRequest.ProtoReflect.Descriptor instead.
+func (*ScrapeRequest) Descriptor() ([]byte, []int) {
+	return file_torrent_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ScrapeRequest) GetHashes() []string {
+	if x != nil {
+		return x.Hashes
+	}
+	return nil
+}
+
+type SwarmStats struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Hash         string   `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	Seeders      uint64   `protobuf:"varint,2,opt,name=seeders,proto3" json:"seeders,omitempty"`                  // пиры со всем файлом
+	Leechers     uint64   `protobuf:"varint,3,opt,name=leechers,proto3" json:"leechers,omitempty"`                // пиры с частью файла
+	Completed    uint64   `protobuf:"varint,4,opt,name=completed,proto3" json:"completed,omitempty"`              // сколько раз файл скачали целиком
+	Availability []uint64 `protobuf:"varint,5,rep,packed,name=availability,proto3" json:"availability,omitempty"` // сколько пиров раздают каждый кусок
+}
+
+func (x *SwarmStats) Reset() {
+	*x = SwarmStats{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_torrent_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SwarmStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SwarmStats) ProtoMessage() {}
+
+func (x *SwarmStats) ProtoReflect() protoreflect.Message {
+	mi := &file_torrent_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
-		file_torrent_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*PieceInfo); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SwarmStats.ProtoReflect.Descriptor instead.
+func (*SwarmStats) Descriptor() ([]byte, []int) {
+	return file_torrent_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *SwarmStats) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *SwarmStats) GetSeeders() uint64 {
+	if x != nil {
+		return x.Seeders
+	}
+	return 0
+}
+
+func (x *SwarmStats) GetLeechers() uint64 {
+	if x != nil {
+		return x.Leechers
+	}
+	return 0
+}
+
+func (x *SwarmStats) GetCompleted() uint64 {
+	if x != nil {
+		return x.Completed
+	}
+	return 0
+}
+
+func (x *SwarmStats) GetAvailability() []uint64 {
+	if x != nil {
+		return x.Availability
+	}
+	return nil
+}
+
+type ScrapeResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Files []*SwarmStats `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty"`
+}
+
+func (x *ScrapeResponse) Reset() {
+	*x = ScrapeResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_torrent_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ScrapeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScrapeResponse) ProtoMessage() {}
+
+func (x *ScrapeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_torrent_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
-		file_torrent_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*DownloadFileRequest); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScrapeResponse.ProtoReflect.Descriptor instead.
+func (*ScrapeResponse) Descriptor() ([]byte, []int) {
+	return file_torrent_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ScrapeResponse) GetFiles() []*SwarmStats {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+type Piece struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Payload      []byte `protobuf:"bytes,1,opt,name=payload,proto3" json:"payload,omitempty"`                                // кусочек
+	SerialNumber uint64 `protobuf:"varint,2,opt,name=serial_number,json=serialNumber,proto3" json:"serial_number,omitempty"` // номер кусочка
+}
+
+func (x *Piece) Reset() {
+	*x = Piece{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_torrent_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Piece) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Piece) ProtoMessage() {}
+
+func (x *Piece) ProtoReflect() protoreflect.Message {
+	mi := &file_torrent_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
-		file_torrent_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*FileInfo); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Piece.ProtoReflect.Descriptor instead.
+func (*Piece) Descriptor() ([]byte, []int) {
+	return file_torrent_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *Piece) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *Piece) GetSerialNumber() uint64 {
+	if x != nil {
+		return x.SerialNumber
+	}
+	return 0
+}
+
+type GetPieceRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SerialNumber uint64 `protobuf:"varint,1,opt,name=serial_number,json=serialNumber,proto3" json:"serial_number,omitempty"`
+	Hash         string `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
+	AccessToken  string `protobuf:"bytes,3,opt,name=access_token,json=accessToken,proto3" json:"access_token,omitempty"` // токен трекера, обязателен для приватных файлов
+}
+
+func (x *GetPieceRequest) Reset() {
+	*x = GetPieceRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_torrent_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetPieceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPieceRequest) ProtoMessage() {}
+
+func (x *GetPieceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_torrent_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
-		file_torrent_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ListFiles); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_torrent_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Piece); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_torrent_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetPieceRequest); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPieceRequest.ProtoReflect.Descriptor instead.
+func (*GetPieceRequest) Descriptor() ([]byte, []int) {
+	return file_torrent_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *GetPieceRequest) GetSerialNumber() uint64 {
+	if x != nil {
+		return x.SerialNumber
+	}
+	return 0
+}
+
+func (x *GetPieceRequest) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *GetPieceRequest) GetAccessToken() string {
+	if x != nil {
+		return x.AccessToken
+	}
+	return ""
+}
+
+type File struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name          string     `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Private       bool       `protobuf:"varint,2,opt,name=private,proto3" json:"private,omitempty"`                                 // загрузить файл как приватный
+	AllowedPeers  []string   `protobuf:"bytes,3,rep,name=allowed_peers,json=allowedPeers,proto3" json:"allowed_peers,omitempty"`    // кому еще, кроме себя, разрешить скачивание
+	Visibility    Visibility `protobuf:"varint,4,opt,name=visibility,proto3,enum=api.Visibility" json:"visibility,omitempty"`       // private = true равносилен PRIVATE
+	AllowedGroups []string   `protobuf:"bytes,5,rep,name=allowed_groups,json=allowedGroups,proto3" json:"allowed_groups,omitempty"` // каким группам пиров разрешить скачивание
+	Torrent       []byte     `protobuf:"bytes,6,opt,name=torrent,proto3" json:"torrent,omitempty"`                                  // торрент-файл BitTorrent v1, которому должен соответствовать раздаваемый файл
+}
+
+func (x *File) Reset() {
+	*x = File{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_torrent_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *File) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*File) ProtoMessage() {}
+
+func (x *File) ProtoReflect() protoreflect.Message {
+	mi := &file_torrent_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
-		file_torrent_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*File); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use File.ProtoReflect.Descriptor instead.
+func (*File) Descriptor() ([]byte, []int) {
+	return file_torrent_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *File) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *File) GetPrivate() bool {
+	if x != nil {
+		return x.Private
+	}
+	return false
+}
+
+func (x *File) GetAllowedPeers() []string {
+	if x != nil {
+		return x.AllowedPeers
+	}
+	return nil
+}
+
+func (x *File) GetVisibility() Visibility {
+	if x != nil {
+		return x.Visibility
+	}
+	return Visibility_PUBLIC
+}
+
+func (x *File) GetAllowedGroups() []string {
+	if x != nil {
+		return x.AllowedGroups
+	}
+	return nil
+}
+
+func (x *File) GetTorrent() []byte {
+	if x != nil {
+		return x.Torrent
+	}
+	return nil
+}
+
+type TrackerKey struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PublicKey []byte `protobuf:"bytes,1,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"` // ed25519 ключ, которым трекер подписывает токены доступа
+}
+
+func (x *TrackerKey) Reset() {
+	*x = TrackerKey{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_torrent_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TrackerKey) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrackerKey) ProtoMessage() {}
+
+func (x *TrackerKey) ProtoReflect() protoreflect.Message {
+	mi := &file_torrent_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
-		file_torrent_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*DownloadFileResponse); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrackerKey.ProtoReflect.Descriptor instead.
+func (*TrackerKey) Descriptor() ([]byte, []int) {
+	return file_torrent_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *TrackerKey) GetPublicKey() []byte {
+	if x != nil {
+		return x.PublicKey
+	}
+	return nil
+}
+
+type DownloadFileResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	FilePath string `protobuf:"bytes,1,opt,name=file_path,json=filePath,proto3" json:"file_path,omitempty"`
+}
+
+func (x *DownloadFileResponse) Reset() {
+	*x = DownloadFileResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_torrent_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DownloadFileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadFileResponse) ProtoMessage() {}
+
+func (x *DownloadFileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_torrent_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
-		file_torrent_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ListPeers_Peer); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadFileResponse.ProtoReflect.Descriptor instead.
+func (*DownloadFileResponse) Descriptor() ([]byte, []int) {
+	return file_torrent_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *DownloadFileResponse) GetFilePath() string {
+	if x != nil {
+		return x.FilePath
+	}
+	return ""
+}
+
+type PeerIdentity struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PeerId    string   `protobuf:"bytes,1,opt,name=peer_id,json=peerId,proto3" json:"peer_id,omitempty"`          // постоянный uuid пира, под ним пир виден на трекере
+	PublicKey []byte   `protobuf:"bytes,2,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"` // ed25519 ключ пира
+	Addresses []string `protobuf:"bytes,3,rep,name=addresses,proto3" json:"addresses,omitempty"`                  // адреса, которые пир объявляет трекеру
+}
+
+func (x *PeerIdentity) Reset() {
+	*x = PeerIdentity{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_torrent_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PeerIdentity) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PeerIdentity) ProtoMessage() {}
+
+func (x *PeerIdentity) ProtoReflect() protoreflect.Message {
+	mi := &file_torrent_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PeerIdentity.ProtoReflect.Descriptor instead.
+func (*PeerIdentity) Descriptor() ([]byte, []int) {
+	return file_torrent_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *PeerIdentity) GetPeerId() string {
+	if x != nil {
+		return x.PeerId
+	}
+	return ""
+}
+
+func (x *PeerIdentity) GetPublicKey() []byte {
+	if x != nil {
+		return x.PublicKey
+	}
+	return nil
+}
+
+func (x *PeerIdentity) GetAddresses() []string {
+	if x != nil {
+		return x.Addresses
+	}
+	return nil
+}
+
+// PeerExchange - участники роя, которых знает пир
+type PeerExchange struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Hash    string   `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	Address string   `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"` // grpc-адрес отправителя, в ответе пусто
+	Peers   []string `protobuf:"bytes,3,rep,name=peers,proto3" json:"peers,omitempty"`     // адреса других участников роя
+}
+
+func (x *PeerExchange) Reset() {
+	*x = PeerExchange{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_torrent_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PeerExchange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PeerExchange) ProtoMessage() {}
+
+func (x *PeerExchange) ProtoReflect() protoreflect.Message {
+	mi := &file_torrent_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PeerExchange.ProtoReflect.Descriptor instead.
+func (*PeerExchange) Descriptor() ([]byte, []int) {
+	return file_torrent_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *PeerExchange) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *PeerExchange) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *PeerExchange) GetPeers() []string {
+	if x != nil {
+		return x.Peers
+	}
+	return nil
+}
+
+type LocalPeersRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Hash string `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"` // пусто - по всем файлам
+}
+
+func (x *LocalPeersRequest) Reset() {
+	*x = LocalPeersRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_torrent_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LocalPeersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LocalPeersRequest) ProtoMessage() {}
+
+func (x *LocalPeersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_torrent_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LocalPeersRequest.ProtoReflect.Descriptor instead.
+func (*LocalPeersRequest) Descriptor() ([]byte, []int) {
+	return file_torrent_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *LocalPeersRequest) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+// LocalPeers - пиры, найденные в локальной сети по multicast-объявлениям
+type LocalPeers struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Peers []*LocalPeers_Peer `protobuf:"bytes,1,rep,name=peers,proto3" json:"peers,omitempty"`
+}
+
+func (x *LocalPeers) Reset() {
+	*x = LocalPeers{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_torrent_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LocalPeers) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LocalPeers) ProtoMessage() {}
+
+func (x *LocalPeers) ProtoReflect() protoreflect.Message {
+	mi := &file_torrent_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LocalPeers.ProtoReflect.Descriptor instead.
+func (*LocalPeers) Descriptor() ([]byte, []int) {
+	return file_torrent_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *LocalPeers) GetPeers() []*LocalPeers_Peer {
+	if x != nil {
+		return x.Peers
+	}
+	return nil
+}
+
+// DHTNode - узел DHT: 20-байтовый id и grpc-адрес пира
+type DHTNode struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id      []byte `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Address string `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+}
+
+func (x *DHTNode) Reset() {
+	*x = DHTNode{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_torrent_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DHTNode) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DHTNode) ProtoMessage() {}
+
+func (x *DHTNode) ProtoReflect() protoreflect.Message {
+	mi := &file_torrent_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DHTNode.ProtoReflect.Descriptor instead.
+func (*DHTNode) Descriptor() ([]byte, []int) {
+	return file_torrent_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *DHTNode) GetId() []byte {
+	if x != nil {
+		return x.Id
+	}
+	return nil
+}
+
+func (x *DHTNode) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+type DHTFindNodeRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Sender *DHTNode `protobuf:"bytes,1,opt,name=sender,proto3" json:"sender,omitempty"` // кто спрашивает, пусто - клиент не участвует в DHT
+	Target []byte   `protobuf:"bytes,2,opt,name=target,proto3" json:"target,omitempty"` // id, к которому ищутся ближайшие узлы
+}
+
+func (x *DHTFindNodeRequest) Reset() {
+	*x = DHTFindNodeRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_torrent_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DHTFindNodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DHTFindNodeRequest) ProtoMessage() {}
+
+func (x *DHTFindNodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_torrent_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DHTFindNodeRequest.ProtoReflect.Descriptor instead.
+func (*DHTFindNodeRequest) Descriptor() ([]byte, []int) {
+	return file_torrent_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *DHTFindNodeRequest) GetSender() *DHTNode {
+	if x != nil {
+		return x.Sender
+	}
+	return nil
+}
+
+func (x *DHTFindNodeRequest) GetTarget() []byte {
+	if x != nil {
+		return x.Target
+	}
+	return nil
+}
+
+type DHTNodes struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Sender *DHTNode   `protobuf:"bytes,1,opt,name=sender,proto3" json:"sender,omitempty"`
+	Nodes  []*DHTNode `protobuf:"bytes,2,rep,name=nodes,proto3" json:"nodes,omitempty"` // ближайшие к цели узлы из таблицы отвечающего
+}
+
+func (x *DHTNodes) Reset() {
+	*x = DHTNodes{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_torrent_proto_msgTypes[27]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DHTNodes) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DHTNodes) ProtoMessage() {}
+
+func (x *DHTNodes) ProtoReflect() protoreflect.Message {
+	mi := &file_torrent_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DHTNodes.ProtoReflect.Descriptor instead.
+func (*DHTNodes) Descriptor() ([]byte, []int) {
+	return file_torrent_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *DHTNodes) GetSender() *DHTNode {
+	if x != nil {
+		return x.Sender
+	}
+	return nil
+}
+
+func (x *DHTNodes) GetNodes() []*DHTNode {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+type DHTGetPeersRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Sender *DHTNode `protobuf:"bytes,1,opt,name=sender,proto3" json:"sender,omitempty"`
+	Hash   string   `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"` // хэш файла
+}
+
+func (x *DHTGetPeersRequest) Reset() {
+	*x = DHTGetPeersRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_torrent_proto_msgTypes[28]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DHTGetPeersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DHTGetPeersRequest) ProtoMessage() {}
+
+func (x *DHTGetPeersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_torrent_proto_msgTypes[28]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DHTGetPeersRequest.ProtoReflect.Descriptor instead.
+func (*DHTGetPeersRequest) Descriptor() ([]byte, []int) {
+	return file_torrent_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *DHTGetPeersRequest) GetSender() *DHTNode {
+	if x != nil {
+		return x.Sender
+	}
+	return nil
+}
+
+func (x *DHTGetPeersRequest) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+type DHTGetPeersResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Sender *DHTNode   `protobuf:"bytes,1,opt,name=sender,proto3" json:"sender,omitempty"`
+	Peers  []*DHTNode `protobuf:"bytes,2,rep,name=peers,proto3" json:"peers,omitempty"` // пиры, объявившие файл
+	Nodes  []*DHTNode `protobuf:"bytes,3,rep,name=nodes,proto3" json:"nodes,omitempty"` // узлы ближе к файлу, если своих пиров мало
+	Token  []byte     `protobuf:"bytes,4,opt,name=token,proto3" json:"token,omitempty"` // токен для AnnouncePeer с того же адреса
+}
+
+func (x *DHTGetPeersResponse) Reset() {
+	*x = DHTGetPeersResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_torrent_proto_msgTypes[29]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DHTGetPeersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DHTGetPeersResponse) ProtoMessage() {}
+
+func (x *DHTGetPeersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_torrent_proto_msgTypes[29]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DHTGetPeersResponse.ProtoReflect.Descriptor instead.
+func (*DHTGetPeersResponse) Descriptor() ([]byte, []int) {
+	return file_torrent_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *DHTGetPeersResponse) GetSender() *DHTNode {
+	if x != nil {
+		return x.Sender
+	}
+	return nil
+}
+
+func (x *DHTGetPeersResponse) GetPeers() []*DHTNode {
+	if x != nil {
+		return x.Peers
+	}
+	return nil
+}
+
+func (x *DHTGetPeersResponse) GetNodes() []*DHTNode {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+func (x *DHTGetPeersResponse) GetToken() []byte {
+	if x != nil {
+		return x.Token
+	}
+	return nil
+}
+
+type DHTAnnounceRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Sender *DHTNode `protobuf:"bytes,1,opt,name=sender,proto3" json:"sender,omitempty"` // объявляется адрес отправителя: ip соединения и порт из address
+	Hash   string   `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
+	Token  []byte   `protobuf:"bytes,3,opt,name=token,proto3" json:"token,omitempty"` // токен из GetPeers
+}
+
+func (x *DHTAnnounceRequest) Reset() {
+	*x = DHTAnnounceRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_torrent_proto_msgTypes[30]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DHTAnnounceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DHTAnnounceRequest) ProtoMessage() {}
+
+func (x *DHTAnnounceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_torrent_proto_msgTypes[30]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DHTAnnounceRequest.ProtoReflect.Descriptor instead.
+func (*DHTAnnounceRequest) Descriptor() ([]byte, []int) {
+	return file_torrent_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *DHTAnnounceRequest) GetSender() *DHTNode {
+	if x != nil {
+		return x.Sender
+	}
+	return nil
+}
+
+func (x *DHTAnnounceRequest) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *DHTAnnounceRequest) GetToken() []byte {
+	if x != nil {
+		return x.Token
+	}
+	return nil
+}
+
+type ListPeers_Peer struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Address      string    `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`                                       // summary address
+	SerialPieces []uint64  `protobuf:"varint,2,rep,packed,name=serial_pieces,json=serialPieces,proto3" json:"serial_pieces,omitempty"` // номера доступных кусочков
+	PeerId       string    `protobuf:"bytes,3,opt,name=peer_id,json=peerId,proto3" json:"peer_id,omitempty"`                           // uuid пира
+	Addresses    []string  `protobuf:"bytes,4,rep,name=addresses,proto3" json:"addresses,omitempty"`                                   // все объявленные пиром адреса, address - первый из них
+	Pieces       *Bitfield `protobuf:"bytes,5,opt,name=pieces,proto3" json:"pieces,omitempty"`                                         // доступные кусочки, если запрошены битовым полем
+}
+
+func (x *ListPeers_Peer) Reset() {
+	*x = ListPeers_Peer{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_torrent_proto_msgTypes[31]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListPeers_Peer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPeers_Peer) ProtoMessage() {}
+
+func (x *ListPeers_Peer) ProtoReflect() protoreflect.Message {
+	mi := &file_torrent_proto_msgTypes[31]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPeers_Peer.ProtoReflect.Descriptor instead.
+func (*ListPeers_Peer) Descriptor() ([]byte, []int) {
+	return file_torrent_proto_rawDescGZIP(), []int{3, 0}
+}
+
+func (x *ListPeers_Peer) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *ListPeers_Peer) GetSerialPieces() []uint64 {
+	if x != nil {
+		return x.SerialPieces
+	}
+	return nil
+}
+
+func (x *ListPeers_Peer) GetPeerId() string {
+	if x != nil {
+		return x.PeerId
+	}
+	return ""
+}
+
+func (x *ListPeers_Peer) GetAddresses() []string {
+	if x != nil {
+		return x.Addresses
+	}
+	return nil
+}
+
+func (x *ListPeers_Peer) GetPieces() *Bitfield {
+	if x != nil {
+		return x.Pieces
+	}
+	return nil
+}
+
+type LocalPeers_Peer struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Hash    string `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	Address string `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`             // объявленный grpc-адрес
+	PeerId  string `protobuf:"bytes,3,opt,name=peer_id,json=peerId,proto3" json:"peer_id,omitempty"` // peer_id из объявления, не проверяется
+}
+
+func (x *LocalPeers_Peer) Reset() {
+	*x = LocalPeers_Peer{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_torrent_proto_msgTypes[32]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LocalPeers_Peer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LocalPeers_Peer) ProtoMessage() {}
+
+func (x *LocalPeers_Peer) ProtoReflect() protoreflect.Message {
+	mi := &file_torrent_proto_msgTypes[32]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LocalPeers_Peer.ProtoReflect.Descriptor instead.
+func (*LocalPeers_Peer) Descriptor() ([]byte, []int) {
+	return file_torrent_proto_rawDescGZIP(), []int{24, 0}
+}
+
+func (x *LocalPeers_Peer) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *LocalPeers_Peer) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *LocalPeers_Peer) GetPeerId() string {
+	if x != nil {
+		return x.PeerId
+	}
+	return ""
+}
+
+var File_torrent_proto protoreflect.FileDescriptor
+
+var file_torrent_proto_rawDesc = []byte{
+	0x0a, 0x0d, 0x74, 0x6f, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12,
+	0x03, 0x61, 0x70, 0x69, 0x1a, 0x1b, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x1a, 0x1c, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x61, 0x6e,
+	0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22,
+	0xd7, 0x03, 0x0a, 0x11, 0x55, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x5f,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74,
+	0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x70, 0x69, 0x65, 0x63, 0x65, 0x5f,
+	0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x70, 0x69,
+	0x65, 0x63, 0x65, 0x4c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x69, 0x65,
+	0x63, 0x65, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x70, 0x69, 0x65, 0x63, 0x65,
+	0x73, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x06, 0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x61, 0x73,
+	0x68, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x68, 0x61, 0x73, 0x68, 0x12, 0x18, 0x0a,
+	0x07, 0x70, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07,
+	0x70, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x61, 0x6c, 0x6c, 0x6f, 0x77,
+	0x65, 0x64, 0x5f, 0x70, 0x65, 0x65, 0x72, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0c,
+	0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x50, 0x65, 0x65, 0x72, 0x73, 0x12, 0x2f, 0x0a, 0x0a,
+	0x76, 0x69, 0x73, 0x69, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0e,
+	0x32, 0x0f, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x56, 0x69, 0x73, 0x69, 0x62, 0x69, 0x6c, 0x69, 0x74,
+	0x79, 0x52, 0x0a, 0x76, 0x69, 0x73, 0x69, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x12, 0x25, 0x0a,
+	0x0e, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x5f, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x18,
+	0x0a, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0d, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x47, 0x72,
+	0x6f, 0x75, 0x70, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x70, 0x69, 0x65, 0x63, 0x65, 0x5f, 0x68, 0x61,
+	0x73, 0x68, 0x65, 0x73, 0x18, 0x0b, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x0b, 0x70, 0x69, 0x65, 0x63,
+	0x65, 0x48, 0x61, 0x73, 0x68, 0x65, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x70, 0x75, 0x62, 0x6c, 0x69,
+	0x73, 0x68, 0x65, 0x72, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0c,
+	0x70, 0x75, 0x62, 0x6c, 0x69, 0x73, 0x68, 0x65, 0x72, 0x4b, 0x65, 0x79, 0x12, 0x1c, 0x0a, 0x09,
+	0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x12, 0x2d, 0x0a, 0x0a, 0x70, 0x69,
+	0x65, 0x63, 0x65, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0e,
+	0x2e, 0x61, 0x70, 0x69, 0x2e, 0x50, 0x69, 0x65, 0x63, 0x65, 0x48, 0x61, 0x73, 0x68, 0x52, 0x09,
+	0x70, 0x69, 0x65, 0x63, 0x65, 0x48, 0x61, 0x73, 0x68, 0x22, 0xa5, 0x01, 0x0a, 0x0f, 0x47, 0x65,
+	0x74, 0x50, 0x65, 0x65, 0x72, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1b, 0x0a,
+	0x09, 0x68, 0x61, 0x73, 0x68, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x08, 0x68, 0x61, 0x73, 0x68, 0x46, 0x69, 0x6c, 0x65, 0x12, 0x17, 0x0a, 0x07, 0x70, 0x65,
+	0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x65, 0x65,
+	0x72, 0x49, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x61, 0x78, 0x5f, 0x70, 0x65, 0x65, 0x72, 0x73,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x6d, 0x61, 0x78, 0x50, 0x65, 0x65, 0x72, 0x73,
+	0x12, 0x23, 0x0a, 0x0d, 0x77, 0x61, 0x6e, 0x74, 0x65, 0x64, 0x5f, 0x70, 0x69, 0x65, 0x63, 0x65,
+	0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x04, 0x52, 0x0c, 0x77, 0x61, 0x6e, 0x74, 0x65, 0x64, 0x50,
+	0x69, 0x65, 0x63, 0x65, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x62, 0x69, 0x74, 0x66, 0x69, 0x65, 0x6c,
+	0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x62, 0x69, 0x74, 0x66, 0x69, 0x65, 0x6c,
+	0x64, 0x22, 0x32, 0x0a, 0x08, 0x42, 0x69, 0x74, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x12, 0x12, 0x0a,
+	0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x04, 0x73, 0x69, 0x7a,
+	0x65, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x75, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x04, 0x52,
+	0x04, 0x72, 0x75, 0x6e, 0x73, 0x22, 0xba, 0x02, 0x0a, 0x09, 0x4c, 0x69, 0x73, 0x74, 0x50, 0x65,
+	0x65, 0x72, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x29, 0x0a, 0x05, 0x70, 0x65, 0x65,
+	0x72, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x4c,
+	0x69, 0x73, 0x74, 0x50, 0x65, 0x65, 0x72, 0x73, 0x2e, 0x50, 0x65, 0x65, 0x72, 0x52, 0x05, 0x70,
+	0x65, 0x65, 0x72, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x61, 0x63, 0x63, 0x65, 0x73, 0x73, 0x5f, 0x74,
+	0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x61, 0x63, 0x63, 0x65,
+	0x73, 0x73, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x23, 0x0a, 0x0d, 0x74, 0x6f, 0x6b, 0x65, 0x6e,
+	0x5f, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c,
+	0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x45, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x1a, 0xa3, 0x01, 0x0a,
+	0x04, 0x50, 0x65, 0x65, 0x72, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12,
+	0x23, 0x0a, 0x0d, 0x73, 0x65, 0x72, 0x69, 0x61, 0x6c, 0x5f, 0x70, 0x69, 0x65, 0x63, 0x65, 0x73,
+	0x18, 0x02, 0x20, 0x03, 0x28, 0x04, 0x52, 0x0c, 0x73, 0x65, 0x72, 0x69, 0x61, 0x6c, 0x50, 0x69,
+	0x65, 0x63, 0x65, 0x73, 0x12, 0x17, 0x0a, 0x07, 0x70, 0x65, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x65, 0x65, 0x72, 0x49, 0x64, 0x12, 0x1c, 0x0a,
+	0x09, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x09, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x65, 0x73, 0x12, 0x25, 0x0a, 0x06, 0x70,
+	0x69, 0x65, 0x63, 0x65, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x61, 0x70,
+	0x69, 0x2e, 0x42, 0x69, 0x74, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x52, 0x06, 0x70, 0x69, 0x65, 0x63,
+	0x65, 0x73, 0x22, 0x67, 0x0a, 0x09, 0x50, 0x69, 0x65, 0x63, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x12,
+	0x1b, 0x0a, 0x09, 0x68, 0x61, 0x73, 0x68, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x08, 0x68, 0x61, 0x73, 0x68, 0x46, 0x69, 0x6c, 0x65, 0x12, 0x16, 0x0a, 0x06,
+	0x73, 0x65, 0x72, 0x69, 0x61, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x73, 0x65,
+	0x72, 0x69, 0x61, 0x6c, 0x12, 0x25, 0x0a, 0x06, 0x70, 0x69, 0x65, 0x63, 0x65, 0x73, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x42, 0x69, 0x74, 0x66, 0x69,
+	0x65, 0x6c, 0x64, 0x52, 0x06, 0x70, 0x69, 0x65, 0x63, 0x65, 0x73, 0x22, 0x33, 0x0a, 0x0b, 0x50,
+	0x69, 0x65, 0x63, 0x65, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x24, 0x0a, 0x05, 0x66, 0x69,
+	0x6c, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x61, 0x70, 0x69, 0x2e,
+	0x50, 0x69, 0x65, 0x63, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x05, 0x66, 0x69, 0x6c, 0x65, 0x73,
+	0x22, 0x2f, 0x0a, 0x11, 0x50, 0x69, 0x65, 0x63, 0x65, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x52,
+	0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x6a, 0x65, 0x63, 0x74, 0x65,
+	0x64, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x72, 0x65, 0x6a, 0x65, 0x63, 0x74, 0x65,
+	0x64, 0x22, 0x87, 0x01, 0x0a, 0x13, 0x44, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x46, 0x69,
+	0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x61, 0x73,
+	0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x68, 0x61, 0x73, 0x68, 0x12, 0x1a, 0x0a,
+	0x08, 0x6d, 0x65, 0x74, 0x61, 0x69, 0x6e, 0x66, 0x6f, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x08, 0x6d, 0x65, 0x74, 0x61, 0x69, 0x6e, 0x66, 0x6f, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72, 0x69,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x69, 0x12, 0x18, 0x0a, 0x07, 0x74,
+	0x6f, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x74, 0x6f,
+	0x72, 0x72, 0x65, 0x6e, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x65, 0x65, 0x72, 0x73, 0x18, 0x05,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x70, 0x65, 0x65, 0x72, 0x73, 0x22, 0x93, 0x03, 0x0a, 0x08,
+	0x46, 0x69, 0x6c, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x21, 0x0a, 0x0c,
+	0x70, 0x69, 0x65, 0x63, 0x65, 0x5f, 0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x0b, 0x70, 0x69, 0x65, 0x63, 0x65, 0x4c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x12,
+	0x16, 0x0a, 0x06, 0x70, 0x69, 0x65, 0x63, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x06, 0x70, 0x69, 0x65, 0x63, 0x65, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x65, 0x6e, 0x67, 0x74,
+	0x68, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x12,
+	0x12, 0x0a, 0x04, 0x68, 0x61, 0x73, 0x68, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x68,
+	0x61, 0x73, 0x68, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x70, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x12, 0x2f, 0x0a,
+	0x0a, 0x76, 0x69, 0x73, 0x69, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x0e, 0x32, 0x0f, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x56, 0x69, 0x73, 0x69, 0x62, 0x69, 0x6c, 0x69,
+	0x74, 0x79, 0x52, 0x0a, 0x76, 0x69, 0x73, 0x69, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x12, 0x21,
+	0x0a, 0x0c, 0x70, 0x69, 0x65, 0x63, 0x65, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x65, 0x73, 0x18, 0x08,
+	0x20, 0x03, 0x28, 0x0c, 0x52, 0x0b, 0x70, 0x69, 0x65, 0x63, 0x65, 0x48, 0x61, 0x73, 0x68, 0x65,
+	0x73, 0x12, 0x23, 0x0a, 0x0d, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x73, 0x68, 0x65, 0x72, 0x5f, 0x6b,
+	0x65, 0x79, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0c, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x73,
+	0x68, 0x65, 0x72, 0x4b, 0x65, 0x79, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74,
+	0x75, 0x72, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61,
+	0x74, 0x75, 0x72, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x69, 0x6e, 0x66, 0x6f,
+	0x18, 0x0b, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x69, 0x6e, 0x66, 0x6f,
+	0x12, 0x10, 0x0a, 0x03, 0x75, 0x72, 0x69, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75,
+	0x72, 0x69, 0x12, 0x2d, 0x0a, 0x0a, 0x70, 0x69, 0x65, 0x63, 0x65, 0x5f, 0x68, 0x61, 0x73, 0x68,
+	0x18, 0x0d, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0e, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x50, 0x69, 0x65,
+	0x63, 0x65, 0x48, 0x61, 0x73, 0x68, 0x52, 0x09, 0x70, 0x69, 0x65, 0x63, 0x65, 0x48, 0x61, 0x73,
+	0x68, 0x22, 0x46, 0x0a, 0x09, 0x4c, 0x69, 0x73, 0x74, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x14,
+	0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x63,
+	0x6f, 0x75, 0x6e, 0x74, 0x12, 0x23, 0x0a, 0x05, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x02, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x49, 0x6e,
+	0x66, 0x6f, 0x52, 0x05, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x22, 0xb0, 0x01, 0x0a, 0x07, 0x46, 0x69,
+	0x6c, 0x65, 0x41, 0x43, 0x4c, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x61, 0x73, 0x68, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x68, 0x61, 0x73, 0x68, 0x12, 0x14, 0x0a, 0x05, 0x6f, 0x77, 0x6e,
+	0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x12,
+	0x2f, 0x0a, 0x0a, 0x76, 0x69, 0x73, 0x69, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x0f, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x56, 0x69, 0x73, 0x69, 0x62, 0x69,
+	0x6c, 0x69, 0x74, 0x79, 0x52, 0x0a, 0x76, 0x69, 0x73, 0x69, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79,
+	0x12, 0x23, 0x0a, 0x0d, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x5f, 0x70, 0x65, 0x65, 0x72,
+	0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0c, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64,
+	0x50, 0x65, 0x65, 0x72, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64,
+	0x5f, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0d, 0x61,
+	0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x22, 0x3d, 0x0a, 0x0a,
+	0x50, 0x65, 0x65, 0x72, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x12, 0x17, 0x0a, 0x07, 0x70, 0x65,
+	0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x65, 0x65,
+	0x72, 0x49, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x18, 0x02, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x06, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x22, 0x2f, 0x0a, 0x14, 0x47,
+	0x65, 0x74, 0x50, 0x65, 0x65, 0x72, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x70, 0x65, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x65, 0x65, 0x72, 0x49, 0x64, 0x22, 0x27, 0x0a, 0x0d,
+	0x53, 0x63, 0x72, 0x61, 0x70, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a,
+	0x06, 0x68, 0x61, 0x73, 0x68, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x68,
+	0x61, 0x73, 0x68, 0x65, 0x73, 0x22, 0x98, 0x01, 0x0a, 0x0a, 0x53, 0x77, 0x61, 0x72, 0x6d, 0x53,
+	0x74, 0x61, 0x74, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x61, 0x73, 0x68, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x68, 0x61, 0x73, 0x68, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x65, 0x65, 0x64,
+	0x65, 0x72, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x73, 0x65, 0x65, 0x64, 0x65,
+	0x72, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x6c, 0x65, 0x65, 0x63, 0x68, 0x65, 0x72, 0x73, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x6c, 0x65, 0x65, 0x63, 0x68, 0x65, 0x72, 0x73, 0x12, 0x1c,
+	0x0a, 0x09, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x09, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x12, 0x22, 0x0a, 0x0c,
+	0x61, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x18, 0x05, 0x20, 0x03,
+	0x28, 0x04, 0x52, 0x0c, 0x61, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79,
+	0x22, 0x37, 0x0a, 0x0e, 0x53, 0x63, 0x72, 0x61, 0x70, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x25, 0x0a, 0x05, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x0f, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x53, 0x77, 0x61, 0x72, 0x6d, 0x53, 0x74, 0x61,
+	0x74, 0x73, 0x52, 0x05, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x22, 0x46, 0x0a, 0x05, 0x50, 0x69, 0x65,
+	0x63, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x12, 0x23, 0x0a, 0x0d,
+	0x73, 0x65, 0x72, 0x69, 0x61, 0x6c, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x0c, 0x73, 0x65, 0x72, 0x69, 0x61, 0x6c, 0x4e, 0x75, 0x6d, 0x62, 0x65,
+	0x72, 0x22, 0x6d, 0x0a, 0x0f, 0x47, 0x65, 0x74, 0x50, 0x69, 0x65, 0x63, 0x65, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x23, 0x0a, 0x0d, 0x73, 0x65, 0x72, 0x69, 0x61, 0x6c, 0x5f, 0x6e,
+	0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c, 0x73, 0x65, 0x72,
+	0x69, 0x61, 0x6c, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x61, 0x73,
+	0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x68, 0x61, 0x73, 0x68, 0x12, 0x21, 0x0a,
+	0x0c, 0x61, 0x63, 0x63, 0x65, 0x73, 0x73, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0b, 0x61, 0x63, 0x63, 0x65, 0x73, 0x73, 0x54, 0x6f, 0x6b, 0x65, 0x6e,
+	0x22, 0xcb, 0x01, 0x0a, 0x04, 0x46, 0x69, 0x6c, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x18, 0x0a,
+	0x07, 0x70, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07,
+	0x70, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x61, 0x6c, 0x6c, 0x6f, 0x77,
+	0x65, 0x64, 0x5f, 0x70, 0x65, 0x65, 0x72, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0c,
+	0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x50, 0x65, 0x65, 0x72, 0x73, 0x12, 0x2f, 0x0a, 0x0a,
+	0x76, 0x69, 0x73, 0x69, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e,
+	0x32, 0x0f, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x56, 0x69, 0x73, 0x69, 0x62, 0x69, 0x6c, 0x69, 0x74,
+	0x79, 0x52, 0x0a, 0x76, 0x69, 0x73, 0x69, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x12, 0x25, 0x0a,
+	0x0e, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x5f, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x18,
+	0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0d, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x47, 0x72,
+	0x6f, 0x75, 0x70, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x74, 0x6f, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x74, 0x6f, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x22, 0x2b,
+	0x0a, 0x0a, 0x54, 0x72, 0x61, 0x63, 0x6b, 0x65, 0x72, 0x4b, 0x65, 0x79, 0x12, 0x1d, 0x0a, 0x0a,
+	0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x09, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x22, 0x33, 0x0a, 0x14, 0x44,
+	0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x70, 0x61, 0x74, 0x68,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x50, 0x61, 0x74, 0x68,
+	0x22, 0x64, 0x0a, 0x0c, 0x50, 0x65, 0x65, 0x72, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79,
+	0x12, 0x17, 0x0a, 0x07, 0x70, 0x65, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x70, 0x65, 0x65, 0x72, 0x49, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x75, 0x62,
+	0x6c, 0x69, 0x63, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x70,
+	0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x12, 0x1c, 0x0a, 0x09, 0x61, 0x64, 0x64, 0x72,
+	0x65, 0x73, 0x73, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x09, 0x61, 0x64, 0x64,
+	0x72, 0x65, 0x73, 0x73, 0x65, 0x73, 0x22, 0x52, 0x0a, 0x0c, 0x50, 0x65, 0x65, 0x72, 0x45, 0x78,
+	0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x61, 0x73, 0x68, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x68, 0x61, 0x73, 0x68, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x64,
+	0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x61, 0x64, 0x64,
+	0x72, 0x65, 0x73, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x65, 0x65, 0x72, 0x73, 0x18, 0x03, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x05, 0x70, 0x65, 0x65, 0x72, 0x73, 0x22, 0x27, 0x0a, 0x11, 0x4c, 0x6f,
+	0x63, 0x61, 0x6c, 0x50, 0x65, 0x65, 0x72, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x12, 0x0a, 0x04, 0x68, 0x61, 0x73, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x68,
+	0x61, 0x73, 0x68, 0x22, 0x87, 0x01, 0x0a, 0x0a, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x50, 0x65, 0x65,
+	0x72, 0x73, 0x12, 0x2a, 0x0a, 0x05, 0x70, 0x65, 0x65, 0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x14, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x50, 0x65, 0x65,
+	0x72, 0x73, 0x2e, 0x50, 0x65, 0x65, 0x72, 0x52, 0x05, 0x70, 0x65, 0x65, 0x72, 0x73, 0x1a, 0x4d,
+	0x0a, 0x04, 0x50, 0x65, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x61, 0x73, 0x68, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x68, 0x61, 0x73, 0x68, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x64,
+	0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x61, 0x64, 0x64,
+	0x72, 0x65, 0x73, 0x73, 0x12, 0x17, 0x0a, 0x07, 0x70, 0x65, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x65, 0x65, 0x72, 0x49, 0x64, 0x22, 0x33, 0x0a,
+	0x07, 0x44, 0x48, 0x54, 0x4e, 0x6f, 0x64, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x02, 0x69, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x64, 0x64, 0x72,
+	0x65, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65,
+	0x73, 0x73, 0x22, 0x52, 0x0a, 0x12, 0x44, 0x48, 0x54, 0x46, 0x69, 0x6e, 0x64, 0x4e, 0x6f, 0x64,
+	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x24, 0x0a, 0x06, 0x73, 0x65, 0x6e, 0x64,
+	0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x44,
+	0x48, 0x54, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x06, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x12, 0x16,
+	0x0a, 0x06, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06,
+	0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x22, 0x54, 0x0a, 0x08, 0x44, 0x48, 0x54, 0x4e, 0x6f, 0x64,
+	0x65, 0x73, 0x12, 0x24, 0x0a, 0x06, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x44, 0x48, 0x54, 0x4e, 0x6f, 0x64, 0x65,
+	0x52, 0x06, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x12, 0x22, 0x0a, 0x05, 0x6e, 0x6f, 0x64, 0x65,
+	0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x44, 0x48,
+	0x54, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x05, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x22, 0x4e, 0x0a, 0x12,
+	0x44, 0x48, 0x54, 0x47, 0x65, 0x74, 0x50, 0x65, 0x65, 0x72, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x24, 0x0a, 0x06, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x44, 0x48, 0x54, 0x4e, 0x6f, 0x64, 0x65,
+	0x52, 0x06, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x61, 0x73, 0x68,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x68, 0x61, 0x73, 0x68, 0x22, 0x99, 0x01, 0x0a,
+	0x13, 0x44, 0x48, 0x54, 0x47, 0x65, 0x74, 0x50, 0x65, 0x65, 0x72, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x24, 0x0a, 0x06, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x44, 0x48, 0x54, 0x4e, 0x6f,
+	0x64, 0x65, 0x52, 0x06, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x12, 0x22, 0x0a, 0x05, 0x70, 0x65,
+	0x65, 0x72, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x61, 0x70, 0x69, 0x2e,
+	0x44, 0x48, 0x54, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x05, 0x70, 0x65, 0x65, 0x72, 0x73, 0x12, 0x22,
+	0x0a, 0x05, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e,
+	0x61, 0x70, 0x69, 0x2e, 0x44, 0x48, 0x54, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x05, 0x6e, 0x6f, 0x64,
+	0x65, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x22, 0x64, 0x0a, 0x12, 0x44, 0x48, 0x54, 0x41,
+	0x6e, 0x6e, 0x6f, 0x75, 0x6e, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x24,
+	0x0a, 0x06, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c,
+	0x2e, 0x61, 0x70, 0x69, 0x2e, 0x44, 0x48, 0x54, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x06, 0x73, 0x65,
+	0x6e, 0x64, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x61, 0x73, 0x68, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x68, 0x61, 0x73, 0x68, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x6b, 0x65,
+	0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x2a, 0x33,
+	0x0a, 0x0a, 0x56, 0x69, 0x73, 0x69, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x12, 0x0a, 0x0a, 0x06,
+	0x50, 0x55, 0x42, 0x4c, 0x49, 0x43, 0x10, 0x00, 0x12, 0x0c, 0x0a, 0x08, 0x55, 0x4e, 0x4c, 0x49,
+	0x53, 0x54, 0x45, 0x44, 0x10, 0x01, 0x12, 0x0b, 0x0a, 0x07, 0x50, 0x52, 0x49, 0x56, 0x41, 0x54,
+	0x45, 0x10, 0x02, 0x2a, 0x21, 0x0a, 0x09, 0x50, 0x69, 0x65, 0x63, 0x65, 0x48, 0x61, 0x73, 0x68,
+	0x12, 0x0a, 0x0a, 0x06, 0x53, 0x48, 0x41, 0x32, 0x35, 0x36, 0x10, 0x00, 0x12, 0x08, 0x0a, 0x04,
+	0x53, 0x48, 0x41, 0x31, 0x10, 0x01, 0x32, 0xf4, 0x02, 0x0a, 0x0c, 0x54, 0x72, 0x61, 0x63, 0x6b,
+	0x65, 0x72, 0x41, 0x64, 0x6d, 0x69, 0x6e, 0x12, 0x55, 0x0a, 0x0a, 0x47, 0x65, 0x74, 0x46, 0x69,
+	0x6c, 0x65, 0x41, 0x43, 0x4c, 0x12, 0x18, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x44, 0x6f, 0x77, 0x6e,
+	0x6c, 0x6f, 0x61, 0x64, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x0c, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x41, 0x43, 0x4c, 0x22, 0x1f, 0x82,
+	0xd3, 0xe4, 0x93, 0x02, 0x19, 0x12, 0x17, 0x2f, 0x61, 0x64, 0x6d, 0x69, 0x6e, 0x2f, 0x66, 0x69,
+	0x6c, 0x65, 0x73, 0x2f, 0x7b, 0x68, 0x61, 0x73, 0x68, 0x7d, 0x2f, 0x61, 0x63, 0x6c, 0x12, 0x4c,
+	0x0a, 0x0a, 0x53, 0x65, 0x74, 0x46, 0x69, 0x6c, 0x65, 0x41, 0x43, 0x4c, 0x12, 0x0c, 0x2e, 0x61,
+	0x70, 0x69, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x41, 0x43, 0x4c, 0x1a, 0x0c, 0x2e, 0x61, 0x70, 0x69,
+	0x2e, 0x46, 0x69, 0x6c, 0x65, 0x41, 0x43, 0x4c, 0x22, 0x22, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1c,
+	0x1a, 0x17, 0x2f, 0x61, 0x64, 0x6d, 0x69, 0x6e, 0x2f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x2f, 0x7b,
+	0x68, 0x61, 0x73, 0x68, 0x7d, 0x2f, 0x61, 0x63, 0x6c, 0x3a, 0x01, 0x2a, 0x12, 0x62, 0x0a, 0x0d,
+	0x47, 0x65, 0x74, 0x50, 0x65, 0x65, 0x72, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x12, 0x19, 0x2e,
+	0x61, 0x70, 0x69, 0x2e, 0x47, 0x65, 0x74, 0x50, 0x65, 0x65, 0x72, 0x47, 0x72, 0x6f, 0x75, 0x70,
+	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0f, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x50,
+	0x65, 0x65, 0x72, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x22, 0x25, 0x82, 0xd3, 0xe4, 0x93, 0x02,
+	0x1f, 0x12, 0x1d, 0x2f, 0x61, 0x64, 0x6d, 0x69, 0x6e, 0x2f, 0x70, 0x65, 0x65, 0x72, 0x73, 0x2f,
+	0x7b, 0x70, 0x65, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x7d, 0x2f, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x73,
+	0x12, 0x5b, 0x0a, 0x0d, 0x53, 0x65, 0x74, 0x50, 0x65, 0x65, 0x72, 0x47, 0x72, 0x6f, 0x75, 0x70,
+	0x73, 0x12, 0x0f, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x50, 0x65, 0x65, 0x72, 0x47, 0x72, 0x6f, 0x75,
+	0x70, 0x73, 0x1a, 0x0f, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x50, 0x65, 0x65, 0x72, 0x47, 0x72, 0x6f,
+	0x75, 0x70, 0x73, 0x22, 0x28, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x22, 0x1a, 0x1d, 0x2f, 0x61, 0x64,
+	0x6d, 0x69, 0x6e, 0x2f, 0x70, 0x65, 0x65, 0x72, 0x73, 0x2f, 0x7b, 0x70, 0x65, 0x65, 0x72, 0x5f,
+	0x69, 0x64, 0x7d, 0x2f, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x3a, 0x01, 0x2a, 0x32, 0x85, 0x04,
+	0x0a, 0x07, 0x54, 0x72, 0x61, 0x63, 0x6b, 0x65, 0x72, 0x12, 0x4b, 0x0a, 0x11, 0x47, 0x65, 0x74,
+	0x41, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c, 0x65, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x16,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x0e, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x4c, 0x69, 0x73,
+	0x74, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x22, 0x0e, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x08, 0x12, 0x06,
+	0x2f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x4d, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x46, 0x69, 0x6c,
+	0x65, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x18, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x44, 0x6f, 0x77, 0x6e,
+	0x6c, 0x6f, 0x61, 0x64, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x0d, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x22, 0x15,
+	0x82, 0xd3, 0xe4, 0x93, 0x02, 0x0f, 0x12, 0x0d, 0x2f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x2f, 0x7b,
+	0x68, 0x61, 0x73, 0x68, 0x7d, 0x12, 0x38, 0x0a, 0x06, 0x55, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x12,
+	0x16, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x55, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x46, 0x69, 0x6c, 0x65,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12,
+	0x30, 0x0a, 0x08, 0x47, 0x65, 0x74, 0x50, 0x65, 0x65, 0x72, 0x73, 0x12, 0x14, 0x2e, 0x61, 0x70,
+	0x69, 0x2e, 0x47, 0x65, 0x74, 0x50, 0x65, 0x65, 0x72, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x0e, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x50, 0x65, 0x65, 0x72,
+	0x73, 0x12, 0x37, 0x0a, 0x0d, 0x50, 0x6f, 0x73, 0x74, 0x50, 0x69, 0x65, 0x63, 0x65, 0x49, 0x6e,
+	0x66, 0x6f, 0x12, 0x0e, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x50, 0x69, 0x65, 0x63, 0x65, 0x49, 0x6e,
+	0x66, 0x6f, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x3b, 0x0a, 0x0f, 0x50, 0x6f,
+	0x73, 0x74, 0x50, 0x69, 0x65, 0x63, 0x65, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x10, 0x2e,
+	0x61, 0x70, 0x69, 0x2e, 0x50, 0x69, 0x65, 0x63, 0x65, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x1a,
+	0x16, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x50, 0x69, 0x65, 0x63, 0x65, 0x52, 0x65, 0x70, 0x6f, 0x72,
+	0x74, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x38, 0x0a, 0x0d, 0x47, 0x65, 0x74, 0x54, 0x72,
+	0x61, 0x63, 0x6b, 0x65, 0x72, 0x4b, 0x65, 0x79, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79,
+	0x1a, 0x0f, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x54, 0x72, 0x61, 0x63, 0x6b, 0x65, 0x72, 0x4b, 0x65,
+	0x79, 0x12, 0x42, 0x0a, 0x06, 0x53, 0x63, 0x72, 0x61, 0x70, 0x65, 0x12, 0x12, 0x2e, 0x61, 0x70,
+	0x69, 0x2e, 0x53, 0x63, 0x72, 0x61, 0x70, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x13, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x53, 0x63, 0x72, 0x61, 0x70, 0x65, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x0f, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x09, 0x12, 0x07, 0x2f, 0x73,
+	0x77, 0x61, 0x72, 0x6d, 0x73, 0x32, 0xdd, 0x01, 0x0a, 0x04, 0x50, 0x65, 0x65, 0x72, 0x12, 0x2c,
+	0x0a, 0x08, 0x47, 0x65, 0x74, 0x50, 0x69, 0x65, 0x63, 0x65, 0x12, 0x14, 0x2e, 0x61, 0x70, 0x69,
+	0x2e, 0x47, 0x65, 0x74, 0x50, 0x69, 0x65, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x0a, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x50, 0x69, 0x65, 0x63, 0x65, 0x12, 0x38, 0x0a, 0x0b,
+	0x47, 0x65, 0x74, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x12, 0x16, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d,
+	0x70, 0x74, 0x79, 0x1a, 0x11, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x50, 0x65, 0x65, 0x72, 0x49, 0x64,
+	0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x12, 0x35, 0x0a, 0x0d, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e,
+	0x67, 0x65, 0x50, 0x65, 0x65, 0x72, 0x73, 0x12, 0x11, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x50, 0x65,
+	0x65, 0x72, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x1a, 0x11, 0x2e, 0x61, 0x70, 0x69,
+	0x2e, 0x50, 0x65, 0x65, 0x72, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x36, 0x0a,
+	0x0b, 0x47, 0x65, 0x74, 0x46, 0x69, 0x6c, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x18, 0x2e, 0x61,
+	0x70, 0x69, 0x2e, 0x44, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x46, 0x69, 0x6c, 0x65, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0d, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x46, 0x69, 0x6c,
+	0x65, 0x49, 0x6e, 0x66, 0x6f, 0x32, 0xdd, 0x01, 0x0a, 0x03, 0x44, 0x48, 0x54, 0x12, 0x22, 0x0a,
+	0x04, 0x50, 0x69, 0x6e, 0x67, 0x12, 0x0c, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x44, 0x48, 0x54, 0x4e,
+	0x6f, 0x64, 0x65, 0x1a, 0x0c, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x44, 0x48, 0x54, 0x4e, 0x6f, 0x64,
+	0x65, 0x12, 0x32, 0x0a, 0x08, 0x46, 0x69, 0x6e, 0x64, 0x4e, 0x6f, 0x64, 0x65, 0x12, 0x17, 0x2e,
+	0x61, 0x70, 0x69, 0x2e, 0x44, 0x48, 0x54, 0x46, 0x69, 0x6e, 0x64, 0x4e, 0x6f, 0x64, 0x65, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0d, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x44, 0x48, 0x54,
+	0x4e, 0x6f, 0x64, 0x65, 0x73, 0x12, 0x3d, 0x0a, 0x08, 0x47, 0x65, 0x74, 0x50, 0x65, 0x65, 0x72,
+	0x73, 0x12, 0x17, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x44, 0x48, 0x54, 0x47, 0x65, 0x74, 0x50, 0x65,
+	0x65, 0x72, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x61, 0x70, 0x69,
+	0x2e, 0x44, 0x48, 0x54, 0x47, 0x65, 0x74, 0x50, 0x65, 0x65, 0x72, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3f, 0x0a, 0x0c, 0x41, 0x6e, 0x6e, 0x6f, 0x75, 0x6e, 0x63, 0x65,
+	0x50, 0x65, 0x65, 0x72, 0x12, 0x17, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x44, 0x48, 0x54, 0x41, 0x6e,
+	0x6e, 0x6f, 0x75, 0x6e, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x45, 0x6d, 0x70, 0x74, 0x79, 0x32, 0xf9, 0x02, 0x0a, 0x07, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x6f,
+	0x6c, 0x12, 0x3a, 0x0a, 0x0a, 0x55, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x46, 0x69, 0x6c, 0x65, 0x12,
+	0x09, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x1a, 0x0d, 0x2e, 0x61, 0x70, 0x69,
+	0x2e, 0x46, 0x69, 0x6c, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x22, 0x12, 0x82, 0xd3, 0xe4, 0x93, 0x02,
+	0x0c, 0x22, 0x07, 0x2f, 0x75, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x3a, 0x01, 0x2a, 0x12, 0x3e, 0x0a,
+	0x0b, 0x47, 0x65, 0x74, 0x46, 0x69, 0x6c, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x09, 0x2e, 0x61,
+	0x70, 0x69, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x1a, 0x0d, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x46, 0x69,
+	0x6c, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x22, 0x15, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x0f, 0x12, 0x0d,
+	0x2f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x2f, 0x7b, 0x6e, 0x61, 0x6d, 0x65, 0x7d, 0x12, 0x55, 0x0a,
+	0x08, 0x44, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x12, 0x18, 0x2e, 0x61, 0x70, 0x69, 0x2e,
+	0x44, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x44, 0x6f, 0x77, 0x6e, 0x6c, 0x6f,
+	0x61, 0x64, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x14,
+	0x82, 0xd3, 0xe4, 0x93, 0x02, 0x0e, 0x22, 0x09, 0x2f, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61,
+	0x64, 0x3a, 0x01, 0x2a, 0x12, 0x4b, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x49, 0x64, 0x65, 0x6e, 0x74,
+	0x69, 0x74, 0x79, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x11, 0x2e, 0x61, 0x70,
+	0x69, 0x2e, 0x50, 0x65, 0x65, 0x72, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x22, 0x11,
+	0x82, 0xd3, 0xe4, 0x93, 0x02, 0x0b, 0x12, 0x09, 0x2f, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74,
+	0x79, 0x12, 0x4e, 0x0a, 0x0d, 0x47, 0x65, 0x74, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x50, 0x65, 0x65,
+	0x72, 0x73, 0x12, 0x16, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x50, 0x65,
+	0x65, 0x72, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0f, 0x2e, 0x61, 0x70, 0x69,
+	0x2e, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x50, 0x65, 0x65, 0x72, 0x73, 0x22, 0x14, 0x82, 0xd3, 0xe4,
+	0x93, 0x02, 0x0e, 0x12, 0x0c, 0x2f, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x2d, 0x70, 0x65, 0x65, 0x72,
+	0x73, 0x42, 0x07, 0x5a, 0x05, 0x3a, 0x3b, 0x61, 0x70, 0x69, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x33,
+}
+
+var (
+	file_torrent_proto_rawDescOnce sync.Once
+	file_torrent_proto_rawDescData = file_torrent_proto_rawDesc
+)
+
+func file_torrent_proto_rawDescGZIP() []byte {
+	file_torrent_proto_rawDescOnce.Do(func() {
+		file_torrent_proto_rawDescData = protoimpl.X.CompressGZIP(file_torrent_proto_rawDescData)
+	})
+	return file_torrent_proto_rawDescData
+}
+
+var file_torrent_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_torrent_proto_msgTypes = make([]protoimpl.MessageInfo, 33)
+var file_torrent_proto_goTypes = []interface{}{
+	(Visibility)(0),              // 0: api.Visibility
+	(PieceHash)(0),               // 1: api.PieceHash
+	(*UploadFileRequest)(nil),    // 2: api.UploadFileRequest
+	(*GetPeersRequest)(nil),      // 3: api.GetPeersRequest
+	(*Bitfield)(nil),             // 4: api.Bitfield
+	(*ListPeers)(nil),            // 5: api.ListPeers
+	(*PieceInfo)(nil),            // 6: api.PieceInfo
+	(*PieceReport)(nil),          // 7: api.PieceReport
+	(*PieceReportResult)(nil),    // 8: api.PieceReportResult
+	(*DownloadFileRequest)(nil),  // 9: api.DownloadFileRequest
+	(*FileInfo)(nil),             // 10: api.FileInfo
+	(*ListFiles)(nil),            // 11: api.ListFiles
+	(*FileACL)(nil),              // 12: api.FileACL
+	(*PeerGroups)(nil),           // 13: api.PeerGroups
+	(*GetPeerGroupsRequest)(nil), // 14: api.GetPeerGroupsRequest
+	(*ScrapeRequest)(nil),        // 15: api.ScrapeRequest
+	(*SwarmStats)(nil),           // 16: api.SwarmStats
+	(*ScrapeResponse)(nil),       // 17: api.ScrapeResponse
+	(*Piece)(nil),                // 18: api.Piece
+	(*GetPieceRequest)(nil),      // 19: api.GetPieceRequest
+	(*File)(nil),                 // 20: api.File
+	(*TrackerKey)(nil),           // 21: api.TrackerKey
+	(*DownloadFileResponse)(nil), // 22: api.DownloadFileResponse
+	(*PeerIdentity)(nil),         // 23: api.PeerIdentity
+	(*PeerExchange)(nil),         // 24: api.PeerExchange
+	(*LocalPeersRequest)(nil),    // 25: api.LocalPeersRequest
+	(*LocalPeers)(nil),           // 26: api.LocalPeers
+	(*DHTNode)(nil),              // 27: api.DHTNode
+	(*DHTFindNodeRequest)(nil),   // 28: api.DHTFindNodeRequest
+	(*DHTNodes)(nil),             // 29: api.DHTNodes
+	(*DHTGetPeersRequest)(nil),   // 30: api.DHTGetPeersRequest
+	(*DHTGetPeersResponse)(nil),  // 31: api.DHTGetPeersResponse
+	(*DHTAnnounceRequest)(nil),   // 32: api.DHTAnnounceRequest
+	(*ListPeers_Peer)(nil),       // 33: api.ListPeers.Peer
+	(*LocalPeers_Peer)(nil),      // 34: api.LocalPeers.Peer
+	(*empty.Empty)(nil),          // 35: google.protobuf.Empty
+}
+var file_torrent_proto_depIdxs = []int32{
+	0,  // 0: api.UploadFileRequest.visibility:type_name -> api.Visibility
+	1,  // 1: api.UploadFileRequest.piece_hash:type_name -> api.PieceHash
+	33, // 2: api.ListPeers.peers:type_name -> api.ListPeers.Peer
+	4,  // 3: api.PieceInfo.pieces:type_name -> api.Bitfield
+	6,  // 4: api.PieceReport.files:type_name -> api.PieceInfo
+	0,  // 5: api.FileInfo.visibility:type_name -> api.Visibility
+	1,  // 6: api.FileInfo.piece_hash:type_name -> api.PieceHash
+	10, // 7: api.ListFiles.files:type_name -> api.FileInfo
+	0,  // 8: api.FileACL.visibility:type_name -> api.Visibility
+	16, // 9: api.ScrapeResponse.files:type_name -> api.SwarmStats
+	0,  // 10: api.File.visibility:type_name -> api.Visibility
+	34, // 11: api.LocalPeers.peers:type_name -> api.LocalPeers.Peer
+	27, // 12: api.DHTFindNodeRequest.sender:type_name -> api.DHTNode
+	27, // 13: api.DHTNodes.sender:type_name -> api.DHTNode
+	27, // 14: api.DHTNodes.nodes:type_name -> api.DHTNode
+	27, // 15: api.DHTGetPeersRequest.sender:type_name -> api.DHTNode
+	27, // 16: api.DHTGetPeersResponse.sender:type_name -> api.DHTNode
+	27, // 17: api.DHTGetPeersResponse.peers:type_name -> api.DHTNode
+	27, // 18: api.DHTGetPeersResponse.nodes:type_name -> api.DHTNode
+	27, // 19: api.DHTAnnounceRequest.sender:type_name -> api.DHTNode
+	4,  // 20: api.ListPeers.Peer.pieces:type_name -> api.Bitfield
+	9,  // 21: api.TrackerAdmin.GetFileACL:input_type -> api.DownloadFileRequest
+	12, // 22: api.TrackerAdmin.SetFileACL:input_type -> api.FileACL
+	14, // 23: api.TrackerAdmin.GetPeerGroups:input_type -> api.GetPeerGroupsRequest
+	13, // 24: api.TrackerAdmin.SetPeerGroups:input_type -> api.PeerGroups
+	35, // 25: api.Tracker.GetAvailableFiles:input_type -> google.protobuf.Empty
+	9,  // 26: api.Tracker.GetFileInfo:input_type -> api.DownloadFileRequest
+	2,  // 27: api.Tracker.Upload:input_type -> api.UploadFileRequest
+	3,  // 28: api.Tracker.GetPeers:input_type -> api.GetPeersRequest
+	6,  // 29: api.Tracker.PostPieceInfo:input_type -> api.PieceInfo
+	7,  // 30: api.Tracker.PostPieceReport:input_type -> api.PieceReport
+	35, // 31: api.Tracker.GetTrackerKey:input_type -> google.protobuf.Empty
+	15, // 32: api.Tracker.Scrape:input_type -> api.ScrapeRequest
+	19, // 33: api.Peer.GetPiece:input_type -> api.GetPieceRequest
+	35, // 34: api.Peer.GetIdentity:input_type -> google.protobuf.Empty
+	24, // 35: api.Peer.ExchangePeers:input_type -> api.PeerExchange
+	9,  // 36: api.Peer.GetFileInfo:input_type -> api.DownloadFileRequest
+	27, // 37: api.DHT.Ping:input_type -> api.DHTNode
+	28, // 38: api.DHT.FindNode:input_type -> api.DHTFindNodeRequest
+	30, // 39: api.DHT.GetPeers:input_type -> api.DHTGetPeersRequest
+	32, // 40: api.DHT.AnnouncePeer:input_type -> api.DHTAnnounceRequest
+	20, // 41: api.Control.UploadFile:input_type -> api.File
+	20, // 42: api.Control.GetFileInfo:input_type -> api.File
+	9,  // 43: api.Control.Download:input_type -> api.DownloadFileRequest
+	35, // 44: api.Control.GetIdentity:input_type -> google.protobuf.Empty
+	25, // 45: api.Control.GetLocalPeers:input_type -> api.LocalPeersRequest
+	12, // 46: api.TrackerAdmin.GetFileACL:output_type -> api.FileACL
+	12, // 47: api.TrackerAdmin.SetFileACL:output_type -> api.FileACL
+	13, // 48: api.TrackerAdmin.GetPeerGroups:output_type -> api.PeerGroups
+	13, // 49: api.TrackerAdmin.SetPeerGroups:output_type -> api.PeerGroups
+	11, // 50: api.Tracker.GetAvailableFiles:output_type -> api.ListFiles
+	10, // 51: api.Tracker.GetFileInfo:output_type -> api.FileInfo
+	35, // 52: api.Tracker.Upload:output_type -> google.protobuf.Empty
+	5,  // 53: api.Tracker.GetPeers:output_type -> api.ListPeers
+	35, // 54: api.Tracker.PostPieceInfo:output_type -> google.protobuf.Empty
+	8,  // 55: api.Tracker.PostPieceReport:output_type -> api.PieceReportResult
+	21, // 56: api.Tracker.GetTrackerKey:output_type -> api.TrackerKey
+	17, // 57: api.Tracker.Scrape:output_type -> api.ScrapeResponse
+	18, // 58: api.Peer.GetPiece:output_type -> api.Piece
+	23, // 59: api.Peer.GetIdentity:output_type -> api.PeerIdentity
+	24, // 60: api.Peer.ExchangePeers:output_type -> api.PeerExchange
+	10, // 61: api.Peer.GetFileInfo:output_type -> api.FileInfo
+	27, // 62: api.DHT.Ping:output_type -> api.DHTNode
+	29, // 63: api.DHT.FindNode:output_type -> api.DHTNodes
+	31, // 64: api.DHT.GetPeers:output_type -> api.DHTGetPeersResponse
+	35, // 65: api.DHT.AnnouncePeer:output_type -> google.protobuf.Empty
+	10, // 66: api.Control.UploadFile:output_type -> api.FileInfo
+	10, // 67: api.Control.GetFileInfo:output_type -> api.FileInfo
+	22, // 68: api.Control.Download:output_type -> api.DownloadFileResponse
+	23, // 69: api.Control.GetIdentity:output_type -> api.PeerIdentity
+	26, // 70: api.Control.GetLocalPeers:output_type -> api.LocalPeers
+	46, // [46:71] is the sub-list for method output_type
+	21, // [21:46] is the sub-list for method input_type
+	21, // [21:21] is the sub-list for extension type_name
+	21, // [21:21] is the sub-list for extension extendee
+	0,  // [0:21] is the sub-list for field type_name
+}
+
+func init() { file_torrent_proto_init() }
+func file_torrent_proto_init() {
+	if File_torrent_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_torrent_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UploadFileRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_torrent_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetPeersRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_torrent_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Bitfield); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_torrent_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListPeers); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_torrent_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PieceInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_torrent_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PieceReport); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_torrent_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PieceReportResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_torrent_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DownloadFileRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_torrent_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FileInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_torrent_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListFiles); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_torrent_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FileACL); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_torrent_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PeerGroups); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_torrent_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetPeerGroupsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_torrent_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ScrapeRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_torrent_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SwarmStats); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_torrent_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ScrapeResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_torrent_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Piece); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_torrent_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetPieceRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_torrent_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*File); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_torrent_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TrackerKey); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_torrent_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DownloadFileResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_torrent_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PeerIdentity); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_torrent_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PeerExchange); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_torrent_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LocalPeersRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_torrent_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LocalPeers); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_torrent_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DHTNode); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_torrent_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DHTFindNodeRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_torrent_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DHTNodes); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_torrent_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DHTGetPeersRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_torrent_proto_msgTypes[29].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DHTGetPeersResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_torrent_proto_msgTypes[30].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DHTAnnounceRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_torrent_proto_msgTypes[31].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListPeers_Peer); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_torrent_proto_msgTypes[32].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LocalPeers_Peer); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_torrent_proto_rawDesc,
+			NumEnums:      2,
+			NumMessages:   33,
+			NumExtensions: 0,
+			NumServices:   5,
+		},
+		GoTypes:           file_torrent_proto_goTypes,
+		DependencyIndexes: file_torrent_proto_depIdxs,
+		EnumInfos:         file_torrent_proto_enumTypes,
+		MessageInfos:      file_torrent_proto_msgTypes,
+	}.Build()
+	File_torrent_proto = out.File
+	file_torrent_proto_rawDesc = nil
+	file_torrent_proto_goTypes = nil
+	file_torrent_proto_depIdxs = nil
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// TrackerAdminClient is the client API for TrackerAdmin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type TrackerAdminClient interface {
+	GetFileACL(ctx context.Context, in *DownloadFileRequest, opts ...grpc.CallOption) (*FileACL, error)
+	SetFileACL(ctx context.Context, in *FileACL, opts ...grpc.CallOption) (*FileACL, error)
+	GetPeerGroups(ctx context.Context, in *GetPeerGroupsRequest, opts ...grpc.CallOption) (*PeerGroups, error)
+	SetPeerGroups(ctx context.Context, in *PeerGroups, opts ...grpc.CallOption) (*PeerGroups, error)
+}
+
+type trackerAdminClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTrackerAdminClient(cc grpc.ClientConnInterface) TrackerAdminClient {
+	return &trackerAdminClient{cc}
+}
+
+func (c *trackerAdminClient) GetFileACL(ctx context.Context, in *DownloadFileRequest, opts ...grpc.CallOption) (*FileACL, error) {
+	out := new(FileACL)
+	err := c.cc.Invoke(ctx, "/api.TrackerAdmin/GetFileACL", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trackerAdminClient) SetFileACL(ctx context.Context, in *FileACL, opts ...grpc.CallOption) (*FileACL, error) {
+	out := new(FileACL)
+	err := c.cc.Invoke(ctx, "/api.TrackerAdmin/SetFileACL", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trackerAdminClient) GetPeerGroups(ctx context.Context, in *GetPeerGroupsRequest, opts ...grpc.CallOption) (*PeerGroups, error) {
+	out := new(PeerGroups)
+	err := c.cc.Invoke(ctx, "/api.TrackerAdmin/GetPeerGroups", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trackerAdminClient) SetPeerGroups(ctx context.Context, in *PeerGroups, opts ...grpc.CallOption) (*PeerGroups, error) {
+	out := new(PeerGroups)
+	err := c.cc.Invoke(ctx, "/api.TrackerAdmin/SetPeerGroups", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TrackerAdminServer is the server API for TrackerAdmin service.
+type TrackerAdminServer interface {
+	GetFileACL(context.Context, *DownloadFileRequest) (*FileACL, error)
+	SetFileACL(context.Context, *FileACL) (*FileACL, error)
+	GetPeerGroups(context.Context, *GetPeerGroupsRequest) (*PeerGroups, error)
+	SetPeerGroups(context.Context, *PeerGroups) (*PeerGroups, error)
+}
+
+// UnimplementedTrackerAdminServer can be embedded to have forward compatible implementations.
+type UnimplementedTrackerAdminServer struct {
+}
+
+func (*UnimplementedTrackerAdminServer) GetFileACL(context.Context, *DownloadFileRequest) (*FileACL, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFileACL not implemented")
+}
+func (*UnimplementedTrackerAdminServer) SetFileACL(context.Context, *FileACL) (*FileACL, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetFileACL not implemented")
+}
+func (*UnimplementedTrackerAdminServer) GetPeerGroups(context.Context, *GetPeerGroupsRequest) (*PeerGroups, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPeerGroups not implemented")
+}
+func (*UnimplementedTrackerAdminServer) SetPeerGroups(context.Context, *PeerGroups) (*PeerGroups, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetPeerGroups not implemented")
+}
+
+func RegisterTrackerAdminServer(s *grpc.Server, srv TrackerAdminServer) {
+	s.RegisterService(&_TrackerAdmin_serviceDesc, srv)
+}
+
+func _TrackerAdmin_GetFileACL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DownloadFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrackerAdminServer).GetFileACL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.TrackerAdmin/GetFileACL",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrackerAdminServer).GetFileACL(ctx, req.(*DownloadFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrackerAdmin_SetFileACL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FileACL)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrackerAdminServer).SetFileACL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.TrackerAdmin/SetFileACL",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrackerAdminServer).SetFileACL(ctx, req.(*FileACL))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrackerAdmin_GetPeerGroups_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPeerGroupsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrackerAdminServer).GetPeerGroups(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.TrackerAdmin/GetPeerGroups",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrackerAdminServer).GetPeerGroups(ctx, req.(*GetPeerGroupsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrackerAdmin_SetPeerGroups_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PeerGroups)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrackerAdminServer).SetPeerGroups(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.TrackerAdmin/SetPeerGroups",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrackerAdminServer).SetPeerGroups(ctx, req.(*PeerGroups))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _TrackerAdmin_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "api.TrackerAdmin",
+	HandlerType: (*TrackerAdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetFileACL",
+			Handler:    _TrackerAdmin_GetFileACL_Handler,
+		},
+		{
+			MethodName: "SetFileACL",
+			Handler:    _TrackerAdmin_SetFileACL_Handler,
+		},
+		{
+			MethodName: "GetPeerGroups",
+			Handler:    _TrackerAdmin_GetPeerGroups_Handler,
+		},
+		{
+			MethodName: "SetPeerGroups",
+			Handler:    _TrackerAdmin_SetPeerGroups_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "torrent.proto",
+}
+
+// TrackerClient is the client API for Tracker service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type TrackerClient interface {
+	GetAvailableFiles(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*ListFiles, error)
+	GetFileInfo(ctx context.Context, in *DownloadFileRequest, opts ...grpc.CallOption) (*FileInfo, error)
+	Upload(ctx context.Context, in *UploadFileRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	GetPeers(ctx context.Context, in *GetPeersRequest, opts ...grpc.CallOption) (*ListPeers, error)
+	PostPieceInfo(ctx context.Context, in *PieceInfo, opts ...grpc.CallOption) (*empty.Empty, error)
+	PostPieceReport(ctx context.Context, in *PieceReport, opts ...grpc.CallOption) (*PieceReportResult, error)
+	GetTrackerKey(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*TrackerKey, error)
+	Scrape(ctx context.Context, in *ScrapeRequest, opts ...grpc.CallOption) (*ScrapeResponse, error)
+}
+
+type trackerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTrackerClient(cc grpc.ClientConnInterface) TrackerClient {
+	return &trackerClient{cc}
+}
+
+func (c *trackerClient) GetAvailableFiles(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*ListFiles, error) {
+	out := new(ListFiles)
+	err := c.cc.Invoke(ctx, "/api.Tracker/GetAvailableFiles", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trackerClient) GetFileInfo(ctx context.Context, in *DownloadFileRequest, opts ...grpc.CallOption) (*FileInfo, error) {
+	out := new(FileInfo)
+	err := c.cc.Invoke(ctx, "/api.Tracker/GetFileInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trackerClient) Upload(ctx context.Context, in *UploadFileRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/api.Tracker/Upload", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trackerClient) GetPeers(ctx context.Context, in *GetPeersRequest, opts ...grpc.CallOption) (*ListPeers, error) {
+	out := new(ListPeers)
+	err := c.cc.Invoke(ctx, "/api.Tracker/GetPeers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trackerClient) PostPieceInfo(ctx context.Context, in *PieceInfo, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/api.Tracker/PostPieceInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trackerClient) PostPieceReport(ctx context.Context, in *PieceReport, opts ...grpc.CallOption) (*PieceReportResult, error) {
+	out := new(PieceReportResult)
+	err := c.cc.Invoke(ctx, "/api.Tracker/PostPieceReport", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trackerClient) GetTrackerKey(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*TrackerKey, error) {
+	out := new(TrackerKey)
+	err := c.cc.Invoke(ctx, "/api.Tracker/GetTrackerKey", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trackerClient) Scrape(ctx context.Context, in *ScrapeRequest, opts ...grpc.CallOption) (*ScrapeResponse, error) {
+	out := new(ScrapeResponse)
+	err := c.cc.Invoke(ctx, "/api.Tracker/Scrape", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TrackerServer is the server API for Tracker service.
+type TrackerServer interface {
+	GetAvailableFiles(context.Context, *empty.Empty) (*ListFiles, error)
+	GetFileInfo(context.Context, *DownloadFileRequest) (*FileInfo, error)
+	Upload(context.Context, *UploadFileRequest) (*empty.Empty, error)
+	GetPeers(context.Context, *GetPeersRequest) (*ListPeers, error)
+	PostPieceInfo(context.Context, *PieceInfo) (*empty.Empty, error)
+	PostPieceReport(context.Context, *PieceReport) (*PieceReportResult, error)
+	GetTrackerKey(context.Context, *empty.Empty) (*TrackerKey, error)
+	Scrape(context.Context, *ScrapeRequest) (*ScrapeResponse, error)
+}
+
+// UnimplementedTrackerServer can be embedded to have forward compatible implementations.
+type UnimplementedTrackerServer struct {
+}
+
+func (*UnimplementedTrackerServer) GetAvailableFiles(context.Context, *empty.Empty) (*ListFiles, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAvailableFiles not implemented")
+}
+func (*UnimplementedTrackerServer) GetFileInfo(context.Context, *DownloadFileRequest) (*FileInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFileInfo not implemented")
+}
+func (*UnimplementedTrackerServer) Upload(context.Context, *UploadFileRequest) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Upload not implemented")
+}
+func (*UnimplementedTrackerServer) GetPeers(context.Context, *GetPeersRequest) (*ListPeers, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPeers not implemented")
+}
+func (*UnimplementedTrackerServer) PostPieceInfo(context.Context, *PieceInfo) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PostPieceInfo not implemented")
+}
+func (*UnimplementedTrackerServer) PostPieceReport(context.Context, *PieceReport) (*PieceReportResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PostPieceReport not implemented")
+}
+func (*UnimplementedTrackerServer) GetTrackerKey(context.Context, *empty.Empty) (*TrackerKey, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTrackerKey not implemented")
+}
+func (*UnimplementedTrackerServer) Scrape(context.Context, *ScrapeRequest) (*ScrapeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Scrape not implemented")
+}
+
+func RegisterTrackerServer(s *grpc.Server, srv TrackerServer) {
+	s.RegisterService(&_Tracker_serviceDesc, srv)
+}
+
+func _Tracker_GetAvailableFiles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrackerServer).GetAvailableFiles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Tracker/GetAvailableFiles",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrackerServer).GetAvailableFiles(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Tracker_GetFileInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DownloadFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrackerServer).GetFileInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Tracker/GetFileInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrackerServer).GetFileInfo(ctx, req.(*DownloadFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Tracker_Upload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UploadFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrackerServer).Upload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Tracker/Upload",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrackerServer).Upload(ctx, req.(*UploadFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Tracker_GetPeers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPeersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrackerServer).GetPeers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Tracker/GetPeers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrackerServer).GetPeers(ctx, req.(*GetPeersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Tracker_PostPieceInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PieceInfo)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrackerServer).PostPieceInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Tracker/PostPieceInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrackerServer).PostPieceInfo(ctx, req.(*PieceInfo))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Tracker_PostPieceReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PieceReport)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrackerServer).PostPieceReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Tracker/PostPieceReport",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrackerServer).PostPieceReport(ctx, req.(*PieceReport))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Tracker_GetTrackerKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrackerServer).GetTrackerKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Tracker/GetTrackerKey",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrackerServer).GetTrackerKey(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Tracker_Scrape_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScrapeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrackerServer).Scrape(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Tracker/Scrape",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrackerServer).Scrape(ctx, req.(*ScrapeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Tracker_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "api.Tracker",
+	HandlerType: (*TrackerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetAvailableFiles",
+			Handler:    _Tracker_GetAvailableFiles_Handler,
+		},
+		{
+			MethodName: "GetFileInfo",
+			Handler:    _Tracker_GetFileInfo_Handler,
+		},
+		{
+			MethodName: "Upload",
+			Handler:    _Tracker_Upload_Handler,
+		},
+		{
+			MethodName: "GetPeers",
+			Handler:    _Tracker_GetPeers_Handler,
+		},
+		{
+			MethodName: "PostPieceInfo",
+			Handler:    _Tracker_PostPieceInfo_Handler,
+		},
+		{
+			MethodName: "PostPieceReport",
+			Handler:    _Tracker_PostPieceReport_Handler,
+		},
+		{
+			MethodName: "GetTrackerKey",
+			Handler:    _Tracker_GetTrackerKey_Handler,
+		},
+		{
+			MethodName: "Scrape",
+			Handler:    _Tracker_Scrape_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "torrent.proto",
+}
+
+// PeerClient is the client API for Peer service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type PeerClient interface {
+	GetPiece(ctx context.Context, in *GetPieceRequest, opts ...grpc.CallOption) (*Piece, error)
+	GetIdentity(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*PeerIdentity, error)
+	ExchangePeers(ctx context.Context, in *PeerExchange, opts ...grpc.CallOption) (*PeerExchange, error)
+	GetFileInfo(ctx context.Context, in *DownloadFileRequest, opts ...grpc.CallOption) (*FileInfo, error)
+}
+
+type peerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPeerClient(cc grpc.ClientConnInterface) PeerClient {
+	return &peerClient{cc}
+}
+
+func (c *peerClient) GetPiece(ctx context.Context, in *GetPieceRequest, opts ...grpc.CallOption) (*Piece, error) {
+	out := new(Piece)
+	err := c.cc.Invoke(ctx, "/api.Peer/GetPiece", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *peerClient) GetIdentity(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*PeerIdentity, error) {
+	out := new(PeerIdentity)
+	err := c.cc.Invoke(ctx, "/api.Peer/GetIdentity", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *peerClient) ExchangePeers(ctx context.Context, in *PeerExchange, opts ...grpc.CallOption) (*PeerExchange, error) {
+	out := new(PeerExchange)
+	err := c.cc.Invoke(ctx, "/api.Peer/ExchangePeers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *peerClient) GetFileInfo(ctx context.Context, in *DownloadFileRequest, opts ...grpc.CallOption) (*FileInfo, error) {
+	out := new(FileInfo)
+	err := c.cc.Invoke(ctx, "/api.Peer/GetFileInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PeerServer is the server API for Peer service.
+type PeerServer interface {
+	GetPiece(context.Context, *GetPieceRequest) (*Piece, error)
+	GetIdentity(context.Context, *empty.Empty) (*PeerIdentity, error)
+	ExchangePeers(context.Context, *PeerExchange) (*PeerExchange, error)
+	GetFileInfo(context.Context, *DownloadFileRequest) (*FileInfo, error)
+}
+
+// UnimplementedPeerServer can be embedded to have forward compatible implementations.
+type UnimplementedPeerServer struct {
+}
+
+func (*UnimplementedPeerServer) GetPiece(context.Context, *GetPieceRequest) (*Piece, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPiece not implemented")
+}
+func (*UnimplementedPeerServer) GetIdentity(context.Context, *empty.Empty) (*PeerIdentity, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetIdentity not implemented")
+}
+func (*UnimplementedPeerServer) ExchangePeers(context.Context, *PeerExchange) (*PeerExchange, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExchangePeers not implemented")
+}
+func (*UnimplementedPeerServer) GetFileInfo(context.Context, *DownloadFileRequest) (*FileInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFileInfo not implemented")
+}
+
+func RegisterPeerServer(s *grpc.Server, srv PeerServer) {
+	s.RegisterService(&_Peer_serviceDesc, srv)
+}
+
+func _Peer_GetPiece_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPieceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PeerServer).GetPiece(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Peer/GetPiece",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PeerServer).GetPiece(ctx, req.(*GetPieceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Peer_GetIdentity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
 	}
-	type x struct{}
-	out := protoimpl.TypeBuilder{
-		File: protoimpl.DescBuilder{
-			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
-			RawDescriptor: file_torrent_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   12,
-			NumExtensions: 0,
-			NumServices:   2,
-		},
-		GoTypes:           file_torrent_proto_goTypes,
-		DependencyIndexes: file_torrent_proto_depIdxs,
-		MessageInfos:      file_torrent_proto_msgTypes,
-	}.Build()
-	File_torrent_proto = out.File
-	file_torrent_proto_rawDesc = nil
-	file_torrent_proto_goTypes = nil
-	file_torrent_proto_depIdxs = nil
+	if interceptor == nil {
+		return srv.(PeerServer).GetIdentity(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Peer/GetIdentity",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PeerServer).GetIdentity(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConnInterface
+func _Peer_ExchangePeers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PeerExchange)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PeerServer).ExchangePeers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Peer/ExchangePeers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PeerServer).ExchangePeers(ctx, req.(*PeerExchange))
+	}
+	return interceptor(ctx, in, info, handler)
+}
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion6
+func _Peer_GetFileInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DownloadFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PeerServer).GetFileInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Peer/GetFileInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PeerServer).GetFileInfo(ctx, req.(*DownloadFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
 
-// TrackerClient is the client API for Tracker service.
+var _Peer_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "api.Peer",
+	HandlerType: (*PeerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetPiece",
+			Handler:    _Peer_GetPiece_Handler,
+		},
+		{
+			MethodName: "GetIdentity",
+			Handler:    _Peer_GetIdentity_Handler,
+		},
+		{
+			MethodName: "ExchangePeers",
+			Handler:    _Peer_ExchangePeers_Handler,
+		},
+		{
+			MethodName: "GetFileInfo",
+			Handler:    _Peer_GetFileInfo_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "torrent.proto",
+}
+
+// DHTClient is the client API for DHT service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type TrackerClient interface {
-	GetAvailableFiles(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*ListFiles, error)
-	GetFileInfo(ctx context.Context, in *DownloadFileRequest, opts ...grpc.CallOption) (*FileInfo, error)
-	Upload(ctx context.Context, in *UploadFileRequest, opts ...grpc.CallOption) (*empty.Empty, error)
-	GetPeers(ctx context.Context, in *GetPeersRequest, opts ...grpc.CallOption) (*ListPeers, error)
-	PostPieceInfo(ctx context.Context, in *PieceInfo, opts ...grpc.CallOption) (*empty.Empty, error)
+type DHTClient interface {
+	Ping(ctx context.Context, in *DHTNode, opts ...grpc.CallOption) (*DHTNode, error)
+	FindNode(ctx context.Context, in *DHTFindNodeRequest, opts ...grpc.CallOption) (*DHTNodes, error)
+	GetPeers(ctx context.Context, in *DHTGetPeersRequest, opts ...grpc.CallOption) (*DHTGetPeersResponse, error)
+	AnnouncePeer(ctx context.Context, in *DHTAnnounceRequest, opts ...grpc.CallOption) (*empty.Empty, error)
 }
 
-type trackerClient struct {
+type dHTClient struct {
 	cc grpc.ClientConnInterface
 }
 
-func NewTrackerClient(cc grpc.ClientConnInterface) TrackerClient {
-	return &trackerClient{cc}
-}
-
-func (c *trackerClient) GetAvailableFiles(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*ListFiles, error) {
-	out := new(ListFiles)
-	err := c.cc.Invoke(ctx, "/api.Tracker/GetAvailableFiles", in, out, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+func NewDHTClient(cc grpc.ClientConnInterface) DHTClient {
+	return &dHTClient{cc}
 }
 
-func (c *trackerClient) GetFileInfo(ctx context.Context, in *DownloadFileRequest, opts ...grpc.CallOption) (*FileInfo, error) {
-	out := new(FileInfo)
-	err := c.cc.Invoke(ctx, "/api.Tracker/GetFileInfo", in, out, opts...)
+func (c *dHTClient) Ping(ctx context.Context, in *DHTNode, opts ...grpc.CallOption) (*DHTNode, error) {
+	out := new(DHTNode)
+	err := c.cc.Invoke(ctx, "/api.DHT/Ping", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *trackerClient) Upload(ctx context.Context, in *UploadFileRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
-	out := new(empty.Empty)
-	err := c.cc.Invoke(ctx, "/api.Tracker/Upload", in, out, opts...)
+func (c *dHTClient) FindNode(ctx context.Context, in *DHTFindNodeRequest, opts ...grpc.CallOption) (*DHTNodes, error) {
+	out := new(DHTNodes)
+	err := c.cc.Invoke(ctx, "/api.DHT/FindNode", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *trackerClient) GetPeers(ctx context.Context, in *GetPeersRequest, opts ...grpc.CallOption) (*ListPeers, error) {
-	out := new(ListPeers)
-	err := c.cc.Invoke(ctx, "/api.Tracker/GetPeers", in, out, opts...)
+func (c *dHTClient) GetPeers(ctx context.Context, in *DHTGetPeersRequest, opts ...grpc.CallOption) (*DHTGetPeersResponse, error) {
+	out := new(DHTGetPeersResponse)
+	err := c.cc.Invoke(ctx, "/api.DHT/GetPeers", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *trackerClient) PostPieceInfo(ctx context.Context, in *PieceInfo, opts ...grpc.CallOption) (*empty.Empty, error) {
+func (c *dHTClient) AnnouncePeer(ctx context.Context, in *DHTAnnounceRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
 	out := new(empty.Empty)
-	err := c.cc.Invoke(ctx, "/api.Tracker/PostPieceInfo", in, out, opts...)
+	err := c.cc.Invoke(ctx, "/api.DHT/AnnouncePeer", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-// TrackerServer is the server API for Tracker service.
-type TrackerServer interface {
-	GetAvailableFiles(context.Context, *empty.Empty) (*ListFiles, error)
-	GetFileInfo(context.Context, *DownloadFileRequest) (*FileInfo, error)
-	Upload(context.Context, *UploadFileRequest) (*empty.Empty, error)
-	GetPeers(context.Context, *GetPeersRequest) (*ListPeers, error)
-	PostPieceInfo(context.Context, *PieceInfo) (*empty.Empty, error)
+// DHTServer is the server API for DHT service.
+type DHTServer interface {
+	Ping(context.Context, *DHTNode) (*DHTNode, error)
+	FindNode(context.Context, *DHTFindNodeRequest) (*DHTNodes, error)
+	GetPeers(context.Context, *DHTGetPeersRequest) (*DHTGetPeersResponse, error)
+	AnnouncePeer(context.Context, *DHTAnnounceRequest) (*empty.Empty, error)
 }
 
-// UnimplementedTrackerServer can be embedded to have forward compatible implementations.
-type UnimplementedTrackerServer struct {
+// UnimplementedDHTServer can be embedded to have forward compatible implementations.
+type UnimplementedDHTServer struct {
 }
 
-func (*UnimplementedTrackerServer) GetAvailableFiles(context.Context, *empty.Empty) (*ListFiles, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method GetAvailableFiles not implemented")
-}
-func (*UnimplementedTrackerServer) GetFileInfo(context.Context, *DownloadFileRequest) (*FileInfo, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method GetFileInfo not implemented")
+func (*UnimplementedDHTServer) Ping(context.Context, *DHTNode) (*DHTNode, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Ping not implemented")
 }
-func (*UnimplementedTrackerServer) Upload(context.Context, *UploadFileRequest) (*empty.Empty, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Upload not implemented")
+func (*UnimplementedDHTServer) FindNode(context.Context, *DHTFindNodeRequest) (*DHTNodes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindNode not implemented")
 }
-func (*UnimplementedTrackerServer) GetPeers(context.Context, *GetPeersRequest) (*ListPeers, error) {
+func (*UnimplementedDHTServer) GetPeers(context.Context, *DHTGetPeersRequest) (*DHTGetPeersResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetPeers not implemented")
 }
-func (*UnimplementedTrackerServer) PostPieceInfo(context.Context, *PieceInfo) (*empty.Empty, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method PostPieceInfo not implemented")
-}
-
-func RegisterTrackerServer(s *grpc.Server, srv TrackerServer) {
-	s.RegisterService(&_Tracker_serviceDesc, srv)
+func (*UnimplementedDHTServer) AnnouncePeer(context.Context, *DHTAnnounceRequest) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AnnouncePeer not implemented")
 }
 
-func _Tracker_GetAvailableFiles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(empty.Empty)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(TrackerServer).GetAvailableFiles(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/api.Tracker/GetAvailableFiles",
-	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(TrackerServer).GetAvailableFiles(ctx, req.(*empty.Empty))
-	}
-	return interceptor(ctx, in, info, handler)
+func RegisterDHTServer(s *grpc.Server, srv DHTServer) {
+	s.RegisterService(&_DHT_serviceDesc, srv)
 }
 
-func _Tracker_GetFileInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(DownloadFileRequest)
+func _DHT_Ping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DHTNode)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(TrackerServer).GetFileInfo(ctx, in)
+		return srv.(DHTServer).Ping(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/api.Tracker/GetFileInfo",
+		FullMethod: "/api.DHT/Ping",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(TrackerServer).GetFileInfo(ctx, req.(*DownloadFileRequest))
+		return srv.(DHTServer).Ping(ctx, req.(*DHTNode))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Tracker_Upload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(UploadFileRequest)
+func _DHT_FindNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DHTFindNodeRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(TrackerServer).Upload(ctx, in)
+		return srv.(DHTServer).FindNode(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/api.Tracker/Upload",
+		FullMethod: "/api.DHT/FindNode",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(TrackerServer).Upload(ctx, req.(*UploadFileRequest))
+		return srv.(DHTServer).FindNode(ctx, req.(*DHTFindNodeRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Tracker_GetPeers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetPeersRequest)
+func _DHT_GetPeers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DHTGetPeersRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(TrackerServer).GetPeers(ctx, in)
+		return srv.(DHTServer).GetPeers(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/api.Tracker/GetPeers",
+		FullMethod: "/api.DHT/GetPeers",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(TrackerServer).GetPeers(ctx, req.(*GetPeersRequest))
+		return srv.(DHTServer).GetPeers(ctx, req.(*DHTGetPeersRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Tracker_PostPieceInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(PieceInfo)
+func _DHT_AnnouncePeer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DHTAnnounceRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(TrackerServer).PostPieceInfo(ctx, in)
+		return srv.(DHTServer).AnnouncePeer(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/api.Tracker/PostPieceInfo",
+		FullMethod: "/api.DHT/AnnouncePeer",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(TrackerServer).PostPieceInfo(ctx, req.(*PieceInfo))
+		return srv.(DHTServer).AnnouncePeer(ctx, req.(*DHTAnnounceRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-var _Tracker_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "api.Tracker",
-	HandlerType: (*TrackerServer)(nil),
+var _DHT_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "api.DHT",
+	HandlerType: (*DHTServer)(nil),
 	Methods: []grpc.MethodDesc{
 		{
-			MethodName: "GetAvailableFiles",
-			Handler:    _Tracker_GetAvailableFiles_Handler,
-		},
-		{
-			MethodName: "GetFileInfo",
-			Handler:    _Tracker_GetFileInfo_Handler,
+			MethodName: "Ping",
+			Handler:    _DHT_Ping_Handler,
 		},
 		{
-			MethodName: "Upload",
-			Handler:    _Tracker_Upload_Handler,
+			MethodName: "FindNode",
+			Handler:    _DHT_FindNode_Handler,
 		},
 		{
 			MethodName: "GetPeers",
-			Handler:    _Tracker_GetPeers_Handler,
+			Handler:    _DHT_GetPeers_Handler,
 		},
 		{
-			MethodName: "PostPieceInfo",
-			Handler:    _Tracker_PostPieceInfo_Handler,
+			MethodName: "AnnouncePeer",
+			Handler:    _DHT_AnnouncePeer_Handler,
 		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "torrent.proto",
 }
 
-// PeerClient is the client API for Peer service.
+// ControlClient is the client API for Control service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type PeerClient interface {
-	GetPiece(ctx context.Context, in *GetPieceRequest, opts ...grpc.CallOption) (*Piece, error)
-	UploadFile(ctx context.Context, in *File, opts ...grpc.CallOption) (*empty.Empty, error)
+type ControlClient interface {
+	UploadFile(ctx context.Context, in *File, opts ...grpc.CallOption) (*FileInfo, error)
 	GetFileInfo(ctx context.Context, in *File, opts ...grpc.CallOption) (*FileInfo, error)
 	Download(ctx context.Context, in *DownloadFileRequest, opts ...grpc.CallOption) (*DownloadFileResponse, error)
+	GetIdentity(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*PeerIdentity, error)
+	GetLocalPeers(ctx context.Context, in *LocalPeersRequest, opts ...grpc.CallOption) (*LocalPeers, error)
 }
 
-type peerClient struct {
+type controlClient struct {
 	cc grpc.ClientConnInterface
 }
 
-func NewPeerClient(cc grpc.ClientConnInterface) PeerClient {
-	return &peerClient{cc}
+func NewControlClient(cc grpc.ClientConnInterface) ControlClient {
+	return &controlClient{cc}
 }
 
-func (c *peerClient) GetPiece(ctx context.Context, in *GetPieceRequest, opts ...grpc.CallOption) (*Piece, error) {
-	out := new(Piece)
-	err := c.cc.Invoke(ctx, "/api.Peer/GetPiece", in, out, opts...)
+func (c *controlClient) UploadFile(ctx context.Context, in *File, opts ...grpc.CallOption) (*FileInfo, error) {
+	out := new(FileInfo)
+	err := c.cc.Invoke(ctx, "/api.Control/UploadFile", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *peerClient) UploadFile(ctx context.Context, in *File, opts ...grpc.CallOption) (*empty.Empty, error) {
-	out := new(empty.Empty)
-	err := c.cc.Invoke(ctx, "/api.Peer/UploadFile", in, out, opts...)
+func (c *controlClient) GetFileInfo(ctx context.Context, in *File, opts ...grpc.CallOption) (*FileInfo, error) {
+	out := new(FileInfo)
+	err := c.cc.Invoke(ctx, "/api.Control/GetFileInfo", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *peerClient) GetFileInfo(ctx context.Context, in *File, opts ...grpc.CallOption) (*FileInfo, error) {
-	out := new(FileInfo)
-	err := c.cc.Invoke(ctx, "/api.Peer/GetFileInfo", in, out, opts...)
+func (c *controlClient) Download(ctx context.Context, in *DownloadFileRequest, opts ...grpc.CallOption) (*DownloadFileResponse, error) {
+	out := new(DownloadFileResponse)
+	err := c.cc.Invoke(ctx, "/api.Control/Download", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *peerClient) Download(ctx context.Context, in *DownloadFileRequest, opts ...grpc.CallOption) (*DownloadFileResponse, error) {
-	out := new(DownloadFileResponse)
-	err := c.cc.Invoke(ctx, "/api.Peer/Download", in, out, opts...)
+func (c *controlClient) GetIdentity(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*PeerIdentity, error) {
+	out := new(PeerIdentity)
+	err := c.cc.Invoke(ctx, "/api.Control/GetIdentity", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-// PeerServer is the server API for Peer service.
-type PeerServer interface {
-	GetPiece(context.Context, *GetPieceRequest) (*Piece, error)
-	UploadFile(context.Context, *File) (*empty.Empty, error)
+func (c *controlClient) GetLocalPeers(ctx context.Context, in *LocalPeersRequest, opts ...grpc.CallOption) (*LocalPeers, error) {
+	out := new(LocalPeers)
+	err := c.cc.Invoke(ctx, "/api.Control/GetLocalPeers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ControlServer is the server API for Control service.
+type ControlServer interface {
+	UploadFile(context.Context, *File) (*FileInfo, error)
 	GetFileInfo(context.Context, *File) (*FileInfo, error)
 	Download(context.Context, *DownloadFileRequest) (*DownloadFileResponse, error)
+	GetIdentity(context.Context, *empty.Empty) (*PeerIdentity, error)
+	GetLocalPeers(context.Context, *LocalPeersRequest) (*LocalPeers, error)
 }
 
-// UnimplementedPeerServer can be embedded to have forward compatible implementations.
-type UnimplementedPeerServer struct {
+// UnimplementedControlServer can be embedded to have forward compatible implementations.
+type UnimplementedControlServer struct {
 }
 
-func (*UnimplementedPeerServer) GetPiece(context.Context, *GetPieceRequest) (*Piece, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method GetPiece not implemented")
-}
-func (*UnimplementedPeerServer) UploadFile(context.Context, *File) (*empty.Empty, error) {
+func (*UnimplementedControlServer) UploadFile(context.Context, *File) (*FileInfo, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UploadFile not implemented")
 }
-func (*UnimplementedPeerServer) GetFileInfo(context.Context, *File) (*FileInfo, error) {
+func (*UnimplementedControlServer) GetFileInfo(context.Context, *File) (*FileInfo, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetFileInfo not implemented")
 }
-func (*UnimplementedPeerServer) Download(context.Context, *DownloadFileRequest) (*DownloadFileResponse, error) {
+func (*UnimplementedControlServer) Download(context.Context, *DownloadFileRequest) (*DownloadFileResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Download not implemented")
 }
+func (*UnimplementedControlServer) GetIdentity(context.Context, *empty.Empty) (*PeerIdentity, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetIdentity not implemented")
+}
+func (*UnimplementedControlServer) GetLocalPeers(context.Context, *LocalPeersRequest) (*LocalPeers, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLocalPeers not implemented")
+}
 
-func RegisterPeerServer(s *grpc.Server, srv PeerServer) {
-	s.RegisterService(&_Peer_serviceDesc, srv)
+func RegisterControlServer(s *grpc.Server, srv ControlServer) {
+	s.RegisterService(&_Control_serviceDesc, srv)
 }
 
-func _Peer_GetPiece_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetPieceRequest)
+func _Control_UploadFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(File)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(PeerServer).GetPiece(ctx, in)
+		return srv.(ControlServer).UploadFile(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/api.Peer/GetPiece",
+		FullMethod: "/api.Control/UploadFile",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(PeerServer).GetPiece(ctx, req.(*GetPieceRequest))
+		return srv.(ControlServer).UploadFile(ctx, req.(*File))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Peer_UploadFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+func _Control_GetFileInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(File)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(PeerServer).UploadFile(ctx, in)
+		return srv.(ControlServer).GetFileInfo(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/api.Peer/UploadFile",
+		FullMethod: "/api.Control/GetFileInfo",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(PeerServer).UploadFile(ctx, req.(*File))
+		return srv.(ControlServer).GetFileInfo(ctx, req.(*File))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Peer_GetFileInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(File)
+func _Control_Download_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DownloadFileRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(PeerServer).GetFileInfo(ctx, in)
+		return srv.(ControlServer).Download(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/api.Peer/GetFileInfo",
+		FullMethod: "/api.Control/Download",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(PeerServer).GetFileInfo(ctx, req.(*File))
+		return srv.(ControlServer).Download(ctx, req.(*DownloadFileRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Peer_Download_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(DownloadFileRequest)
+func _Control_GetIdentity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(PeerServer).Download(ctx, in)
+		return srv.(ControlServer).GetIdentity(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/api.Peer/Download",
+		FullMethod: "/api.Control/GetIdentity",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(PeerServer).Download(ctx, req.(*DownloadFileRequest))
+		return srv.(ControlServer).GetIdentity(ctx, req.(*empty.Empty))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-var _Peer_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "api.Peer",
-	HandlerType: (*PeerServer)(nil),
+func _Control_GetLocalPeers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LocalPeersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServer).GetLocalPeers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Control/GetLocalPeers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServer).GetLocalPeers(ctx, req.(*LocalPeersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Control_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "api.Control",
+	HandlerType: (*ControlServer)(nil),
 	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "GetPiece",
-			Handler:    _Peer_GetPiece_Handler,
-		},
 		{
 			MethodName: "UploadFile",
-			Handler:    _Peer_UploadFile_Handler,
+			Handler:    _Control_UploadFile_Handler,
 		},
 		{
 			MethodName: "GetFileInfo",
-			Handler:    _Peer_GetFileInfo_Handler,
+			Handler:    _Control_GetFileInfo_Handler,
 		},
 		{
 			MethodName: "Download",
-			Handler:    _Peer_Download_Handler,
+			Handler:    _Control_Download_Handler,
+		},
+		{
+			MethodName: "GetIdentity",
+			Handler:    _Control_GetIdentity_Handler,
+		},
+		{
+			MethodName: "GetLocalPeers",
+			Handler:    _Control_GetLocalPeers_Handler,
 		},
 	},
 	Streams:  []grpc.StreamDesc{},
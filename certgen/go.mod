@@ -0,0 +1,3 @@
+module github.com/elizarpif/grpctorrent/certgen
+
+go 1.14
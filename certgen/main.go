@@ -0,0 +1,157 @@
+// certgen создает локальный удостоверяющий центр и сертификаты для разработки:
+// ими трекер, пиры и их http-шлюзы включают TLS и взаимный TLS.
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"flag"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+func main() {
+	out := flag.String("out", "certs", "output directory")
+	names := flag.String("names", "tracker,peer", "comma separated node names, one certificate per name")
+	hosts := flag.String("hosts", "localhost,127.0.0.1,::1", "comma separated DNS names and IPs put into every node certificate")
+	validFor := flag.Duration("valid-for", 365*24*time.Hour, "certificate lifetime")
+	flag.Parse()
+
+	err := os.MkdirAll(*out, 0o700)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ca, caKey, err := newCA(*validFor)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = writePair(*out, "ca", ca, caKey)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, name := range strings.Split(*names, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		cert, key, err := newNodeCert(name, strings.Split(*hosts, ","), ca, caKey, *validFor)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		err = writePair(*out, name, cert, key)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		log.Printf("%s: %s, %s", name, filepath.Join(*out, name+".pem"), filepath.Join(*out, name+"-key.pem"))
+	}
+
+	log.Printf("ca: %s", filepath.Join(*out, "ca.pem"))
+}
+
+func serial() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
+
+func newCA(validFor time.Duration) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sn, err := serial()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          sn,
+		Subject:               pkix.Name{CommonName: "grpctorrent dev CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(validFor),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	return cert, key, err
+}
+
+// сертификат узла годится и для сервера, и для клиента при взаимном TLS
+func newNodeCert(name string, hosts []string, ca *x509.Certificate, caKey *ecdsa.PrivateKey,
+	validFor time.Duration) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sn, err := serial()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: sn,
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(validFor),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	for _, h := range hosts {
+		h = strings.TrimSpace(h)
+		if ip := net.ParseIP(h); ip != nil {
+			tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+		} else if h != "" {
+			tmpl.DNSNames = append(tmpl.DNSNames, h)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca, &key.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	return cert, key, err
+}
+
+func writePair(dir, name string, cert *x509.Certificate, key *ecdsa.PrivateKey) error {
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+
+	err := ioutil.WriteFile(filepath.Join(dir, name+".pem"), certPEM, 0o644) //nolint:gosec // certificate is public
+	if err != nil {
+		return err
+	}
+
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return err
+	}
+
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+
+	return ioutil.WriteFile(filepath.Join(dir, name+"-key.pem"), keyPEM, 0o600)
+}
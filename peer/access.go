@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/elizarpif/grpctorrent/api"
+	"github.com/elizarpif/grpctorrent/api/auth"
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	getPieceMethod = "/api.Peer/GetPiece"
+
+	// ключ трекера перезапрашивается не чаще, чем раз в столько
+	keyRefreshInterval = time.Minute
+)
+
+// trackerKey - закэшированный ключ, которым трекер подписывает токены доступа
+type trackerKey struct {
+	key     ed25519.PublicKey
+	fetched time.Time // когда ключ последний раз запрашивали у трекера
+
+	mutex *sync.Mutex
+}
+
+func newTrackerKey() *trackerKey {
+	return &trackerKey{mutex: &sync.Mutex{}}
+}
+
+// trackerPublicKey возвращает ключ трекера, при refresh заново запрашивает его.
+// Запрос к трекеру идет без блокировки и не чаще keyRefreshInterval,
+// так что чужие запросы не заставят пира постоянно ходить к трекеру.
+func (p *Peer) trackerPublicKey(ctx context.Context, refresh bool) (ed25519.PublicKey, error) {
+	k := p.trackerKey
+
+	k.mutex.Lock()
+	key := k.key
+	if (key != nil && !refresh) || time.Since(k.fetched) < keyRefreshInterval {
+		k.mutex.Unlock()
+
+		if key == nil {
+			return nil, errors.New("tracker key is unavailable")
+		}
+		return key, nil
+	}
+	k.fetched = time.Now()
+	k.mutex.Unlock()
+
+	resp, err := p.tracker.GetTrackerKey(ctx, &empty.Empty{})
+	if err != nil {
+		return nil, err
+	}
+
+	k.mutex.Lock()
+	k.key = resp.PublicKey
+	k.mutex.Unlock()
+
+	return resp.PublicKey, nil
+}
+
+// nonces - nonce подписанных запросов к пиру, каждый принимается один раз
+type nonces struct {
+	seen      map[string]time.Time
+	lastSweep time.Time
+
+	mutex *sync.Mutex
+}
+
+func newNonces() *nonces {
+	return &nonces{seen: make(map[string]time.Time), mutex: &sync.Mutex{}}
+}
+
+// use запоминает nonce и возвращает false, если он уже был
+func (n *nonces) use(nonce string, ts, now time.Time) bool {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	// nonce старше допустимого расхождения времени не пройдет проверку подписи
+	if now.Sub(n.lastSweep) >= auth.MaxClockSkew {
+		for v, at := range n.seen {
+			if now.Sub(at) > auth.MaxClockSkew {
+				delete(n.seen, v)
+			}
+		}
+		n.lastSweep = now
+	}
+
+	if _, ok := n.seen[nonce]; ok {
+		return false
+	}
+
+	n.seen[nonce] = ts
+	return true
+}
+
+// checkAccess проверяет запрос куска приватного файла: запрос подписан ключом пира,
+// которому трекер выдал токен. Подпись проверяется раньше токена, поэтому
+// чужой запрос без подписи не доходит до трекера. Ключ трекера перезапрашивается,
+// только если токен правильного вида не сошелся с подписью: трекер мог перезапуститься с новым ключом.
+func (p *Peer) checkAccess(ctx context.Context, req *api.GetPieceRequest) error {
+	now := time.Now()
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	// запрос, подписанный для другого раздающего, тот не перешлет сюда
+	signed, err := auth.Verify(md, []string{p.addr}, getPieceMethod, req, now)
+	if err != nil {
+		return errors.New("request is not signed: " + err.Error())
+	}
+
+	if !p.nonces.use(signed.Nonce, signed.Timestamp, now) {
+		return errors.New("replayed nonce")
+	}
+
+	key, err := p.trackerPublicKey(ctx, false)
+	if err != nil {
+		return err
+	}
+
+	token, err := auth.VerifyToken(key, req.AccessToken, req.Hash, now)
+	if errors.Is(err, auth.ErrTokenSignature) {
+		key, refreshErr := p.trackerPublicKey(ctx, true)
+		if refreshErr != nil {
+			return err
+		}
+
+		token, err = auth.VerifyToken(key, req.AccessToken, req.Hash, now)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !token.IssuedTo(signed.PeerID, signed.PublicKey) {
+		return errors.New("access token is issued to another peer")
+	}
+
+	return nil
+}
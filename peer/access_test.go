@@ -0,0 +1,264 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elizarpif/grpctorrent/api"
+	"github.com/elizarpif/grpctorrent/api/auth"
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	seederAddr     = "localhost:9002"
+	downloaderAddr = "localhost:9003"
+
+	privateHash = "11111111111111111111111111111111"
+	publicHash  = "22222222222222222222222222222222"
+)
+
+// keyTracker - трекер, который отдает только ключ токенов и считает запросы ключа
+type keyTracker struct {
+	api.TrackerClient
+
+	key   ed25519.PublicKey
+	err   error
+	calls int
+}
+
+func (t *keyTracker) GetTrackerKey(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*api.TrackerKey, error) {
+	t.calls++
+	if t.err != nil {
+		return nil, t.err
+	}
+
+	return &api.TrackerKey{PublicKey: t.key}, nil
+}
+
+func newTestKey(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+	t.Helper()
+
+	pub, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pub, key
+}
+
+// newSeeder - раздающий пир с куском 0 приватного и публичного файлов
+func newSeeder(tracker *keyTracker) *Peer {
+	p := &Peer{
+		addr:       seederAddr,
+		files:      newRegistry(),
+		tracker:    tracker,
+		trackerKey: newTrackerKey(),
+		nonces:     newNonces(),
+	}
+
+	for hash, private := range map[string]bool{privateHash: true, publicHash: false} {
+		f := newEmptyFile(&api.FileInfo{Name: hash, Hash: hash, Length: 1, PieceLength: 1, Pieces: 1, Private: private})
+		f.setPiece(&api.Piece{SerialNumber: 0, Payload: []byte{1}})
+		p.files.add(f)
+	}
+
+	return p
+}
+
+// getPiece запрашивает кусок 0, подписав запрос ключом key для адреса target; key nil - без подписи
+func getPiece(t *testing.T, p *Peer, key ed25519.PrivateKey, target, hash, token string) error {
+	t.Helper()
+
+	req := &api.GetPieceRequest{Hash: hash, SerialNumber: 0, AccessToken: token}
+
+	ctx := context.Background()
+	if key != nil {
+		md, err := auth.Sign(key, downloaderAddr, target, getPieceMethod, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx = metadata.NewIncomingContext(ctx, md)
+	}
+
+	_, err := p.GetPiece(ctx, req)
+	return err
+}
+
+func issueToken(trackerKey ed25519.PrivateKey, hash string, peer ed25519.PublicKey, expires time.Time) string {
+	return auth.IssueToken(trackerKey, &auth.AccessToken{
+		Hash:      hash,
+		PeerID:    auth.PeerID(peer),
+		PublicKey: peer,
+		Expires:   expires,
+	})
+}
+
+func TestCheckAccess(t *testing.T) {
+	trackerPub, trackerKey := newTestKey(t)
+	_, otherTracker := newTestKey(t)
+	downloaderPub, downloaderKey := newTestKey(t)
+	otherPub, otherKey := newTestKey(t)
+
+	hour := time.Now().Add(time.Hour)
+	valid := issueToken(trackerKey, privateHash, downloaderPub, hour)
+
+	tests := []struct {
+		name   string
+		key    ed25519.PrivateKey // чем подписан запрос
+		target string
+		hash   string
+		token  string
+		ok     bool
+	}{
+		{name: "valid token", key: downloaderKey, token: valid, ok: true},
+		{name: "expired token", key: downloaderKey, token: issueToken(trackerKey, privateHash, downloaderPub, time.Now().Add(-time.Minute))},
+		{name: "token for another file", key: downloaderKey, token: issueToken(trackerKey, publicHash, downloaderPub, hour)},
+		{name: "token of another peer", key: downloaderKey, token: issueToken(trackerKey, privateHash, otherPub, hour)},
+		{name: "token used by another peer", key: otherKey, token: valid},
+		{
+			name: "token with another key",
+			key:  downloaderKey,
+			token: auth.IssueToken(trackerKey, &auth.AccessToken{
+				Hash: privateHash, PeerID: auth.PeerID(downloaderPub), PublicKey: otherPub, Expires: hour,
+			}),
+		},
+		{name: "bad tracker signature", key: downloaderKey, token: issueToken(otherTracker, privateHash, downloaderPub, hour)},
+		{name: "malformed token", key: downloaderKey, token: "abc"},
+		{name: "no token", key: downloaderKey},
+		{name: "unsigned request", token: valid},
+		{name: "signed for another seeder", key: downloaderKey, target: "localhost:9004", token: valid},
+
+		// публичный файл отдается без подписи и токена
+		{name: "public file", hash: publicHash, ok: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := &keyTracker{key: trackerPub}
+			p := newSeeder(tracker)
+
+			target, hash := tt.target, tt.hash
+			if target == "" {
+				target = seederAddr
+			}
+			if hash == "" {
+				hash = privateHash
+			}
+
+			err := getPiece(t, p, tt.key, target, hash, tt.token)
+			if (err == nil) != tt.ok {
+				t.Fatalf("GetPiece error = %v, want ok %v", err, tt.ok)
+			}
+			if err != nil && status.Code(err) != codes.PermissionDenied {
+				t.Errorf("error code = %v, want PermissionDenied", status.Code(err))
+			}
+
+			// чужие запросы без подписи и к публичному файлу не доходят до трекера
+			if (tt.key == nil || tt.target != "") && tracker.calls != 0 {
+				t.Errorf("tracker key requested %d times", tracker.calls)
+			}
+		})
+	}
+}
+
+func TestCheckAccessReplay(t *testing.T) {
+	trackerPub, trackerKey := newTestKey(t)
+	downloaderPub, downloaderKey := newTestKey(t)
+
+	p := newSeeder(&keyTracker{key: trackerPub})
+
+	req := &api.GetPieceRequest{Hash: privateHash, AccessToken: issueToken(trackerKey, privateHash, downloaderPub, time.Now().Add(time.Hour))}
+	md, err := auth.Sign(downloaderKey, downloaderAddr, seederAddr, getPieceMethod, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+
+	if _, err := p.GetPiece(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.GetPiece(ctx, req); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("replayed request: %v, want PermissionDenied", err)
+	}
+}
+
+func TestTrackerKeyRefresh(t *testing.T) {
+	oldPub, oldKey := newTestKey(t)
+	newPub, newKey := newTestKey(t)
+	downloaderPub, downloaderKey := newTestKey(t)
+
+	tracker := &keyTracker{key: oldPub}
+	p := newSeeder(tracker)
+
+	hour := time.Now().Add(time.Hour)
+	request := func(token string) error {
+		return getPiece(t, p, downloaderKey, seederAddr, privateHash, token)
+	}
+
+	if err := request(issueToken(oldKey, privateHash, downloaderPub, hour)); err != nil || tracker.calls != 1 {
+		t.Fatalf("first request: %v, %d key requests", err, tracker.calls)
+	}
+
+	// трекер перезапустился с новым ключом, но с прошлого запроса ключа не прошло и минуты
+	tracker.key = newPub
+	signedByNew := issueToken(newKey, privateHash, downloaderPub, hour)
+
+	for i := 0; i < 3; i++ {
+		if err := request(signedByNew); err == nil {
+			t.Fatal("token signed by the new key accepted with the cached old key")
+		}
+	}
+	if tracker.calls != 1 {
+		t.Errorf("key requested %d times within a minute, want once", tracker.calls)
+	}
+
+	// через минуту ключ перезапрашивается один раз, и токен проходит
+	p.trackerKey.fetched = time.Now().Add(-keyRefreshInterval)
+
+	if err := request(signedByNew); err != nil {
+		t.Fatalf("after refresh: %v", err)
+	}
+	if err := request(signedByNew); err != nil || tracker.calls != 2 {
+		t.Errorf("with the new key: %v, %d key requests, want 2", err, tracker.calls)
+	}
+
+	// токен неправильного вида ключ не обновляет даже после паузы
+	p.trackerKey.fetched = time.Now().Add(-keyRefreshInterval)
+
+	for _, token := range []string{"abc", "a.b", strings.Repeat("x", 100)} {
+		if err := request(token); err == nil {
+			t.Errorf("malformed token %q accepted", token)
+		}
+	}
+	if tracker.calls != 2 {
+		t.Errorf("malformed tokens made %d key requests", tracker.calls-2)
+	}
+}
+
+func TestTrackerKeyUnavailable(t *testing.T) {
+	trackerPub, trackerKey := newTestKey(t)
+	downloaderPub, downloaderKey := newTestKey(t)
+
+	tracker := &keyTracker{key: trackerPub, err: errors.New("tracker is down")}
+	p := newSeeder(tracker)
+
+	token := issueToken(trackerKey, privateHash, downloaderPub, time.Now().Add(time.Hour))
+
+	// без ключа приватный файл не отдается, и повторные запросы не идут к трекеру
+	for i := 0; i < 3; i++ {
+		if err := getPiece(t, p, downloaderKey, seederAddr, privateHash, token); status.Code(err) != codes.PermissionDenied {
+			t.Errorf("request %d: %v, want PermissionDenied", i, err)
+		}
+	}
+	if tracker.calls != 1 {
+		t.Errorf("key requested %d times, want once", tracker.calls)
+	}
+}
@@ -1,21 +1,43 @@
 package main
 
-import "context"
+import (
+	"context"
+	"errors"
 
-type auth struct {
-	data map[string]string
-}
+	"github.com/elizarpif/grpctorrent/api/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
 
-func newAuth(address string) *auth {
-	a := make(map[string]string)
-	a["address"] = address
-	return &auth{data: a}
+// signer подписывает каждый запрос к трекеру ключом личности пира,
+// так что никто другой не может выступить от имени его peer_id.
+// В подпись входит адрес, по которому пир соединился, поэтому трекер из ссылки
+// или метаинфо не перешлет запрос своему трекеру пира.
+type signer struct {
+	ident   *identity
+	address string
 }
 
-func (a *auth) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
-	return a.data, nil
+func newSigner(ident *identity, address string) *signer {
+	return &signer{ident: ident, address: address}
 }
 
-func (a *auth) RequireTransportSecurity() bool {
-	return false
+func (a *signer) unaryInterceptor(ctx context.Context, method string, req, reply interface{},
+	cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	msg, ok := req.(proto.Message)
+	if !ok {
+		return errors.New("cannot sign non-proto request")
+	}
+
+	md, err := auth.Sign(a.ident.privateKey, a.address, cc.Target(), method, msg)
+	if err != nil {
+		return err
+	}
+
+	if outgoing, ok := metadata.FromOutgoingContext(ctx); ok {
+		md = metadata.Join(outgoing, md)
+	}
+
+	return invoker(metadata.NewOutgoingContext(ctx, md), method, req, reply, cc, opts...)
 }
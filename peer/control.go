@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	controlSocket    = "control.sock"
+	controlTokenFile = "control.token"
+)
+
+// listenControl открывает адрес API управления: по умолчанию unix-сокет в каталоге состояния,
+// "unix:<путь>" - заданный сокет, иначе host:port, который обязан быть loopback.
+// Возвращает еще и адрес, по которому к API ходит http-шлюз.
+func listenControl(addr, dir string) (net.Listener, string, error) {
+	if addr == "" {
+		addr = "unix:" + filepath.Join(dir, controlSocket)
+	}
+
+	if strings.HasPrefix(addr, "unix:") {
+		path := strings.TrimPrefix(addr, "unix:")
+
+		// сокет мог остаться от прошлого запуска
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return nil, "", err
+		}
+
+		lis, err := net.Listen("unix", path)
+		if err != nil {
+			return nil, "", err
+		}
+
+		return lis, "unix:" + path, os.Chmod(path, 0o600)
+	}
+
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, "", err
+	}
+
+	ip := net.ParseIP(host)
+	if host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return nil, "", errors.New("control address must be a unix socket or a loopback address")
+	}
+
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return lis, addr, nil
+}
+
+// loadControlToken возвращает токен API управления: заданный флагом
+// или сохраненный в каталоге состояния, при первом запуске создает его
+func loadControlToken(token, dir string) (string, error) {
+	if token != "" {
+		return token, nil
+	}
+
+	name := filepath.Join(dir, controlTokenFile)
+
+	data, err := ioutil.ReadFile(name)
+	if err == nil {
+		return strings.TrimSpace(string(data)), nil
+	}
+	if !os.IsNotExist(err) {
+		return "", err
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+
+	token = hex.EncodeToString(raw)
+
+	return token, ioutil.WriteFile(name, []byte(token+"\n"), 0o600)
+}
+
+// controlAuth пускает к API управления только с заголовком authorization: Bearer <token>
+func controlAuth(token string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		for _, v := range md.Get("authorization") {
+			if subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(v, "Bearer ")), []byte(token)) == 1 {
+				return handler(ctx, req)
+			}
+		}
+
+		return nil, status.Error(codes.Unauthenticated, "invalid control token")
+	}
+}
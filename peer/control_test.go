@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/elizarpif/grpctorrent/api"
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const testControlToken = "secret"
+
+// serveGRPC запускает grpc-сервер на свободном петлевом порту и возвращает соединение с ним
+func serveGRPC(t *testing.T, server *grpc.Server) *grpc.ClientConn {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+func newControlPeer(t *testing.T) *Peer {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ident := newIdentity(key)
+
+	return &Peer{id: ident.id, ident: ident, addr: seederAddr, files: newRegistry()}
+}
+
+func TestControlAuth(t *testing.T) {
+	server := grpc.NewServer(grpc.UnaryInterceptor(controlAuth(testControlToken)))
+	api.RegisterControlServer(server, newControlPeer(t))
+
+	client := api.NewControlClient(serveGRPC(t, server))
+
+	tests := []struct {
+		name   string
+		header []string // значения authorization
+		code   codes.Code
+	}{
+		{name: "valid token", header: []string{"Bearer " + testControlToken}, code: codes.OK},
+		{name: "one of several values", header: []string{"Bearer guess", "Bearer " + testControlToken}, code: codes.OK},
+		{name: "no token", code: codes.Unauthenticated},
+		{name: "wrong token", header: []string{"Bearer guess"}, code: codes.Unauthenticated},
+		{name: "token prefix", header: []string{"Bearer secre"}, code: codes.Unauthenticated},
+		{name: "longer token", header: []string{"Bearer secrets"}, code: codes.Unauthenticated},
+		{name: "empty bearer", header: []string{"Bearer "}, code: codes.Unauthenticated},
+		{name: "other scheme", header: []string{"Basic " + testControlToken}, code: codes.Unauthenticated},
+	}
+
+	for _, tt := range tests {
+		ctx := context.Background()
+		if tt.header != nil {
+			ctx = metadata.NewOutgoingContext(ctx, metadata.MD{"authorization": tt.header})
+		}
+
+		if _, err := client.GetIdentity(ctx, &empty.Empty{}); status.Code(err) != tt.code {
+			t.Errorf("%s: GetIdentity error = %v, want %v", tt.name, err, tt.code)
+		}
+
+		// и метод, меняющий состояние пира, не вызывается без токена
+		if tt.code == codes.OK {
+			continue
+		}
+		if _, err := client.Download(ctx, &api.DownloadFileRequest{}); status.Code(err) != codes.Unauthenticated {
+			t.Errorf("%s: Download error = %v, want Unauthenticated", tt.name, err)
+		}
+	}
+}
+
+func TestPublicPeerHidesControl(t *testing.T) {
+	// grpc-порт пира, как его собирает main
+	server := grpc.NewServer()
+	api.RegisterPeerServer(server, &publicPeer{newControlPeer(t)})
+
+	services := server.GetServiceInfo()
+	if _, ok := services["api.Control"]; ok {
+		t.Fatal("control service is registered on the public port")
+	}
+
+	control := map[string]bool{}
+	for _, m := range []string{"UploadFile", "Download", "GetLocalPeers"} {
+		control[m] = true
+	}
+	for _, m := range services["api.Peer"].Methods {
+		if control[m.Name] {
+			t.Errorf("public Peer service exposes %s", m.Name)
+		}
+	}
+
+	// вызов методов управления на публичном порту не доходит до пира даже с токеном
+	client := api.NewControlClient(serveGRPC(t, server))
+	ctx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+testControlToken))
+
+	if _, err := client.UploadFile(ctx, &api.File{Name: "some.txt"}); status.Code(err) != codes.Unimplemented {
+		t.Errorf("UploadFile on the public port: %v, want Unimplemented", err)
+	}
+	if _, err := client.GetLocalPeers(ctx, &api.LocalPeersRequest{}); status.Code(err) != codes.Unimplemented {
+		t.Errorf("GetLocalPeers on the public port: %v, want Unimplemented", err)
+	}
+}
+
+func TestListenControl(t *testing.T) {
+	dir, err := ioutil.TempDir("", "control")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, addr := range []string{"0.0.0.0:0", "192.168.1.5:0", ":0", "peer.lan:0"} {
+		if lis, _, err := listenControl(addr, dir); err == nil {
+			lis.Close()
+			t.Errorf("control api listens on %s", addr)
+		}
+	}
+
+	// по умолчанию - сокет в каталоге состояния, доступный только владельцу
+	lis, target, err := listenControl("", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+
+	path := filepath.Join(dir, controlSocket)
+	if target != "unix:"+path {
+		t.Errorf("target = %q", target)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("socket: %v, %v", info, err)
+	}
+}
+
+func TestLoadControlToken(t *testing.T) {
+	dir, err := ioutil.TempDir("", "control")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if token, err := loadControlToken(testControlToken, dir); err != nil || token != testControlToken {
+		t.Errorf("token from the flag: %q, %v", token, err)
+	}
+
+	token, err := loadControlToken("", dir)
+	if err != nil || len(token) != 64 {
+		t.Fatalf("created token %q, %v", token, err)
+	}
+
+	if info, err := os.Stat(filepath.Join(dir, controlTokenFile)); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("token file: %v, %v", info, err)
+	}
+
+	if again, err := loadControlToken("", dir); err != nil || again != token {
+		t.Errorf("reloaded token %q, %v, want %q", again, err, token)
+	}
+}
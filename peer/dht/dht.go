@@ -0,0 +1,472 @@
+// Package dht - Kademlia DHT поверх grpc: пиры находят пиров файла, когда трекер недоступен.
+// Каждый пир - узел DHT, сервис api.DHT работает на его публичном grpc-сервере.
+package dht
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/elizarpif/grpctorrent/api"
+	"github.com/elizarpif/logger"
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	alpha        = 3  // сколько узлов поиск опрашивает одновременно
+	maxQueries   = 64 // больше запросов один поиск не делает
+	queryTimeout = 5 * time.Second
+
+	// как часто обновлять таблицу и повторять объявления своих файлов
+	refreshInterval = 15 * time.Minute
+)
+
+type DHT struct {
+	id        ID
+	addr      string // grpc-адрес пира, порт из него видят другие узлы
+	transport grpc.DialOption
+
+	table *table
+	store *store
+
+	provided map[string]bool // файлы, которые пир раздает через DHT
+	pending  map[string]bool // из них еще не объявленные
+	wake     chan struct{}
+
+	mutex *sync.Mutex // защищает provided и pending
+}
+
+func New(id ID, addr string, transport grpc.DialOption) (*DHT, error) {
+	st, err := newStore()
+	if err != nil {
+		return nil, err
+	}
+
+	return &DHT{
+		id:        id,
+		addr:      addr,
+		transport: transport,
+		table:     newTable(id),
+		store:     st,
+		provided:  make(map[string]bool),
+		pending:   make(map[string]bool),
+		wake:      make(chan struct{}, 1),
+		mutex:     &sync.Mutex{},
+	}, nil
+}
+
+func (d *DHT) sender() *api.DHTNode {
+	return &api.DHTNode{Id: d.id[:], Address: d.addr}
+}
+
+func (d *DHT) Ping(ctx context.Context, n *api.DHTNode) (*api.DHTNode, error) {
+	d.observe(ctx, n)
+
+	return d.sender(), nil
+}
+
+func (d *DHT) FindNode(ctx context.Context, req *api.DHTFindNodeRequest) (*api.DHTNodes, error) {
+	target, ok := idFromBytes(req.Target)
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "invalid target id")
+	}
+
+	d.observe(ctx, req.Sender)
+
+	return &api.DHTNodes{Sender: d.sender(), Nodes: toAPI(d.table.closest(target, bucketSize))}, nil
+}
+
+func (d *DHT) GetPeers(ctx context.Context, req *api.DHTGetPeersRequest) (*api.DHTGetPeersResponse, error) {
+	if req.Hash == "" {
+		return nil, status.Error(codes.InvalidArgument, "empty hash")
+	}
+
+	ip, ok := remoteIP(ctx)
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "unknown remote address")
+	}
+
+	d.observe(ctx, req.Sender)
+
+	resp := &api.DHTGetPeersResponse{Sender: d.sender(), Token: d.store.token(ip, time.Now())}
+
+	for _, a := range d.store.get(req.Hash) {
+		id := a.id
+		resp.Peers = append(resp.Peers, &api.DHTNode{Id: id[:], Address: a.addr})
+	}
+
+	if len(resp.Peers) < bucketSize {
+		resp.Nodes = toAPI(d.table.closest(Key(req.Hash), bucketSize))
+	}
+
+	return resp, nil
+}
+
+func (d *DHT) AnnouncePeer(ctx context.Context, req *api.DHTAnnounceRequest) (*empty.Empty, error) {
+	id, addr, ok := senderAddr(ctx, req.Sender)
+	if !ok || req.Hash == "" {
+		return nil, status.Error(codes.InvalidArgument, "invalid announce")
+	}
+
+	ip, _ := remoteIP(ctx)
+	if !d.store.validToken(req.Token, ip) {
+		return nil, status.Error(codes.PermissionDenied, "invalid token")
+	}
+
+	d.store.add(req.Hash, id, addr)
+	d.table.update(id, addr)
+
+	return &empty.Empty{}, nil
+}
+
+// observe заносит в таблицу узел, приславший запрос
+func (d *DHT) observe(ctx context.Context, n *api.DHTNode) {
+	if id, addr, ok := senderAddr(ctx, n); ok {
+		d.table.update(id, addr)
+	}
+}
+
+// Provide объявляет, что пир раздает файл: объявление уходит сразу
+// и повторяется, пока пир работает
+func (d *DHT) Provide(hash string) {
+	d.mutex.Lock()
+	d.provided[hash] = true
+	d.pending[hash] = true
+	d.mutex.Unlock()
+
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run входит в сеть через bootstrap-адреса и поддерживает ее: обновляет таблицу,
+// объявляет раздаваемые файлы и забывает устаревшие чужие объявления, пока не отменят ctx
+func (d *DHT) Run(ctx context.Context, bootstrap []string) error {
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+
+	d.bootstrap(ctx, bootstrap)
+
+	for {
+		for _, hash := range d.takePending() {
+			d.announce(ctx, hash)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-d.wake:
+		case <-ticker.C:
+			d.store.expire()
+			d.bootstrap(ctx, bootstrap)
+			d.repeatProvided()
+		}
+	}
+}
+
+func (d *DHT) takePending() []string {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	hashes := make([]string, 0, len(d.pending))
+	for hash := range d.pending {
+		hashes = append(hashes, hash)
+	}
+	d.pending = make(map[string]bool)
+
+	return hashes
+}
+
+func (d *DHT) repeatProvided() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	for hash := range d.provided {
+		d.pending[hash] = true
+	}
+}
+
+// bootstrap пингует известные адреса и ищет узлы рядом с собой
+func (d *DHT) bootstrap(ctx context.Context, addrs []string) {
+	for _, addr := range addrs {
+		_, err := d.query(ctx, node{addr: addr}, func(ctx context.Context, client api.DHTClient) (*api.DHTNode, []*api.DHTNode, error) {
+			sender, err := client.Ping(ctx, d.sender())
+			return sender, nil, err
+		})
+		if err != nil {
+			logger.GetLogger(ctx).WithError(err).WithField("node", addr).Error("cannot reach dht bootstrap node")
+		}
+	}
+
+	d.lookup(ctx, d.id, func(ctx context.Context, client api.DHTClient) (*api.DHTNode, []*api.DHTNode, error) {
+		resp, err := client.FindNode(ctx, &api.DHTFindNodeRequest{Sender: d.sender(), Target: d.id[:]})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return resp.Sender, resp.Nodes, nil
+	})
+}
+
+// FindPeers ищет в сети адреса пиров, объявивших файл
+func (d *DHT) FindPeers(ctx context.Context, hash string) []string {
+	mutex := &sync.Mutex{}
+	found := make(map[string]bool)
+
+	// этот узел может сам оказаться ближайшим к файлу
+	for _, a := range d.store.get(hash) {
+		found[a.addr] = true
+	}
+
+	d.lookup(ctx, Key(hash), func(ctx context.Context, client api.DHTClient) (*api.DHTNode, []*api.DHTNode, error) {
+		resp, err := client.GetPeers(ctx, &api.DHTGetPeersRequest{Sender: d.sender(), Hash: hash})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		mutex.Lock()
+		for _, p := range resp.Peers {
+			if p.Address != "" && !d.id.equal(p.Id) {
+				found[p.Address] = true
+			}
+		}
+		mutex.Unlock()
+
+		return resp.Sender, resp.Nodes, nil
+	})
+
+	addrs := make([]string, 0, len(found))
+	for addr := range found {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+
+	return addrs
+}
+
+// announce объявляет пира раздающим файл на ближайших к файлу узлах
+func (d *DHT) announce(ctx context.Context, hash string) {
+	mutex := &sync.Mutex{}
+	tokens := make(map[ID][]byte)
+
+	closest := d.lookup(ctx, Key(hash), func(ctx context.Context, client api.DHTClient) (*api.DHTNode, []*api.DHTNode, error) {
+		resp, err := client.GetPeers(ctx, &api.DHTGetPeersRequest{Sender: d.sender(), Hash: hash})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if id, ok := idFromBytes(resp.Sender.GetId()); ok {
+			mutex.Lock()
+			tokens[id] = resp.Token
+			mutex.Unlock()
+		}
+
+		return resp.Sender, resp.Nodes, nil
+	})
+
+	var announced int
+	for _, n := range closest {
+		token, ok := tokens[n.id]
+		if !ok {
+			continue
+		}
+
+		_, err := d.query(ctx, n, func(ctx context.Context, client api.DHTClient) (*api.DHTNode, []*api.DHTNode, error) {
+			_, err := client.AnnouncePeer(ctx, &api.DHTAnnounceRequest{Sender: d.sender(), Hash: hash, Token: token})
+			return nil, nil, err
+		})
+		if err == nil {
+			announced++
+		}
+	}
+
+	logger.GetLogger(ctx).WithField("hash", hash).WithField("nodes", announced).Debug("announced to dht")
+}
+
+type call func(ctx context.Context, client api.DHTClient) (sender *api.DHTNode, nodes []*api.DHTNode, err error)
+
+// lookup - итеративный поиск Kademlia: на каждом шаге опрашиваются alpha ближайших
+// к target еще не опрошенных узлов, пока не ответят все bucketSize ближайших.
+// Возвращает ближайшие ответившие узлы.
+func (d *DHT) lookup(ctx context.Context, target ID, c call) []node {
+	shortlist := d.table.closest(target, bucketSize)
+
+	seen := make(map[ID]bool)
+	for _, n := range shortlist {
+		seen[n.id] = true
+	}
+
+	queried := make(map[ID]bool)
+
+	for queries := 0; queries < maxQueries; {
+		var batch []node
+		for _, n := range shortlist {
+			if !queried[n.id] {
+				batch = append(batch, n)
+			}
+			if len(batch) == alpha {
+				break
+			}
+		}
+
+		if len(batch) == 0 {
+			break
+		}
+
+		found := make([][]node, len(batch))
+		failed := make([]bool, len(batch))
+
+		wg := &sync.WaitGroup{}
+		for i, n := range batch {
+			queried[n.id] = true
+			queries++
+
+			wg.Add(1)
+			go func(i int, n node) {
+				defer wg.Done()
+
+				nodes, err := d.query(ctx, n, c)
+				found[i], failed[i] = nodes, err != nil
+			}(i, n)
+		}
+		wg.Wait()
+
+		next := make([]node, 0, len(shortlist))
+		for _, n := range shortlist {
+			if !containsFailed(batch, failed, n.id) {
+				next = append(next, n)
+			}
+		}
+
+		for _, nodes := range found {
+			for _, n := range nodes {
+				if !seen[n.id] {
+					seen[n.id] = true
+					next = append(next, n)
+				}
+			}
+		}
+
+		sortByDistance(next, target)
+		if len(next) > bucketSize {
+			next = next[:bucketSize]
+		}
+
+		shortlist = next
+	}
+
+	return shortlist
+}
+
+func containsFailed(batch []node, failed []bool, id ID) bool {
+	for i, n := range batch {
+		if failed[i] && n.id == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// query - один запрос к узлу, возвращает узлы из ответа. Ответивший узел попадает
+// в таблицу, не ответивший из нее убирается. Если call не вернул отправителя,
+// узел считается тем, кем он записан в n.
+func (d *DHT) query(ctx context.Context, n node, c call) ([]node, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, n.addr, d.transport)
+	if err != nil {
+		d.table.remove(n.id)
+		return nil, err
+	}
+	defer conn.Close()
+
+	sender, nodes, err := c(ctx, api.NewDHTClient(conn))
+	if err != nil {
+		d.table.remove(n.id)
+		return nil, err
+	}
+
+	id := n.id
+	if sender != nil {
+		var ok bool
+		id, ok = idFromBytes(sender.Id)
+		if !ok {
+			d.table.remove(n.id)
+			return nil, errors.New("invalid dht node id")
+		}
+	}
+
+	d.table.update(id, n.addr)
+
+	return d.fromAPI(nodes), nil
+}
+
+// fromAPI - узлы из ответа, без себя и без узлов с неверным id или адресом
+func (d *DHT) fromAPI(nodes []*api.DHTNode) []node {
+	res := make([]node, 0, len(nodes))
+	for _, n := range nodes {
+		id, ok := idFromBytes(n.Id)
+		if !ok || id == d.id || n.Address == "" {
+			continue
+		}
+
+		res = append(res, node{id: id, addr: n.Address})
+	}
+
+	return res
+}
+
+func toAPI(nodes []node) []*api.DHTNode {
+	res := make([]*api.DHTNode, 0, len(nodes))
+	for _, n := range nodes {
+		id := n.id
+		res = append(res, &api.DHTNode{Id: id[:], Address: n.addr})
+	}
+
+	return res
+}
+
+// senderAddr - id отправителя и адрес, по которому он доступен: ip соединения
+// и порт из объявленного адреса, так узел не может выдать себя за чужой адрес
+func senderAddr(ctx context.Context, n *api.DHTNode) (ID, string, bool) {
+	id, ok := idFromBytes(n.GetId())
+	if !ok {
+		return id, "", false
+	}
+
+	_, port, err := net.SplitHostPort(n.GetAddress())
+	if err != nil || port == "" || port == "0" {
+		return id, "", false
+	}
+
+	ip, ok := remoteIP(ctx)
+	if !ok {
+		return id, "", false
+	}
+
+	return id, net.JoinHostPort(ip, port), true
+}
+
+func remoteIP(ctx context.Context) (string, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", false
+	}
+
+	tcp, ok := p.Addr.(*net.TCPAddr)
+	if !ok {
+		return "", false
+	}
+
+	return tcp.IP.String(), true
+}
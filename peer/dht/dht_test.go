@@ -0,0 +1,190 @@
+package dht
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/elizarpif/grpctorrent/api"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// startNode запускает узел DHT на свободном петлевом порту
+func startNode(t *testing.T, n int) *DHT {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := New(NewID([]byte(fmt.Sprintf("node-%d", n))), lis.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := grpc.NewServer()
+	api.RegisterDHTServer(server, d)
+
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	return d
+}
+
+// startNetwork запускает count узлов, каждый входит в сеть через первый
+func startNetwork(t *testing.T, count int) []*DHT {
+	t.Helper()
+
+	nodes := make([]*DHT, count)
+	for i := range nodes {
+		nodes[i] = startNode(t, i)
+	}
+
+	for _, d := range nodes[1:] {
+		d.bootstrap(context.Background(), []string{nodes[0].addr})
+	}
+
+	return nodes
+}
+
+func findNode(target ID) call {
+	return func(ctx context.Context, client api.DHTClient) (*api.DHTNode, []*api.DHTNode, error) {
+		resp, err := client.FindNode(ctx, &api.DHTFindNodeRequest{Target: target[:]})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return resp.Sender, resp.Nodes, nil
+	}
+}
+
+func TestLookup(t *testing.T) {
+	nodes := startNetwork(t, 12)
+
+	// после входа в сеть узлы знают не только первый
+	for i, d := range nodes {
+		if known := len(d.table.closest(d.id, 100)); known < 2 {
+			t.Errorf("node %d knows %d nodes", i, known)
+		}
+	}
+
+	// последний узел находит любой другой, хотя знал о нем не напрямую
+	for _, target := range nodes[1:11] {
+		found := nodes[11].lookup(context.Background(), target.id, findNode(target.id))
+		if len(found) == 0 || found[0].id != target.id || found[0].addr != target.addr {
+			t.Errorf("lookup of %x found %v", target.id[:4], found)
+		}
+	}
+}
+
+func TestLookupDropsDeadNodes(t *testing.T) {
+	nodes := startNetwork(t, 4)
+
+	// узел ушел из сети, но остался в таблице
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dead := NewID([]byte("dead"))
+	deadAddr := lis.Addr().String()
+	lis.Close()
+
+	nodes[1].table.update(dead, deadAddr)
+
+	found := nodes[1].lookup(context.Background(), dead, findNode(dead))
+	for _, n := range found {
+		if n.id == dead {
+			t.Error("lookup returned a node that did not answer")
+		}
+	}
+
+	for _, n := range nodes[1].table.closest(dead, 100) {
+		if n.id == dead {
+			t.Error("node that did not answer is kept in the table")
+		}
+	}
+}
+
+func TestAnnounceAndFindPeers(t *testing.T) {
+	nodes := startNetwork(t, 10)
+	seeder := nodes[3]
+
+	seeder.announce(context.Background(), testHash)
+
+	// объявление хранят узлы, ближайшие к ключу файла
+	stored := 0
+	for _, d := range nodes {
+		for _, a := range d.store.get(testHash) {
+			if a.addr != seeder.addr || a.id != seeder.id {
+				t.Errorf("stored %x at %s, want the seeder", a.id[:4], a.addr)
+			}
+			stored++
+		}
+	}
+	if stored == 0 {
+		t.Fatal("announcement is stored nowhere")
+	}
+
+	for i, d := range nodes {
+		got := d.FindPeers(context.Background(), testHash)
+
+		// себя узел в ответах не находит
+		want := []string{seeder.addr}
+		if d == seeder {
+			want = nil
+		}
+
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("node %d found %v, want %v", i, got, want)
+		}
+	}
+
+	if got := nodes[0].FindPeers(context.Background(), "unknown"); len(got) != 0 {
+		t.Errorf("peers of an unknown file: %v", got)
+	}
+}
+
+func TestAnnouncePeerToken(t *testing.T) {
+	node := startNode(t, 0)
+	sender := startNode(t, 1)
+
+	conn, err := grpc.Dial(node.addr, grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	client := api.NewDHTClient(conn)
+	ctx := context.Background()
+
+	resp, err := client.GetPeers(ctx, &api.DHTGetPeersRequest{Sender: sender.sender(), Hash: testHash})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		req  *api.DHTAnnounceRequest
+		code codes.Code
+	}{
+		{name: "forged token", req: &api.DHTAnnounceRequest{Sender: sender.sender(), Hash: testHash, Token: []byte("token")}, code: codes.PermissionDenied},
+		{name: "no sender", req: &api.DHTAnnounceRequest{Hash: testHash, Token: resp.Token}, code: codes.InvalidArgument},
+		{name: "no hash", req: &api.DHTAnnounceRequest{Sender: sender.sender(), Token: resp.Token}, code: codes.InvalidArgument},
+		{name: "valid token", req: &api.DHTAnnounceRequest{Sender: sender.sender(), Hash: testHash, Token: resp.Token}, code: codes.OK},
+	}
+
+	for _, tt := range tests {
+		if _, err := client.AnnouncePeer(ctx, tt.req); status.Code(err) != tt.code {
+			t.Errorf("%s: AnnouncePeer = %v, want %v", tt.name, err, tt.code)
+		}
+	}
+
+	// узел записан под ip соединения и портом из объявленного адреса
+	if got := node.store.get(testHash); len(got) != 1 || got[0].addr != sender.addr {
+		t.Errorf("stored %v, want %s", got, sender.addr)
+	}
+}
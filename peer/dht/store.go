@@ -0,0 +1,122 @@
+package dht
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"sync"
+	"time"
+)
+
+const (
+	peerTTL = 30 * time.Minute // объявление живет столько, если его не повторить
+
+	maxPeersPerHash = 100   // сколько пиров одного файла хранится и отдается
+	maxHashes       = 10000 // сколько файлов узел готов помнить
+
+	// токен для AnnouncePeer живет от одного до двух таких периодов
+	tokenTTL = 5 * time.Minute
+)
+
+type announced struct {
+	id   ID
+	addr string
+	at   time.Time
+}
+
+// store - пиры файлов, объявленные на этом узле, и токены для объявлений
+type store struct {
+	peers  map[string]map[string]*announced // хэш файла - адрес - объявление
+	secret []byte                           // ключ, которым подписываются токены
+
+	mutex *sync.Mutex
+}
+
+func newStore() (*store, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+
+	return &store{peers: make(map[string]map[string]*announced), secret: secret, mutex: &sync.Mutex{}}, nil
+}
+
+// add запоминает пира файла. Когда места нет, новый файл не принимается,
+// а у известного файла вытесняется самое старое объявление.
+func (s *store) add(hash string, id ID, addr string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	peers, ok := s.peers[hash]
+	if !ok {
+		if len(s.peers) >= maxHashes {
+			return
+		}
+
+		peers = make(map[string]*announced)
+		s.peers[hash] = peers
+	}
+
+	if _, ok := peers[addr]; !ok && len(peers) >= maxPeersPerHash {
+		var oldest *announced
+		for _, a := range peers {
+			if oldest == nil || a.at.Before(oldest.at) {
+				oldest = a
+			}
+		}
+		delete(peers, oldest.addr)
+	}
+
+	peers[addr] = &announced{id: id, addr: addr, at: time.Now()}
+}
+
+func (s *store) get(hash string) []announced {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var res []announced
+	for _, a := range s.peers[hash] {
+		if time.Since(a.at) < peerTTL {
+			res = append(res, *a)
+		}
+	}
+
+	return res
+}
+
+// expire забывает устаревшие объявления
+func (s *store) expire() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for hash, peers := range s.peers {
+		for addr, a := range peers {
+			if time.Since(a.at) >= peerTTL {
+				delete(peers, addr)
+			}
+		}
+
+		if len(peers) == 0 {
+			delete(s.peers, hash)
+		}
+	}
+}
+
+// token - подпись ip спрашивающего и периода времени, хранить выданные токены не нужно
+func (s *store) token(ip string, now time.Time) []byte {
+	period := now.Unix() / int64(tokenTTL/time.Second)
+
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(ip))
+	_ = binary.Write(mac, binary.BigEndian, period)
+
+	return mac.Sum(nil)
+}
+
+// validToken принимает токен текущего и предыдущего периода
+func (s *store) validToken(token []byte, ip string) bool {
+	now := time.Now()
+
+	return hmac.Equal(token, s.token(ip, now)) || hmac.Equal(token, s.token(ip, now.Add(-tokenTTL)))
+}
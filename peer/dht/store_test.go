@@ -0,0 +1,108 @@
+package dht
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+const testHash = "9702842ac5824617babda6a32791ac2f"
+
+func newTestStore(t *testing.T) *store {
+	t.Helper()
+
+	s, err := newStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+func TestStore(t *testing.T) {
+	s := newTestStore(t)
+	a, b := NewID([]byte("a")), NewID([]byte("b"))
+
+	s.add(testHash, a, "10.0.0.1:9002")
+	s.add(testHash, b, "10.0.0.2:9002")
+	s.add(testHash, b, "10.0.0.2:9002") // повторное объявление обновляет время
+
+	if got := s.get(testHash); len(got) != 2 {
+		t.Errorf("get = %v, want 2 peers", got)
+	}
+	if got := s.get("other"); len(got) != 0 {
+		t.Errorf("peers of an unknown file: %v", got)
+	}
+
+	// устаревшее объявление не отдается и забывается вместе с опустевшим файлом
+	s.peers[testHash]["10.0.0.1:9002"].at = time.Now().Add(-peerTTL)
+	if got := s.get(testHash); len(got) != 1 || got[0].id != b {
+		t.Errorf("get after ttl = %v", got)
+	}
+
+	s.peers[testHash]["10.0.0.2:9002"].at = time.Now().Add(-peerTTL)
+	s.expire()
+	if len(s.peers) != 0 {
+		t.Errorf("expired peers are kept: %v", s.peers)
+	}
+}
+
+func TestStoreLimits(t *testing.T) {
+	s := newTestStore(t)
+
+	for i := 0; i < maxPeersPerHash; i++ {
+		s.add(testHash, NewID([]byte{byte(i)}), fmt.Sprintf("10.0.%d.%d:9002", i/256, i%256))
+	}
+
+	// новый пир вытесняет самое старое объявление файла
+	oldest := s.peers[testHash]["10.0.0.5:9002"]
+	oldest.at = oldest.at.Add(-time.Minute)
+
+	s.add(testHash, NewID([]byte("new")), "10.1.0.1:9002")
+
+	if len(s.peers[testHash]) != maxPeersPerHash {
+		t.Errorf("%d peers stored, want %d", len(s.peers[testHash]), maxPeersPerHash)
+	}
+	if _, ok := s.peers[testHash]["10.0.0.5:9002"]; ok {
+		t.Error("oldest announcement is kept")
+	}
+	if _, ok := s.peers[testHash]["10.1.0.1:9002"]; !ok {
+		t.Error("new announcement is dropped")
+	}
+
+	// новые файлы сверх предела не принимаются, известные обновляются
+	for i := len(s.peers); i < maxHashes; i++ {
+		s.add(fmt.Sprintf("%032x", i), NewID(nil), "10.0.0.1:9002")
+	}
+	s.add("one too many", NewID(nil), "10.0.0.1:9002")
+
+	if len(s.peers) != maxHashes {
+		t.Errorf("%d files stored, want %d", len(s.peers), maxHashes)
+	}
+	if _, ok := s.peers["one too many"]; ok {
+		t.Error("file over the limit is stored")
+	}
+}
+
+func TestStoreToken(t *testing.T) {
+	s := newTestStore(t)
+	now := time.Now()
+
+	if !s.validToken(s.token("10.0.0.1", now), "10.0.0.1") {
+		t.Error("fresh token is rejected")
+	}
+	if !s.validToken(s.token("10.0.0.1", now.Add(-tokenTTL)), "10.0.0.1") {
+		t.Error("token of the previous period is rejected")
+	}
+	if s.validToken(s.token("10.0.0.1", now.Add(-2*tokenTTL)), "10.0.0.1") {
+		t.Error("token two periods old is accepted")
+	}
+	if s.validToken(s.token("10.0.0.1", now), "10.0.0.2") {
+		t.Error("token is accepted from another ip")
+	}
+
+	// токен другого узла не подходит
+	if newTestStore(t).validToken(s.token("10.0.0.1", now), "10.0.0.1") {
+		t.Error("token of another node is accepted")
+	}
+}
@@ -0,0 +1,167 @@
+//nolint:gosec // sha1 здесь - способ получить 160-битный id, а не защита
+package dht
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"math/bits"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// IDLen - длина id узла и ключа файла в байтах
+	IDLen = sha1.Size
+
+	bucketSize = 8 // k: сколько узлов хранится в одной корзине и возвращается в ответах
+
+	// узел, от которого так долго ничего не было, уступает место новому
+	staleAfter = 15 * time.Minute
+)
+
+// ID - идентификатор узла или ключ файла в пространстве DHT
+type ID [IDLen]byte
+
+// NewID выводит id из произвольных байтов, например публичного ключа пира
+func NewID(seed []byte) ID {
+	return sha1.Sum(seed)
+}
+
+// Key - ключ файла в DHT по его хэшу
+func Key(hash string) ID {
+	return NewID([]byte(hash))
+}
+
+func idFromBytes(b []byte) (ID, bool) {
+	var id ID
+	if len(b) != IDLen {
+		return id, false
+	}
+
+	copy(id[:], b)
+	return id, true
+}
+
+// closer - ближе ли a к target, чем b, по метрике xor
+func closer(target, a, b ID) bool {
+	for i := range target {
+		da, db := a[i]^target[i], b[i]^target[i]
+		if da != db {
+			return da < db
+		}
+	}
+
+	return false
+}
+
+// bucket - номер корзины для id: длина общего префикса с self, -1 для самого self
+func bucket(self, id ID) int {
+	for i := range self {
+		if x := self[i] ^ id[i]; x != 0 {
+			return i*8 + bits.LeadingZeros8(x)
+		}
+	}
+
+	return -1
+}
+
+type node struct {
+	id   ID
+	addr string
+	seen time.Time // последний ответ или запрос от узла
+}
+
+// table - таблица маршрутизации Kademlia: корзины по длине общего префикса с self,
+// в каждой корзине узлы от давно виденных к недавним
+type table struct {
+	self    ID
+	buckets [IDLen * 8][]*node
+
+	mutex *sync.Mutex
+}
+
+func newTable(self ID) *table {
+	return &table{self: self, mutex: &sync.Mutex{}}
+}
+
+// update отмечает, что узел жив. Новый узел попадает в полную корзину,
+// только если в ней есть давно молчащий.
+func (t *table) update(id ID, addr string) {
+	i := bucket(t.self, id)
+	if i < 0 {
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	b := t.buckets[i]
+	now := time.Now()
+
+	for j, n := range b {
+		if n.id == id {
+			n.addr, n.seen = addr, now
+			t.buckets[i] = append(append(b[:j:j], b[j+1:]...), n)
+			return
+		}
+	}
+
+	if len(b) >= bucketSize {
+		if now.Sub(b[0].seen) < staleAfter {
+			return
+		}
+
+		b = b[1:]
+	}
+
+	t.buckets[i] = append(b, &node{id: id, addr: addr, seen: now})
+}
+
+// remove убирает узел, который не ответил
+func (t *table) remove(id ID) {
+	i := bucket(t.self, id)
+	if i < 0 {
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	b := t.buckets[i]
+	for j, n := range b {
+		if n.id == id {
+			t.buckets[i] = append(b[:j:j], b[j+1:]...)
+			return
+		}
+	}
+}
+
+// closest - до count известных узлов, ближайших к target
+func (t *table) closest(target ID, count int) []node {
+	t.mutex.Lock()
+	var all []node
+	for _, b := range t.buckets {
+		for _, n := range b {
+			all = append(all, *n)
+		}
+	}
+	t.mutex.Unlock()
+
+	sortByDistance(all, target)
+	if len(all) > count {
+		all = all[:count]
+	}
+
+	return all
+}
+
+func sortByDistance(nodes []node, target ID) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return closer(target, nodes[i].id, nodes[j].id)
+	})
+}
+
+func (id ID) equal(other []byte) bool {
+	return bytes.Equal(id[:], other)
+}
@@ -0,0 +1,139 @@
+package dht
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// neighbour - id, у которого с self общие ровно prefix первых бит; n различает узлы одной корзины
+func neighbour(self ID, prefix int, n byte) ID {
+	id := self
+	id[prefix/8] ^= 0x80 >> uint(prefix%8)
+	id[IDLen-1] ^= n
+
+	return id
+}
+
+func TestBucket(t *testing.T) {
+	self := NewID([]byte("self"))
+
+	if got := bucket(self, self); got != -1 {
+		t.Errorf("bucket of self = %d, want -1", got)
+	}
+
+	for _, prefix := range []int{0, 1, 7, 8, 63, 150} {
+		if got := bucket(self, neighbour(self, prefix, 1)); got != prefix {
+			t.Errorf("bucket for a common prefix of %d bits = %d", prefix, got)
+		}
+	}
+
+	// последний бит: дальше в корзине различать нечем
+	if got := bucket(self, neighbour(self, IDLen*8-1, 0)); got != IDLen*8-1 {
+		t.Errorf("bucket for the last bit = %d", got)
+	}
+}
+
+// TestTablePlacement - корзины заведены сразу для каждой длины общего префикса,
+// поэтому корзина, в которую попадает сам узел, не делится: узлы с разной
+// длиной префикса сразу расходятся по своим корзинам и не вытесняют друг друга
+func TestTablePlacement(t *testing.T) {
+	self := NewID([]byte("self"))
+	tbl := newTable(self)
+
+	for prefix := 0; prefix < 4; prefix++ {
+		for n := 1; n <= bucketSize; n++ {
+			tbl.update(neighbour(self, prefix, byte(n)), fmt.Sprintf("10.0.%d.%d:9000", prefix, n))
+		}
+	}
+
+	tbl.update(self, "10.0.0.0:9000") // себя таблица не хранит
+
+	for prefix := 0; prefix < 4; prefix++ {
+		if got := len(tbl.buckets[prefix]); got != bucketSize {
+			t.Errorf("bucket %d holds %d nodes, want %d", prefix, got, bucketSize)
+		}
+	}
+
+	if got := len(tbl.closest(self, 1000)); got != 4*bucketSize {
+		t.Errorf("table holds %d nodes, want %d", got, 4*bucketSize)
+	}
+}
+
+func TestTableEviction(t *testing.T) {
+	self := NewID([]byte("self"))
+	tbl := newTable(self)
+
+	for n := 1; n <= bucketSize; n++ {
+		tbl.update(neighbour(self, 0, byte(n)), fmt.Sprintf("10.0.0.%d:9000", n))
+	}
+
+	// в полную корзину из живых узлов новый не попадает
+	newcomer := neighbour(self, 0, 100)
+	tbl.update(newcomer, "10.0.1.1:9000")
+	if contains(tbl.buckets[0], newcomer) {
+		t.Fatal("newcomer replaced a live node")
+	}
+
+	// ответивший узел переезжает в конец корзины с новым адресом
+	first := neighbour(self, 0, 1)
+	tbl.update(first, "10.0.0.101:9000")
+	if last := tbl.buckets[0][bucketSize-1]; last.id != first || last.addr != "10.0.0.101:9000" {
+		t.Errorf("updated node %x at %s is not at the tail", last.id[IDLen-1], last.addr)
+	}
+
+	// давно молчащий узел в голове корзины уступает место
+	oldest := tbl.buckets[0][0]
+	oldest.seen = time.Now().Add(-staleAfter)
+
+	tbl.update(newcomer, "10.0.1.1:9000")
+	if !contains(tbl.buckets[0], newcomer) || contains(tbl.buckets[0], oldest.id) {
+		t.Error("stale node is not evicted")
+	}
+	if len(tbl.buckets[0]) != bucketSize {
+		t.Errorf("bucket holds %d nodes", len(tbl.buckets[0]))
+	}
+
+	// не ответивший узел убирается, и место освобождается
+	tbl.remove(newcomer)
+	if contains(tbl.buckets[0], newcomer) || len(tbl.buckets[0]) != bucketSize-1 {
+		t.Error("removed node is kept")
+	}
+	tbl.remove(newcomer)
+	tbl.remove(self)
+}
+
+func TestTableClosest(t *testing.T) {
+	self := NewID([]byte("self"))
+	tbl := newTable(self)
+
+	var ids []ID
+	for prefix := 0; prefix < 10; prefix++ {
+		id := neighbour(self, prefix, 1)
+		ids = append(ids, id)
+		tbl.update(id, fmt.Sprintf("10.0.0.%d:9000", prefix))
+	}
+
+	// ближе всего к цели узел с самым длинным общим с ней префиксом
+	target := neighbour(self, 9, 2)
+
+	got := tbl.closest(target, 3)
+	if len(got) != 3 {
+		t.Fatalf("closest returned %d nodes", len(got))
+	}
+	for i, want := range []ID{ids[9], ids[8], ids[7]} {
+		if got[i].id != want {
+			t.Errorf("closest[%d] = %x, want %x", i, got[i].id, want)
+		}
+	}
+}
+
+func contains(b []*node, id ID) bool {
+	for _, n := range b {
+		if n.id == id {
+			return true
+		}
+	}
+
+	return false
+}
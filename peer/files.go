@@ -2,15 +2,22 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"crypto/ed25519"
 	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"io/ioutil"
 	"os"
 	"path"
 	"sort"
+	"sync"
 
 	"github.com/elizarpif/grpctorrent/api"
+	"github.com/elizarpif/grpctorrent/api/auth"
 	"github.com/elizarpif/logger"
 )
 
@@ -21,7 +28,120 @@ type file struct {
 	length    uint64
 
 	piecesLen uint64
+	pieces    uint64 // всего кусочков в файле
 	piecesMap map[uint]*api.Piece
+	private   bool // куски отдаются только с токеном трекера
+
+	// описание, подписанное издателем; не меняется после создания файла
+	pieceHashes  [][]byte      // хэш каждого куска
+	pieceHash    api.PieceHash // алгоритм хэшей кусков
+	publisherKey ed25519.PublicKey
+	signature    []byte
+
+	mutex *sync.RWMutex // защищает piecesMap, allPieces и private
+}
+
+func newEmptyFile(info *api.FileInfo) *file {
+	return &file{
+		name:      info.Name,
+		hash:      info.Hash,
+		allPieces: false,
+		length:    info.Length,
+		piecesLen: info.PieceLength,
+		pieces:    info.Pieces,
+		piecesMap: make(map[uint]*api.Piece), // карта кусков
+		private:   info.Private,
+		mutex:     &sync.RWMutex{},
+
+		pieceHashes:  info.PieceHashes,
+		pieceHash:    info.PieceHash,
+		publisherKey: info.PublisherKey,
+		signature:    info.Signature,
+	}
+}
+
+func (f *file) isPrivate() bool {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.private
+}
+
+func (f *file) setPrivate(private bool) {
+	f.mutex.Lock()
+	f.private = private
+	f.mutex.Unlock()
+}
+
+// setPiece публикует докачанный кусок, после этого его можно раздавать
+func (f *file) setPiece(piece *api.Piece) {
+	f.mutex.Lock()
+	f.piecesMap[uint(piece.SerialNumber)] = piece
+	f.mutex.Unlock()
+}
+
+func (f *file) getPiece(serial uint64) (*api.Piece, bool) {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	piece, ok := f.piecesMap[uint(serial)]
+	return piece, ok
+}
+
+func (f *file) info() *api.FileInfo {
+	return &api.FileInfo{
+		Name:        f.name,
+		PieceLength: f.piecesLen,
+		Pieces:      f.pieces,
+		Length:      f.length,
+		Hash:        f.hash,
+		Private:     f.isPrivate(),
+
+		PieceHashes:  f.pieceHashes,
+		PieceHash:    f.pieceHash,
+		PublisherKey: f.publisherKey,
+		Signature:    f.signature,
+	}
+}
+
+func (f *file) meta() *auth.FileMeta {
+	return &auth.FileMeta{
+		Name:        f.name,
+		Length:      f.length,
+		PieceLength: f.piecesLen,
+		Hash:        f.hash,
+		PieceHashes: f.pieceHashes,
+		PieceHash:   f.pieceHash.String(),
+	}
+}
+
+// sign подписывает описание файла ключом пира как издателя
+func (f *file) sign(key ed25519.PrivateKey) {
+	f.publisherKey = key.Public().(ed25519.PublicKey)
+	f.signature = auth.SignFile(key, f.meta())
+}
+
+// checkPiece сверяет кусок с хэшем из описания; у неподписанного файла хэшей может не быть
+func (f *file) checkPiece(serial uint64, piece *api.Piece) bool {
+	if len(f.pieceHashes) == 0 {
+		return true
+	}
+
+	if serial >= uint64(len(f.pieceHashes)) {
+		return false
+	}
+
+	return bytes.Equal(hashPiece(f.pieceHash, piece.Payload), f.pieceHashes[serial])
+}
+
+func hashPiece(kind api.PieceHash, payload []byte) []byte {
+	if kind == api.PieceHash_SHA1 {
+		sum := sha1.Sum(payload) //nolint:gosec // так устроены торрент-файлы BitTorrent v1
+		return sum[:]
+	}
+
+	sum := sha256.Sum256(payload)
+	return sum[:]
 }
 
 // fixme
@@ -47,6 +167,11 @@ func getPieceLength(length int) int {
 func splitFile(content []byte) (res map[uint]*api.Piece, length uint64) {
 	pieceLen := getPieceLength(len(content))
 
+	return splitFileBy(content, pieceLen), uint64(pieceLen)
+}
+
+// деление файла на куски заданной длины
+func splitFileBy(content []byte, pieceLen int) map[uint]*api.Piece {
 	var serial uint64 = 0
 
 	mapPiece := make(map[uint]*api.Piece)
@@ -65,7 +190,7 @@ func splitFile(content []byte) (res map[uint]*api.Piece, length uint64) {
 		serial++
 	}
 
-	return mapPiece, uint64(pieceLen)
+	return mapPiece
 }
 
 func getHash(fContent []byte) string {
@@ -73,8 +198,9 @@ func getHash(fContent []byte) string {
 	return hex.EncodeToString(hash[:])
 }
 
-//nolint:gosec // for hash
 // чтение файла и создание торрент-файла с последующей загрузкой
+//
+//nolint:gosec // for hash
 func newFile(name string) (*file, error) {
 	fContent, err := ioutil.ReadFile(name)
 	if err != nil {
@@ -90,7 +216,14 @@ func newFile(name string) (*file, error) {
 		hash:      getHash(fContent),
 		piecesMap: pMap,
 		piecesLen: pLen,
+		pieces:    uint64(len(pMap)),
 		allPieces: true,
+		mutex:     &sync.RWMutex{},
+	}
+
+	f.pieceHashes = make([][]byte, f.pieces)
+	for serial, piece := range pMap {
+		f.pieceHashes[serial] = hashPiece(f.pieceHash, piece.Payload)
 	}
 
 	return f, nil
@@ -98,6 +231,9 @@ func newFile(name string) (*file, error) {
 
 // сортировка кусочков (на всякий)
 func (f *file) sortPieces() []*api.Piece {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
 	pieces := make([]*api.Piece, 0, len(f.piecesMap))
 	for _, v := range f.piecesMap {
 		pieces = append(pieces, v)
@@ -128,12 +264,20 @@ func (f *file) write(bytes []byte) error {
 	return err
 }
 
-//nolint:gosec // for hash
+// куски сошлись с описанием, а файл целиком - нет: описание подложное
+var errContentMismatch = errors.New("downloaded content doesnt match file hash")
+
 // склеивание файла из кусочков
+//
+//nolint:gosec // for hash
 func (f *file) MergePieces(ctx context.Context) error {
 	log := logger.GetLogger(ctx)
 
-	if !f.allPieces {
+	f.mutex.RLock()
+	allPieces := f.allPieces
+	f.mutex.RUnlock()
+
+	if !allPieces {
 		log.Warning("no all pieces")
 		return nil
 	}
@@ -148,10 +292,13 @@ func (f *file) MergePieces(ctx context.Context) error {
 	tmp := md5.Sum(bytes)
 	newHash := hex.EncodeToString(tmp[:])
 
-	if f.hash != newHash {
+	// у файла из торрент-файла хэш - это infohash, а куски уже сверены по sha1
+	if f.pieceHash == api.PieceHash_SHA256 && f.hash != newHash {
 		log.WithField("oldHash", f.hash).
 			WithField("newHash", newHash).
 			Error("hash not expected")
+
+		return errContentMismatch
 	}
 
 	err := f.write(bytes)
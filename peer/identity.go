@@ -0,0 +1,130 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/elizarpif/grpctorrent/api/auth"
+	"github.com/google/uuid"
+)
+
+const identityFile = "identity.json"
+
+// identity - постоянная личность пира: peer_id и ключевая пара,
+// хранится в каталоге состояния и переживает перезапуски.
+// peer_id выводится из открытого ключа, трекер и пиры сверяют их в каждой подписи.
+type identity struct {
+	id         uuid.UUID
+	publicKey  ed25519.PublicKey
+	privateKey ed25519.PrivateKey
+}
+
+// так личность лежит на диске, от приватного ключа хранится только seed
+type identityJSON struct {
+	PeerID string `json:"peer_id"`
+	Seed   []byte `json:"seed"`
+}
+
+// loadIdentity читает личность из каталога состояния, при первом запуске создает ее
+func loadIdentity(dir string) (*identity, error) {
+	name := filepath.Join(dir, identityFile)
+
+	info, err := os.Stat(name)
+	if os.IsNotExist(err) {
+		return createIdentity(dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// ключ, доступный другим пользователям, мог быть прочитан или подменен
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0o077 != 0 {
+		return nil, errors.New(name + " is accessible by other users, run chmod 600 on it")
+	}
+
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var stored identityJSON
+	err = json.Unmarshal(data, &stored)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(stored.Seed) != ed25519.SeedSize {
+		return nil, errors.New("invalid key seed in " + name)
+	}
+
+	ident := newIdentity(ed25519.NewKeyFromSeed(stored.Seed))
+
+	// личность, созданная до вывода peer_id из ключа, хранит случайный peer_id:
+	// трекер его больше не примет, поэтому файл переписывается
+	if stored.PeerID != ident.id.String() {
+		return ident, saveIdentity(dir, ident)
+	}
+
+	return ident, nil
+}
+
+func newIdentity(privateKey ed25519.PrivateKey) *identity {
+	publicKey := privateKey.Public().(ed25519.PublicKey)
+
+	return &identity{
+		id:         uuid.MustParse(auth.PeerID(publicKey)),
+		publicKey:  publicKey,
+		privateKey: privateKey,
+	}
+}
+
+func createIdentity(dir string) (*identity, error) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	ident := newIdentity(privateKey)
+
+	return ident, saveIdentity(dir, ident)
+}
+
+func saveIdentity(dir string, ident *identity) error {
+	data, err := json.MarshalIndent(identityJSON{
+		PeerID: ident.id.String(),
+		Seed:   ident.privateKey.Seed(),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filepath.Join(dir, identityFile), data, 0o600)
+}
+
+// defaultStateDir - каталог состояния по умолчанию, свой для каждого grpc-порта,
+// чтобы несколько пиров на одной машине не делили одну личность
+func defaultStateDir(grpcAddr string) string {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		base = "."
+	}
+
+	_, port, err := net.SplitHostPort(grpcAddr)
+	if err != nil {
+		port = grpcAddr
+	}
+
+	return filepath.Join(base, "grpctorrent", "peer-"+port)
+}
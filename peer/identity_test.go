@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/elizarpif/grpctorrent/api/auth"
+)
+
+func tempStateDir(t *testing.T) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "identity")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	// личность создается в еще не существующем каталоге состояния
+	return filepath.Join(dir, "state")
+}
+
+func TestIdentityReload(t *testing.T) {
+	dir := tempStateDir(t)
+
+	created, err := loadIdentity(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if created.id.String() != auth.PeerID(created.publicKey) {
+		t.Errorf("peer id %s is not derived from the key", created.id)
+	}
+
+	name := filepath.Join(dir, identityFile)
+	if info, err := os.Stat(name); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("identity file: %v, %v", info, err)
+	}
+	if info, err := os.Stat(dir); err != nil || info.Mode().Perm() != 0o700 {
+		t.Errorf("state dir: %v, %v", info, err)
+	}
+
+	loaded, err := loadIdentity(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded.id != created.id || !bytes.Equal(loaded.privateKey, created.privateKey) {
+		t.Errorf("reloaded identity %s differs from the created %s", loaded.id, created.id)
+	}
+}
+
+func TestIdentityRandomPeerID(t *testing.T) {
+	dir := tempStateDir(t)
+
+	created, err := loadIdentity(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// так личность хранила прошлая версия: peer_id не связан с ключом
+	writeIdentity(t, dir, identityJSON{PeerID: "00000000-0000-4000-8000-000000000000", Seed: created.privateKey.Seed()}, 0o600)
+
+	loaded, err := loadIdentity(dir)
+	if err != nil || loaded.id != created.id {
+		t.Fatalf("loaded %v, %v, want %s", loaded, err, created.id)
+	}
+
+	var stored identityJSON
+	data, _ := ioutil.ReadFile(filepath.Join(dir, identityFile))
+	if err := json.Unmarshal(data, &stored); err != nil || stored.PeerID != created.id.String() {
+		t.Errorf("stored peer id %q, %v, want it rewritten", stored.PeerID, err)
+	}
+}
+
+func TestIdentityRejected(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not checked on windows")
+	}
+
+	seed := bytes.Repeat([]byte{1}, 32)
+
+	tests := []struct {
+		name string
+		data []byte
+		mode os.FileMode
+	}{
+		{name: "readable by group", data: identityData(t, identityJSON{Seed: seed}), mode: 0o640},
+		{name: "readable by others", data: identityData(t, identityJSON{Seed: seed}), mode: 0o604},
+		{name: "writable by others", data: identityData(t, identityJSON{Seed: seed}), mode: 0o622},
+		{name: "not json", data: []byte("seed"), mode: 0o600},
+		{name: "short seed", data: identityData(t, identityJSON{Seed: seed[:16]}), mode: 0o600},
+		{name: "no seed", data: []byte(`{"peer_id": "00000000-0000-4000-8000-000000000000"}`), mode: 0o600},
+	}
+
+	for _, tt := range tests {
+		dir := tempStateDir(t)
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			t.Fatal(err)
+		}
+
+		name := filepath.Join(dir, identityFile)
+		if err := ioutil.WriteFile(name, tt.data, tt.mode); err != nil {
+			t.Fatal(err)
+		}
+		// WriteFile учитывает umask
+		if err := os.Chmod(name, tt.mode); err != nil {
+			t.Fatal(err)
+		}
+
+		if ident, err := loadIdentity(dir); err == nil {
+			t.Errorf("%s: loaded identity %s", tt.name, ident.id)
+		}
+
+		// отвергнутый файл не заменяется новой личностью
+		if data, _ := ioutil.ReadFile(name); !bytes.Equal(data, tt.data) {
+			t.Errorf("%s: identity file changed", tt.name)
+		}
+	}
+}
+
+func identityData(t *testing.T, stored identityJSON) []byte {
+	t.Helper()
+
+	data, err := json.Marshal(stored)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+func writeIdentity(t *testing.T, dir string, stored identityJSON, mode os.FileMode) {
+	t.Helper()
+
+	if err := ioutil.WriteFile(filepath.Join(dir, identityFile), identityData(t, stored), mode); err != nil {
+		t.Fatal(err)
+	}
+}
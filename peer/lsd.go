@@ -0,0 +1,362 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/elizarpif/grpctorrent/api"
+	"github.com/elizarpif/logger"
+)
+
+const (
+	lsdProto = "grpctorrent-lsd/2"
+
+	lsdMaxHashes = 32   // хэшей в одном пакете, остальные уходят следующими
+	lsdKeep      = 50   // сколько пиров одного файла помнится
+	lsdMaxFiles  = 1000 // сколько файлов помнится
+
+	maxHashLen = 64 // длиннее хэшей в сети нет
+)
+
+// lsdAnnounce - multicast-объявление: пир с таким grpc-адресом раздает эти файлы
+type lsdAnnounce struct {
+	Proto   string   `json:"proto"`
+	PeerID  string   `json:"peer_id"`
+	Address string   `json:"address"`
+	Hashes  []string `json:"hashes"`
+}
+
+type localPeer struct {
+	peerID string
+	seen   time.Time
+}
+
+// localDiscovery находит пиров в локальной сети: раз в interval пир рассылает
+// в multicast-группу хэши своих публичных файлов и слушает объявления других
+type localDiscovery struct {
+	group    *net.UDPAddr
+	interval time.Duration
+	peerID   string // свои объявления пропускаем
+	addr     string // grpc-адрес, который пир сообщает другим
+	silent   bool   // пир слушает только loopback: из сети до него не достучаться, объявлять нечего
+
+	provided map[string]bool                  // свои файлы для объявления
+	found    map[string]map[string]*localPeer // хэш - адрес - пир
+	wake     chan struct{}
+
+	mutex *sync.Mutex
+}
+
+// newLocalDiscovery создает поиск для пира, который слушает на listen и сообщает другим addr
+func newLocalDiscovery(group string, interval time.Duration, peerID, addr, listen string) (*localDiscovery, error) {
+	if interval <= 0 {
+		return nil, errors.New("local discovery interval must be positive")
+	}
+
+	groupAddr, err := net.ResolveUDPAddr("udp4", group)
+	if err != nil {
+		return nil, err
+	}
+
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	listenHost, _, err := net.SplitHostPort(listen)
+	if err != nil {
+		return nil, err
+	}
+
+	return &localDiscovery{
+		group:    groupAddr,
+		interval: interval,
+		peerID:   peerID,
+		addr:     addr,
+		silent:   loopbackOnly(listenHost) || loopbackOnly(host),
+		provided: make(map[string]bool),
+		found:    make(map[string]map[string]*localPeer),
+		wake:     make(chan struct{}, 1),
+		mutex:    &sync.Mutex{},
+	}, nil
+}
+
+// provide добавляет файл в объявления, первое уходит сразу
+func (l *localDiscovery) provide(hash string) {
+	l.mutex.Lock()
+	l.provided[hash] = true
+	l.mutex.Unlock()
+
+	select {
+	case l.wake <- struct{}{}:
+	default:
+	}
+}
+
+// peers - адреса пиров файла, объявлявшихся за последние три интервала
+func (l *localDiscovery) peers(hash string) []string {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	var addrs []string
+	for addr, p := range l.found[hash] {
+		if l.fresh(p) {
+			addrs = append(addrs, addr)
+		}
+	}
+	sort.Strings(addrs)
+
+	return addrs
+}
+
+// list - все найденные пиры, для GetLocalPeers
+func (l *localDiscovery) list(hash string) []*api.LocalPeers_Peer {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	var res []*api.LocalPeers_Peer
+	for h, peers := range l.found {
+		if hash != "" && h != hash {
+			continue
+		}
+
+		for addr, p := range peers {
+			if l.fresh(p) {
+				res = append(res, &api.LocalPeers_Peer{Hash: h, Address: addr, PeerId: p.peerID})
+			}
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Hash != res[j].Hash {
+			return res[i].Hash < res[j].Hash
+		}
+		return res[i].Address < res[j].Address
+	})
+
+	return res
+}
+
+func (l *localDiscovery) fresh(p *localPeer) bool {
+	return time.Since(p.seen) < 3*l.interval
+}
+
+// run слушает группу и рассылает объявления, пока не отменят ctx
+func (l *localDiscovery) run(ctx context.Context) error {
+	conn, err := net.ListenMulticastUDP("udp4", nil, l.group)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	go l.listen(ctx, conn)
+
+	if l.silent {
+		logger.GetLogger(ctx).WithField("address", l.addr).
+			Warn("peer listens on loopback only, its files are not announced to the local network")
+	}
+
+	ticker := time.NewTicker(l.interval)
+	defer ticker.Stop()
+
+	for {
+		l.announce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			l.expire()
+		case <-l.wake:
+		}
+	}
+}
+
+func (l *localDiscovery) announce(ctx context.Context) {
+	if l.silent {
+		return
+	}
+
+	l.mutex.Lock()
+	hashes := make([]string, 0, len(l.provided))
+	for hash := range l.provided {
+		hashes = append(hashes, hash)
+	}
+	l.mutex.Unlock()
+
+	if len(hashes) == 0 {
+		return
+	}
+
+	sort.Strings(hashes)
+
+	conn, err := net.DialUDP("udp4", nil, l.group)
+	if err != nil {
+		logger.GetLogger(ctx).WithError(err).Error("cannot send local announce")
+		return
+	}
+	defer conn.Close()
+
+	for len(hashes) > 0 {
+		n := len(hashes)
+		if n > lsdMaxHashes {
+			n = lsdMaxHashes
+		}
+
+		msg, err := json.Marshal(&lsdAnnounce{Proto: lsdProto, PeerID: l.peerID, Address: l.addr, Hashes: hashes[:n]})
+		if err != nil {
+			return
+		}
+
+		if _, err := conn.Write(msg); err != nil {
+			logger.GetLogger(ctx).WithError(err).Error("cannot send local announce")
+			return
+		}
+
+		hashes = hashes[n:]
+	}
+}
+
+func (l *localDiscovery) listen(ctx context.Context, conn *net.UDPConn) {
+	buf := make([]byte, 64*1024)
+
+	for {
+		n, from, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.GetLogger(ctx).WithError(err).Error("local discovery stopped listening")
+			}
+			return
+		}
+
+		var msg lsdAnnounce
+		if err := json.Unmarshal(buf[:n], &msg); err != nil || msg.Proto != lsdProto || msg.PeerID == l.peerID {
+			continue
+		}
+
+		if !reachableAddr(msg.Address) {
+			logger.GetLogger(ctx).WithField("from", from.String()).WithField("address", msg.Address).
+				Debug("skip local announce with an unreachable address")
+			continue
+		}
+
+		l.record(msg.Address, &msg)
+	}
+}
+
+// reachableAddr - адрес из объявления, по которому к пиру можно подключиться из сети
+func reachableAddr(addr string) bool {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || host == "" {
+		return false
+	}
+
+	if p, err := strconv.ParseUint(port, 10, 16); err != nil || p == 0 {
+		return false
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return !strings.EqualFold(host, "localhost")
+	}
+
+	return !ip.IsLoopback() && !ip.IsUnspecified() && !ip.IsMulticast()
+}
+
+// loopbackOnly - хост слушающего или объявленного адреса ведет только на эту машину;
+// имя считается loopback, если все его адреса loopback
+func loopbackOnly(host string) bool {
+	if host == "" {
+		return false
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.IsLoopback()
+	}
+
+	ips, err := net.LookupIP(host)
+	if err != nil || len(ips) == 0 {
+		return strings.EqualFold(host, "localhost")
+	}
+
+	for _, ip := range ips {
+		if !ip.IsLoopback() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// record запоминает пира по всем хэшам из объявления
+func (l *localDiscovery) record(addr string, msg *lsdAnnounce) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	hashes := msg.Hashes
+	if len(hashes) > lsdMaxHashes {
+		hashes = hashes[:lsdMaxHashes]
+	}
+
+	for _, hash := range hashes {
+		if _, err := hex.DecodeString(hash); err != nil || hash == "" || len(hash) > maxHashLen {
+			continue
+		}
+
+		peers, ok := l.found[hash]
+		if !ok {
+			if len(l.found) >= lsdMaxFiles {
+				continue
+			}
+
+			peers = make(map[string]*localPeer)
+			l.found[hash] = peers
+		}
+
+		if _, ok := peers[addr]; !ok && len(peers) >= lsdKeep {
+			continue
+		}
+
+		peers[addr] = &localPeer{peerID: msg.PeerID, seen: time.Now()}
+	}
+}
+
+// expire забывает пиров, которые давно не объявлялись
+func (l *localDiscovery) expire() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	for hash, peers := range l.found {
+		for addr, p := range peers {
+			if !l.fresh(p) {
+				delete(peers, addr)
+			}
+		}
+
+		if len(peers) == 0 {
+			delete(l.found, hash)
+		}
+	}
+}
+
+// GetLocalPeers отдает пиров, найденных в локальной сети
+func (p *Peer) GetLocalPeers(ctx context.Context, req *api.LocalPeersRequest) (*api.LocalPeers, error) {
+	resp := &api.LocalPeers{}
+	if p.local != nil {
+		resp.Peers = p.local.list(req.Hash)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+const testGroup = "239.192.71.84:6790"
+
+func TestLocalDiscoverySilent(t *testing.T) {
+	tests := []struct {
+		name   string
+		addr   string
+		listen string
+		silent bool
+	}{
+		{name: "default localhost", addr: "localhost:9002", listen: "localhost:9002", silent: true},
+		{name: "loopback ip", addr: "127.0.0.1:9002", listen: "127.0.0.1:9002", silent: true},
+		{name: "ipv6 loopback", addr: "[::1]:9002", listen: "[::1]:9002", silent: true},
+		{name: "lan address", addr: "192.168.1.5:9002", listen: "192.168.1.5:9002"},
+		{name: "all interfaces", addr: "192.168.1.5:9002", listen: "0.0.0.0:9002"},
+		{name: "all interfaces by default", addr: "192.168.1.5:9002", listen: ":9002"},
+		// пир слушает всю сеть, но сообщает адрес, по которому его найдут только с этой машины
+		{name: "loopback host", addr: "localhost:9002", listen: "0.0.0.0:9002", silent: true},
+	}
+
+	for _, tt := range tests {
+		l, err := newLocalDiscovery(testGroup, time.Minute, "me", tt.addr, tt.listen)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		if l.silent != tt.silent {
+			t.Errorf("%s: silent = %v, want %v", tt.name, l.silent, tt.silent)
+		}
+	}
+}
+
+func TestReachableAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{addr: "192.168.1.5:9002", want: true},
+		{addr: "[fe80::1]:9002", want: true},
+		{addr: "peer.lan:9002", want: true},
+		{addr: "127.0.0.1:9002"},
+		{addr: "[::1]:9002"},
+		{addr: "localhost:9002"},
+		{addr: "0.0.0.0:9002"},
+		{addr: "239.192.71.84:9002"},
+		{addr: ":9002"},
+		{addr: "192.168.1.5:0"},
+		{addr: "192.168.1.5:70000"},
+		{addr: "192.168.1.5"},
+		{addr: ""},
+	}
+
+	for _, tt := range tests {
+		if got := reachableAddr(tt.addr); got != tt.want {
+			t.Errorf("reachableAddr(%q) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestLocalDiscoveryRecord(t *testing.T) {
+	l, err := newLocalDiscovery(testGroup, time.Minute, "me", "192.168.1.5:9002", "0.0.0.0:9002")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// пир записывается под объявленным адресом, а не под ip отправителя пакета
+	l.record("192.168.1.7:9003", &lsdAnnounce{PeerID: "other", Address: "192.168.1.7:9003", Hashes: []string{testHash, "not hex"}})
+
+	if got := l.peers(testHash); len(got) != 1 || got[0] != "192.168.1.7:9003" {
+		t.Errorf("peers = %v", got)
+	}
+
+	list := l.list("")
+	if len(list) != 1 || list[0].Hash != testHash || list[0].PeerId != "other" {
+		t.Errorf("list = %v", list)
+	}
+
+	// давно не объявлявшийся пир забывается
+	l.found[testHash]["192.168.1.7:9003"].seen = time.Now().Add(-3 * time.Minute)
+	l.expire()
+
+	if len(l.found) != 0 {
+		t.Errorf("found = %v after expiry", l.found)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// magnet-ссылка на файл: magnet:?xt=urn:md5:<хэш>&dn=<имя>&xl=<длина>&tr=<трекер>&x.pe=<пир>.
+// Для файлов из торрент-файлов вместо urn:md5 - urn:btih с infohash.
+// Параметров tr и x.pe может быть несколько, обязателен только xt.
+type magnet struct {
+	hash     string
+	name     string
+	length   uint64   // 0 - длина не указана
+	trackers []string // адреса трекеров
+	peers    []string // адреса пиров, у которых можно качать напрямую
+}
+
+const (
+	magnetHashPrefix     = "urn:md5:"
+	magnetInfohashPrefix = "urn:btih:"
+
+	md5HexLen      = 32
+	infohashHexLen = 40
+)
+
+func (m *magnet) String() string {
+	// порядок параметров фиксирован, чтобы ссылка на один файл не менялась
+	prefix := magnetHashPrefix
+	if len(m.hash) == infohashHexLen {
+		prefix = magnetInfohashPrefix
+	}
+
+	parts := []string{"xt=" + prefix + m.hash}
+
+	if m.name != "" {
+		parts = append(parts, "dn="+url.QueryEscape(m.name))
+	}
+	if m.length > 0 {
+		parts = append(parts, "xl="+strconv.FormatUint(m.length, 10))
+	}
+	for _, t := range m.trackers {
+		parts = append(parts, "tr="+url.QueryEscape(t))
+	}
+	for _, p := range m.peers {
+		parts = append(parts, "x.pe="+url.QueryEscape(p))
+	}
+
+	return "magnet:?" + strings.Join(parts, "&")
+}
+
+func parseMagnet(uri string) (*magnet, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Scheme != "magnet" {
+		return nil, errors.New("not a magnet uri")
+	}
+
+	q, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	xt := q.Get("xt")
+
+	var hash string
+	size := md5HexLen
+	switch {
+	case strings.HasPrefix(xt, magnetHashPrefix):
+		hash = strings.TrimPrefix(xt, magnetHashPrefix)
+	case strings.HasPrefix(xt, magnetInfohashPrefix):
+		hash = strings.TrimPrefix(xt, magnetInfohashPrefix)
+		size = infohashHexLen
+	default:
+		return nil, errors.New("magnet uri has no md5 hash or infohash")
+	}
+
+	m := &magnet{
+		hash:     strings.ToLower(hash),
+		name:     q.Get("dn"),
+		trackers: q["tr"],
+		peers:    q["x.pe"],
+	}
+
+	if _, err := hex.DecodeString(m.hash); err != nil || len(m.hash) != size {
+		return nil, errors.New("invalid hash in magnet uri")
+	}
+
+	if xl := q.Get("xl"); xl != "" {
+		m.length, err = strconv.ParseUint(xl, 10, 64)
+		if err != nil {
+			return nil, errors.New("invalid length in magnet uri")
+		}
+	}
+
+	return m, nil
+}
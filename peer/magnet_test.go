@@ -0,0 +1,115 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testHash = "9702842ac5824617babda6a32791ac2f"
+
+func TestMagnetRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		m    *magnet
+		uri  string
+	}{
+		{
+			name: "hash only",
+			m:    &magnet{hash: testHash},
+			uri:  "magnet:?xt=urn:md5:" + testHash,
+		},
+		{
+			name: "all fields",
+			m: &magnet{
+				hash:     testHash,
+				name:     "some file&.txt",
+				length:   24,
+				trackers: []string{"localhost:9000", "http://tracker:8000/announce"},
+				peers:    []string{"localhost:9002", "[::1]:9003"},
+			},
+			uri: "magnet:?xt=urn:md5:" + testHash + "&dn=some+file%26.txt&xl=24" +
+				"&tr=localhost%3A9000&tr=http%3A%2F%2Ftracker%3A8000%2Fannounce" +
+				"&x.pe=localhost%3A9002&x.pe=%5B%3A%3A1%5D%3A9003",
+		},
+		{
+			name: "infohash",
+			m:    &magnet{hash: strings.Repeat("ab", 20), name: "data.bin"},
+			uri:  "magnet:?xt=urn:btih:" + strings.Repeat("ab", 20) + "&dn=data.bin",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.m.String(); got != tt.uri {
+				t.Errorf("String = %q, want %q", got, tt.uri)
+			}
+
+			got, err := parseMagnet(tt.uri)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.m) {
+				t.Errorf("parseMagnet = %+v, want %+v", got, tt.m)
+			}
+		})
+	}
+}
+
+func TestParseMagnet(t *testing.T) {
+	tests := []struct {
+		name string
+		uri  string
+		want *magnet
+	}{
+		{
+			name: "upper case hash",
+			uri:  "magnet:?xt=urn:md5:" + strings.ToUpper(testHash),
+			want: &magnet{hash: testHash},
+		},
+		{
+			name: "any parameter order, unknown parameters",
+			uri:  "magnet:?x.pe=a:1&foo=bar&xt=urn:md5:" + testHash + "&tr=b:2",
+			want: &magnet{hash: testHash, trackers: []string{"b:2"}, peers: []string{"a:1"}},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := parseMagnet(tt.uri)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: parseMagnet = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseMagnetMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		uri  string
+	}{
+		{name: "empty", uri: ""},
+		{name: "http", uri: "http://localhost/?xt=urn:md5:" + testHash},
+		{name: "no xt", uri: "magnet:?dn=some.txt"},
+		{name: "other urn", uri: "magnet:?xt=urn:sha1:" + testHash},
+		{name: "short hash", uri: "magnet:?xt=urn:md5:" + testHash[:30]},
+		{name: "long hash", uri: "magnet:?xt=urn:md5:" + testHash + "00"},
+		{name: "not hex", uri: "magnet:?xt=urn:md5:" + strings.Repeat("zz", 16)},
+		{name: "md5 as infohash", uri: "magnet:?xt=urn:btih:" + testHash},
+		{name: "infohash as md5", uri: "magnet:?xt=urn:md5:" + strings.Repeat("ab", 20)},
+		{name: "bad length", uri: "magnet:?xt=urn:md5:" + testHash + "&xl=-1"},
+		{name: "length overflow", uri: "magnet:?xt=urn:md5:" + testHash + "&xl=99999999999999999999"},
+		{name: "bad escape", uri: "magnet:?xt=urn:md5:" + testHash + "&dn=%zz"},
+		{name: "broken uri", uri: "magnet:%zz"},
+	}
+
+	for _, tt := range tests {
+		if m, err := parseMagnet(tt.uri); err == nil {
+			t.Errorf("%s: parseMagnet(%q) = %+v, want error", tt.name, tt.uri, m)
+		}
+	}
+}
@@ -1,11 +1,19 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/elizarpif/grpctorrent/api"
+	"github.com/elizarpif/grpctorrent/api/tlsconfig"
 	"github.com/elizarpif/logger"
 	"github.com/grpc-ecosystem/grpc-gateway/runtime"
 
@@ -15,56 +23,170 @@ import (
 
 const (
 	trackerAddr     = "localhost:9000"
+	defaultHost     = "localhost"
 	defaultGrpcPort = "9001"
 	defaultHttpPort = "8000"
 )
 
-func getAddress() (grpcAddr, httpAddr string) {
+var (
+	pickerName = flag.String("picker", "sequential", "piece selection strategy: sequential or rarest")
+	stateDir   = flag.String("state", "", "directory for the peer identity, defaults to a per-port directory in the user config dir")
+
+	shareDirs    = flag.String("share", ".", "comma-separated directories the peer may upload files from")
+	shareInclude = flag.String("share-include", "", "comma-separated globs, if set only matching files are shared")
+	shareExclude = flag.String("share-exclude", "", "comma-separated globs of files that are never shared")
+
+	controlAddr = flag.String("control", "", "control api address: unix:<path> or a loopback host:port, "+
+		"defaults to a unix socket in the state dir")
+	controlToken = flag.String("control-token", "", "bearer token for the control api, defaults to one stored in the state dir")
+
+	trustedPublishers = flag.String("trusted-publishers", "", "comma-separated base64 publisher keys; "+
+		"if set, only files signed by them are downloaded")
+
+	dhtBootstrap = flag.String("dht-bootstrap", "", "comma-separated grpc addresses of peers to join the dht through")
+
+	// своя группа, а не группа BEP 14: ее слушают обычные BitTorrent-клиенты, а формат объявлений другой
+	lsdGroup    = flag.String("lsd-group", "239.192.71.84:6790", "multicast group for local peer discovery, empty disables it")
+	lsdInterval = flag.Duration("lsd-interval", time.Minute, "how often to announce files to the local network")
+
+	peersFile = flag.String("peers-file", "", "file with grpc addresses of known peers, one per line; "+
+		"files are looked up on them when the tracker is unavailable")
+
+	tlsConfig = &tlsconfig.Config{}
+)
+
+func init() {
+	tlsConfig.RegisterFlags(flag.CommandLine)
+}
+
+// getAddress возвращает адреса, на которых слушают grpc и http, и grpc-адрес,
+// который пир сообщает трекеру и другим пирам
+func getAddress() (grpcListen, httpListen, grpcAddr string, err error) {
 	peerPort := flag.String("grpc", defaultGrpcPort, "port for grpc address")
 
 	httpPort := flag.String("http", defaultHttpPort, "port for http address")
+
+	listenHost := flag.String("listen", defaultHost, "host to listen on for grpc and http, 0.0.0.0 or :: for all interfaces")
+	publicHost := flag.String("host", "", "host other peers reach this peer at, defaults to the listen host")
 	flag.Parse()
 
-	grpcAddr = net.JoinHostPort("localhost", func() string {
-		if peerPort == nil {
-			return defaultGrpcPort
+	host := *publicHost
+	if host == "" {
+		// на всех интерфейсах адрес для других пиров угадать нельзя
+		if ip := net.ParseIP(*listenHost); *listenHost == "" || (ip != nil && ip.IsUnspecified()) {
+			return "", "", "", errors.New("-host is required when listening on all interfaces")
 		}
-		return *peerPort
-	}())
 
-	httpAddr = net.JoinHostPort("localhost", func() string {
-		if httpPort == nil {
-			return defaultHttpPort
-		}
-		return *httpPort
-	}())
+		host = *listenHost
+	}
+
+	grpcListen = net.JoinHostPort(*listenHost, *peerPort)
+	httpListen = net.JoinHostPort(*listenHost, *httpPort)
+	grpcAddr = net.JoinHostPort(host, *peerPort)
 
-	return grpcAddr, httpAddr
+	return grpcListen, httpListen, grpcAddr, nil
 }
 
 func main() {
 	log := logger.NewLogger()
-	grpcAddr, httpAddr := getAddress()
+	grpcListen, httpAddr, grpcAddr, err := getAddress()
+	if err != nil {
+		log.WithError(err).Fatal("invalid address")
+	}
+
+	lis, err := net.Listen("tcp", grpcListen)
+	if err != nil {
+		log.WithError(err).WithField("address", grpcListen).Fatal("listen for grpc")
+	}
+
+	var serverOpts []grpc.ServerOption
+
+	creds, err := tlsConfig.ServerOption()
+	if err != nil {
+		log.WithError(err).Fatal("invalid tls config")
+	}
+	if creds != nil {
+		serverOpts = append(serverOpts, creds)
+	}
 
-	lis, err := net.Listen("tcp", grpcAddr)
+	// этой опцией пир ходит к трекеру, другим пирам и в свой grpc-сервер из шлюза
+	dialOpt, err := tlsConfig.DialOption()
 	if err != nil {
-		log.WithError(err).WithField("address", grpcAddr).Fatal("listen for grpc")
+		log.WithError(err).Fatal("invalid tls config")
 	}
 
-	grpcServer := grpc.NewServer()
+	grpcServer := grpc.NewServer(serverOpts...)
 	defer grpcServer.GracefulStop()
 
-	ctx := logger.SetContext(log)
+	ctx, cancel := context.WithCancel(logger.SetContext(log))
+	defer cancel()
+
+	dir := *stateDir
+	if dir == "" {
+		dir = defaultStateDir(grpcAddr)
+	}
+
+	ident, err := loadIdentity(dir)
+	if err != nil {
+		log.WithError(err).WithField("state_dir", dir).Fatal("cannot load peer identity")
+	}
+
+	log.WithField("peer_id", ident.id.String()).WithField("state_dir", dir).Info("peer identity")
+
+	token, err := loadControlToken(*controlToken, dir)
+	if err != nil {
+		log.WithError(err).Fatal("cannot load control token")
+	}
+
+	controlLis, controlTarget, err := listenControl(*controlAddr, dir)
+	if err != nil {
+		log.WithError(err).Fatal("listen for control api")
+	}
+
+	controlServer := grpc.NewServer(grpc.UnaryInterceptor(controlAuth(token)))
+	defer controlServer.GracefulStop()
 
-	server, err := NewPeer(ctx, trackerAddr, grpcAddr)
+	trusted, err := parsePublishers(splitList(*trustedPublishers))
+	if err != nil {
+		log.WithError(err).Fatal("invalid trusted publishers")
+	}
+
+	share, err := newShareRoots(splitList(*shareDirs), splitList(*shareInclude), splitList(*shareExclude))
+	if err != nil {
+		log.WithError(err).Fatal("invalid share roots")
+	}
+
+	bootstrap, err := loadPeersFile(*peersFile)
+	if err != nil {
+		log.WithError(err).WithField("peers_file", *peersFile).Fatal("cannot load peers file")
+	}
+
+	server, err := NewPeer(ctx, &config{
+		trackerAddr: trackerAddr,
+		addr:        grpcAddr,
+		listen:      grpcListen,
+		picker:      *pickerName,
+		ident:       ident,
+		transport:   dialOpt,
+		share:       share,
+		trusted:     trusted,
+		lsdGroup:    *lsdGroup,
+		lsdInterval: *lsdInterval,
+		bootstrap:   bootstrap,
+	})
 	if err != nil {
 		log.WithError(err).Fatal("cannot create peer")
 	}
 
-	api.RegisterPeerServer(grpcServer, server)
+	// другим пирам видны GetPiece, GetIdentity, описание публичных файлов и DHT,
+	// управление - отдельно и локально
+	api.RegisterPeerServer(grpcServer, &publicPeer{server})
+	api.RegisterDHTServer(grpcServer, server.dht)
+	api.RegisterControlServer(controlServer, server)
 
+	// шлюз отдает только API управления, токен он передает из заголовка Authorization
 	mux := runtime.NewServeMux()
-	err = api.RegisterPeerHandlerFromEndpoint(ctx, mux, grpcAddr, []grpc.DialOption{grpc.WithInsecure()})
+	err = api.RegisterControlHandlerFromEndpoint(ctx, mux, controlTarget, []grpc.DialOption{grpc.WithInsecure()})
 	if err != nil {
 		log.WithError(err).Fatal("cannot register")
 	}
@@ -74,19 +196,82 @@ func main() {
 		Handler: mux,
 	}
 
+	if tlsConfig.Enabled() {
+		srv.TLSConfig, err = tlsConfig.Server()
+		if err != nil {
+			log.WithError(err).Fatal("invalid tls config")
+		}
+	}
+
 	group := errgroup.Group{}
+
+	// по SIGINT и SIGTERM пир закрывает серверы и отменяет ctx: фоновые задачи
+	// завершаются, а очередь отчетов о кусках последний раз уходит трекеру
+	group.Go(func() error {
+		stop := make(chan os.Signal, 1)
+		signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+
+		sig := <-stop
+		log.WithField("signal", sig.String()).Info("stop peer")
+
+		cancel()
+		grpcServer.Stop()
+		controlServer.Stop()
+		return srv.Close()
+	})
+
 	group.Go(func() error {
-		log.WithField("grpc_address", grpcAddr).Info("start grpc server")
+		log.WithField("grpc_address", grpcListen).WithField("public_address", grpcAddr).Info("start grpc server")
 		return grpcServer.Serve(lis)
 	})
 
 	group.Go(func() error {
-		log.WithField("http_address", httpAddr).Info("start http server")
+		log.WithField("control_address", controlTarget).Info("start control server")
+		return controlServer.Serve(controlLis)
+	})
+
+	group.Go(func() error {
+		return server.reports.run(ctx)
+	})
+
+	group.Go(func() error {
+		return server.dht.Run(ctx, append(splitList(*dhtBootstrap), bootstrap...))
+	})
+
+	if server.local != nil {
+		// без multicast в сети пир работает и так, поэтому ошибка не останавливает его
+		group.Go(func() error {
+			err := server.local.run(ctx)
+			if err != nil && ctx.Err() == nil {
+				log.WithError(err).WithField("group", *lsdGroup).Error("local peer discovery stopped")
+			}
+			return nil
+		})
+	}
+
+	group.Go(func() error {
+		log.WithField("http_address", httpAddr).WithField("tls", srv.TLSConfig != nil).Info("start http server")
+		if srv.TLSConfig != nil {
+			return srv.ListenAndServeTLS("", "")
+		}
 		return srv.ListenAndServe()
 	})
 
 	err = group.Wait()
-	if err != nil {
+	if err != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, http.ErrServerClosed) {
 		log.WithError(err).Fatal("group wait")
 	}
 }
+
+// splitList разбирает значение флага со списком через запятую
+func splitList(value string) []string {
+	var list []string
+	for _, v := range strings.Split(value, ",") {
+		v = strings.TrimSpace(v)
+		if v != "" {
+			list = append(list, v)
+		}
+	}
+
+	return list
+}
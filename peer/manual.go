@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/elizarpif/grpctorrent/api"
+	"github.com/elizarpif/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const manualTimeout = 5 * time.Second // сколько ждать описание файла от одного пира
+
+// publicPeer - сервис Peer для других пиров. GetFileInfo у него по хэшу
+// и только для публичных файлов, в отличие от одноименного метода управления.
+type publicPeer struct {
+	*Peer
+}
+
+// GetFileInfo отдает описание файла, чтобы его скачали без трекера
+func (p *publicPeer) GetFileInfo(ctx context.Context, req *api.DownloadFileRequest) (*api.FileInfo, error) {
+	file, ok := p.files.getByHash(req.Hash)
+	if !ok {
+		return nil, status.Error(codes.NotFound, "cannot find file")
+	}
+
+	// состав и описание приватного роя знает только трекер
+	if file.isPrivate() {
+		return nil, status.Error(codes.PermissionDenied, "file is private")
+	}
+
+	return file.info(), nil
+}
+
+// loadPeersFile читает адреса пиров, по одному на строку; пустые строки и # комментарии пропускаются
+func loadPeersFile(name string) ([]string, error) {
+	if name == "" {
+		return nil, nil
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var peers []string
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+
+		line = strings.TrimSpace(line)
+		if line != "" && !contains(peers, line) {
+			peers = append(peers, line)
+		}
+	}
+
+	return peers, scanner.Err()
+}
+
+// uniquePeers объединяет списки адресов пиров без повторов, сохраняя порядок:
+// пир, указанный и в запросе, и в файле настроек, опрашивается один раз
+func uniquePeers(lists ...[]string) []string {
+	res := []string{}
+	for _, list := range lists {
+		for _, addr := range list {
+			if !contains(res, addr) {
+				res = append(res, addr)
+			}
+		}
+	}
+
+	return res
+}
+
+// fileInfoFrom спрашивает описание файла у одного пира
+func (p *Peer) fileInfoFrom(ctx context.Context, addr, hash string) (*api.FileInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, manualTimeout)
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, addr, p.transport)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	info, err := api.NewPeerClient(conn).GetFileInfo(ctx, &api.DownloadFileRequest{Hash: hash})
+	if err != nil {
+		return nil, err
+	}
+
+	return info, checkRemoteInfo(info, hash)
+}
+
+// infoFromPeers берет описание файла у первого пира, который его отдал
+func (p *Peer) infoFromPeers(ctx context.Context, hash string, peers []string) (*api.FileInfo, error) {
+	for _, addr := range peers {
+		if addr == p.addr {
+			continue
+		}
+
+		info, err := p.fileInfoFrom(ctx, addr, hash)
+		if err != nil {
+			logger.GetLogger(ctx).WithError(err).WithField("remote_peer", addr).Debug("no file info from peer")
+			continue
+		}
+
+		return info, nil
+	}
+
+	return nil, errors.New("no peer has the file")
+}
+
+// holders - те из пиров, у кого есть файл с таким описанием
+func (p *Peer) holders(ctx context.Context, info *api.FileInfo, peers []string) []string {
+	var (
+		res   []string
+		mutex = &sync.Mutex{}
+		wg    = &sync.WaitGroup{}
+	)
+
+	for _, addr := range peers {
+		if addr == p.addr {
+			continue
+		}
+
+		wg.Add(1)
+		go func(addr string) {
+			defer wg.Done()
+
+			got, err := p.fileInfoFrom(ctx, addr, info.Hash)
+			if err != nil || got.Length != info.Length || got.PieceLength != info.PieceLength {
+				return
+			}
+
+			mutex.Lock()
+			res = append(res, addr)
+			mutex.Unlock()
+		}(addr)
+	}
+
+	wg.Wait()
+
+	return res
+}
+
+// checkRemoteInfo проверяет описание, присланное пиром или чужим трекером: из него
+// создается файл на диске и по хэшам кусков сверяется скачанное
+func checkRemoteInfo(info *api.FileInfo, hash string) error {
+	if info.Hash != hash {
+		return errors.New("hash doesnt match file description")
+	}
+
+	if info.Name == "" || info.Name != filepath.Base(info.Name) || info.Name == "." || info.Name == ".." {
+		return errors.New("invalid file name")
+	}
+
+	if info.PieceLength == 0 || info.Pieces != pieceCount(info.Length, info.PieceLength) {
+		return errors.New("pieces dont match file length")
+	}
+
+	if uint64(len(info.PieceHashes)) != info.Pieces {
+		return errors.New("piece hashes dont match file length")
+	}
+
+	return nil
+}
+
+// pieceCount - сколько кусков длины pieceLength в файле, без переполнения на длинах около 2^64
+func pieceCount(length, pieceLength uint64) uint64 {
+	n := length / pieceLength
+	if length%pieceLength != 0 {
+		n++
+	}
+
+	return n
+}
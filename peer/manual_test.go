@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/elizarpif/grpctorrent/api"
+	"google.golang.org/grpc"
+)
+
+// startRemotePeer запускает публичный сервис пира, у которого есть или нет публичного файла publicHash;
+// счетчик - сколько раз к нему обращались
+func startRemotePeer(t *testing.T, hasFile bool) (string, *int32) {
+	t.Helper()
+
+	calls := new(int32)
+	server := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req interface{},
+		info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		atomic.AddInt32(calls, 1)
+		return handler(ctx, req)
+	}))
+
+	remote := newControlPeer(t)
+	if hasFile {
+		remote.files.add(newEmptyFile(&api.FileInfo{
+			Name: "a.txt", Hash: publicHash, Length: 2, PieceLength: 1, Pieces: 2, PieceHashes: [][]byte{{1}, {2}},
+		}))
+	}
+	api.RegisterPeerServer(server, &publicPeer{remote})
+
+	conn := serveGRPC(t, server)
+
+	return conn.Target(), calls
+}
+
+func newManualPeer() *Peer {
+	return &Peer{addr: seederAddr, files: newRegistry(), transport: grpc.WithInsecure()}
+}
+
+func TestInfoFromPeers(t *testing.T) {
+	empty, emptyCalls := startRemotePeer(t, false)
+	holder, holderCalls := startRemotePeer(t, true)
+
+	p := newManualPeer()
+
+	// пиры из запроса и из файла настроек пересекаются, среди них и сам пир
+	manual := []string{empty, empty, seederAddr}
+	bootstrap := []string{empty, holder, holder}
+
+	info, err := p.infoFromPeers(context.Background(), publicHash, uniquePeers(manual, bootstrap))
+	if err != nil || info.Hash != publicHash {
+		t.Fatalf("infoFromPeers = %v, %v", info, err)
+	}
+
+	if *emptyCalls != 1 || *holderCalls != 1 {
+		t.Errorf("peers asked %d and %d times, want once each", *emptyCalls, *holderCalls)
+	}
+
+	if _, err := p.infoFromPeers(context.Background(), privateHash, []string{empty, seederAddr}); err == nil {
+		t.Error("info for a file no peer has")
+	}
+}
+
+func TestHolders(t *testing.T) {
+	empty, emptyCalls := startRemotePeer(t, false)
+	holder, holderCalls := startRemotePeer(t, true)
+
+	p := newManualPeer()
+	info := &api.FileInfo{Hash: publicHash, Length: 2, PieceLength: 1}
+
+	// источниками становятся только пиры из файла настроек, у которых есть файл
+	got := p.holders(context.Background(), info, []string{empty, holder, seederAddr})
+	if !reflect.DeepEqual(got, []string{holder}) {
+		t.Errorf("holders = %v, want %v", got, []string{holder})
+	}
+	if *emptyCalls != 1 || *holderCalls != 1 {
+		t.Errorf("peers asked %d and %d times, want once each", *emptyCalls, *holderCalls)
+	}
+
+	// у пира другой файл с тем же хэшем
+	info.Length = 3
+	if got := p.holders(context.Background(), info, []string{holder}); len(got) != 0 {
+		t.Errorf("holders with another length = %v", got)
+	}
+}
+
+func TestUniquePeers(t *testing.T) {
+	tests := []struct {
+		lists [][]string
+		want  []string
+	}{
+		{want: []string{}},
+		{lists: [][]string{{"a:1", "b:1", "a:1"}}, want: []string{"a:1", "b:1"}},
+		{lists: [][]string{{"a:1"}, nil, {"b:1", "a:1"}, {"c:1"}}, want: []string{"a:1", "b:1", "c:1"}},
+	}
+
+	for _, tt := range tests {
+		if got := uniquePeers(tt.lists...); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("uniquePeers(%v) = %v, want %v", tt.lists, got, tt.want)
+		}
+	}
+
+	// списки-аргументы не меняются
+	manual := make([]string, 1, 4)
+	manual[0] = "a:1"
+	uniquePeers(manual, []string{"b:1"})
+	if got := manual[:2]; got[1] != "" {
+		t.Errorf("manual peers changed: %v", got)
+	}
+}
+
+func TestLoadPeersFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "peers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, "peers")
+	data := "# known peers\n" +
+		"localhost:9002\n" +
+		"\n" +
+		"  192.168.1.5:9002  # lan\n" +
+		"localhost:9002\n" +
+		"   # commented\n" +
+		"[::1]:9003"
+
+	if err := ioutil.WriteFile(name, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	peers, err := loadPeersFile(name)
+	if want := []string{"localhost:9002", "192.168.1.5:9002", "[::1]:9003"}; err != nil || !reflect.DeepEqual(peers, want) {
+		t.Errorf("loadPeersFile = %v, %v, want %v", peers, err, want)
+	}
+
+	if peers, err := loadPeersFile(""); peers != nil || err != nil {
+		t.Errorf("without a file: %v, %v", peers, err)
+	}
+
+	if _, err := loadPeersFile(filepath.Join(dir, "missing")); err == nil {
+		t.Error("missing peers file is not reported")
+	}
+}
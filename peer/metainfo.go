@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+
+	"github.com/elizarpif/grpctorrent/api"
+	"github.com/elizarpif/grpctorrent/api/bencode"
+)
+
+// хэш кусков в метаинфо: sha256, в отличие от sha1 у торрент-файлов BitTorrent
+const pieceHashSHA256 = "sha256"
+
+// encodeMetainfo собирает bencoded описание файла: словарь info с именем, размерами,
+// хэшами кусков и md5 файла, адреса трекеров и подпись издателя
+func encodeMetainfo(info *api.FileInfo, trackers []string) ([]byte, error) {
+	if uint64(len(info.PieceHashes)) != info.Pieces {
+		return nil, errors.New("file has no piece hashes")
+	}
+
+	// файлы из торрент-файлов BitTorrent описывает сам торрент-файл
+	if info.PieceHash != api.PieceHash_SHA256 {
+		return nil, errors.New("metainfo is only for sha256 pieces")
+	}
+
+	infoDict := map[string]interface{}{
+		"name":         info.Name,
+		"length":       info.Length,
+		"piece length": info.PieceLength,
+		"pieces":       bytes.Join(info.PieceHashes, nil),
+		"piece hash":   pieceHashSHA256,
+		"md5sum":       info.Hash,
+	}
+
+	if info.Private {
+		infoDict["private"] = 1
+	}
+
+	doc := map[string]interface{}{
+		"info":       infoDict,
+		"created by": "grpctorrent",
+	}
+
+	if len(trackers) > 0 {
+		doc["announce"] = trackers[0]
+		doc["announce-list"] = []interface{}{trackers}
+	}
+
+	if len(info.Signature) > 0 {
+		doc["publisher key"] = []byte(info.PublisherKey)
+		doc["signature"] = info.Signature
+	}
+
+	return bencode.Marshal(doc)
+}
+
+// decodeMetainfo разбирает описание файла и возвращает его вместе с адресами трекеров
+func decodeMetainfo(data []byte) (*api.FileInfo, []string, error) {
+	v, err := bencode.Unmarshal(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	doc, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, nil, errors.New("metainfo is not a dictionary")
+	}
+
+	infoDict, ok := doc["info"].(map[string]interface{})
+	if !ok {
+		return nil, nil, errors.New("metainfo has no info dictionary")
+	}
+
+	if s, _ := infoDict["piece hash"].(string); s != pieceHashSHA256 {
+		return nil, nil, errors.New("unsupported piece hash")
+	}
+
+	name, _ := infoDict["name"].(string)
+	hash, _ := infoDict["md5sum"].(string)
+	pieces, _ := infoDict["pieces"].(string)
+	length, lok := infoDict["length"].(int64)
+	pieceLength, pok := infoDict["piece length"].(int64)
+
+	if name == "" || hash == "" || !lok || !pok || length < 0 || pieceLength <= 0 {
+		return nil, nil, errors.New("metainfo misses file fields")
+	}
+
+	if len(pieces)%sha256.Size != 0 || uint64(len(pieces)/sha256.Size) != pieceCount(uint64(length), uint64(pieceLength)) {
+		return nil, nil, errors.New("piece hashes dont match file length")
+	}
+
+	info := &api.FileInfo{
+		Name:        name,
+		PieceLength: uint64(pieceLength),
+		Pieces:      uint64(len(pieces) / sha256.Size),
+		Length:      uint64(length),
+		Hash:        hash,
+	}
+
+	if private, _ := infoDict["private"].(int64); private == 1 {
+		info.Private = true
+		info.Visibility = api.Visibility_PRIVATE
+	}
+
+	for i := 0; i < len(pieces); i += sha256.Size {
+		info.PieceHashes = append(info.PieceHashes, []byte(pieces[i:i+sha256.Size]))
+	}
+
+	if key, ok := doc["publisher key"].(string); ok {
+		info.PublisherKey = []byte(key)
+	}
+
+	if sig, ok := doc["signature"].(string); ok {
+		info.Signature = []byte(sig)
+	}
+
+	return info, announceList(doc), nil
+}
+
+// announceList - адреса трекеров из announce-list, а без него из announce
+func announceList(doc map[string]interface{}) []string {
+	var trackers []string
+
+	tiers, _ := doc["announce-list"].([]interface{})
+	for _, tier := range tiers {
+		list, _ := tier.([]interface{})
+		for _, t := range list {
+			if addr, ok := t.(string); ok && addr != "" && !contains(trackers, addr) {
+				trackers = append(trackers, addr)
+			}
+		}
+	}
+
+	if announce, ok := doc["announce"].(string); ok && announce != "" && !contains(trackers, announce) {
+		trackers = append(trackers, announce)
+	}
+
+	return trackers
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+
+	return false
+}
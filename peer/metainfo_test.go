@@ -0,0 +1,249 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/elizarpif/grpctorrent/api"
+	"github.com/elizarpif/grpctorrent/api/bencode"
+)
+
+func testInfo() *api.FileInfo {
+	return &api.FileInfo{
+		Name:         "some.txt",
+		PieceLength:  16,
+		Pieces:       2,
+		Length:       24,
+		Hash:         "9702842ac5824617babda6a32791ac2f",
+		PieceHashes:  [][]byte{bytes.Repeat([]byte{1}, sha256.Size), bytes.Repeat([]byte{2}, sha256.Size)},
+		PieceHash:    api.PieceHash_SHA256,
+		PublisherKey: []byte("publisher key"),
+		Signature:    []byte("signature"),
+	}
+}
+
+func TestMetainfoRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		private  bool
+		trackers []string
+	}{
+		{name: "public", trackers: []string{"localhost:9000"}},
+		{name: "private", private: true, trackers: []string{"localhost:9000", "tracker:9000"}},
+		{name: "no trackers"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := testInfo()
+			if tt.private {
+				info.Private = true
+				info.Visibility = api.Visibility_PRIVATE
+			}
+
+			data, err := encodeMetainfo(info, tt.trackers)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, trackers, err := decodeMetainfo(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(trackers, tt.trackers) {
+				t.Errorf("trackers = %v, want %v", trackers, tt.trackers)
+			}
+
+			// алгоритм кусков в описание не возвращается, он задан форматом
+			got.PieceHash = api.PieceHash_SHA256
+			if !reflect.DeepEqual(got, info) {
+				t.Errorf("decodeMetainfo = %+v, want %+v", got, info)
+			}
+		})
+	}
+}
+
+func TestEncodeMetainfoRejects(t *testing.T) {
+	info := testInfo()
+	info.PieceHashes = info.PieceHashes[:1]
+	if _, err := encodeMetainfo(info, nil); err == nil {
+		t.Error("encoded a file without all piece hashes")
+	}
+
+	info = testInfo()
+	info.PieceHash = api.PieceHash_SHA1
+	if _, err := encodeMetainfo(info, nil); err == nil {
+		t.Error("encoded a file with sha1 pieces")
+	}
+}
+
+// metainfo собирает документ с заменой полей словаря info
+func metainfo(t *testing.T, change map[string]interface{}) []byte {
+	t.Helper()
+
+	info := map[string]interface{}{
+		"name":         "some.txt",
+		"length":       24,
+		"piece length": 16,
+		"pieces":       strings.Repeat("x", 2*sha256.Size),
+		"piece hash":   "sha256",
+		"md5sum":       "9702842ac5824617babda6a32791ac2f",
+	}
+	for k, v := range change {
+		if v == nil {
+			delete(info, k)
+			continue
+		}
+		info[k] = v
+	}
+
+	data, err := bencode.Marshal(map[string]interface{}{"info": info})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+func TestDecodeMetainfoMalformed(t *testing.T) {
+	if _, _, err := decodeMetainfo(metainfo(t, nil)); err != nil {
+		t.Fatalf("valid metainfo rejected: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "not bencode", data: []byte("d4:info")},
+		{name: "not a dictionary", data: []byte("li1ee")},
+		{name: "no info", data: []byte("d8:announce3:urle")},
+		{name: "info is a string", data: []byte("d4:info4:spame")},
+		{name: "sha1 pieces", data: metainfo(t, map[string]interface{}{"piece hash": "sha1"})},
+		{name: "no piece hash", data: metainfo(t, map[string]interface{}{"piece hash": nil})},
+		{name: "no name", data: metainfo(t, map[string]interface{}{"name": nil})},
+		{name: "no md5", data: metainfo(t, map[string]interface{}{"md5sum": nil})},
+		{name: "length is a string", data: metainfo(t, map[string]interface{}{"length": "24"})},
+		{name: "negative length", data: metainfo(t, map[string]interface{}{"length": -1})},
+		{name: "zero piece length", data: metainfo(t, map[string]interface{}{"piece length": 0})},
+		{name: "too few pieces", data: metainfo(t, map[string]interface{}{"length": 40})},
+		{name: "too many pieces", data: metainfo(t, map[string]interface{}{"length": 8})},
+		{name: "cut piece hash", data: metainfo(t, map[string]interface{}{"pieces": strings.Repeat("x", 2*sha256.Size-1)})},
+	}
+
+	for _, tt := range tests {
+		if info, _, err := decodeMetainfo(tt.data); err == nil {
+			t.Errorf("%s: decodeMetainfo = %+v, want error", tt.name, info)
+		}
+	}
+}
+
+func TestDecodeMetainfoHugeLength(t *testing.T) {
+	// число кусков считается без переполнения int64
+	data := metainfo(t, map[string]interface{}{
+		"length":       int64(1<<63 - 1),
+		"piece length": int64(1 << 62),
+	})
+
+	info, _, err := decodeMetainfo(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Pieces != 2 {
+		t.Errorf("pieces = %d, want 2", info.Pieces)
+	}
+}
+
+func TestAnnounceList(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want []string
+	}{
+		{name: "none", doc: "de"},
+		{name: "announce only", doc: "d8:announce1:ae", want: []string{"a"}},
+		{name: "tiers in order", doc: "d8:announce1:c13:announce-listll1:a1:bel1:ceee", want: []string{"a", "b", "c"}},
+		{name: "duplicates and junk", doc: "d13:announce-listll1:ai1e0:1:ae3:bade8:announce1:ae", want: []string{"a"}},
+	}
+
+	for _, tt := range tests {
+		v, err := bencode.Unmarshal([]byte(tt.doc))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		got := announceList(v.(map[string]interface{}))
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: announceList = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGrpcTrackers(t *testing.T) {
+	list := []string{
+		"localhost:9000",
+		"tracker:9000",
+		"http://tracker:8000/announce",
+		"udp://tracker:6969",
+		"no-port",
+		"tracker:9000",
+		"[::1]:9000",
+	}
+
+	got := grpcTrackers(list, "localhost:9000")
+	want := []string{"tracker:9000", "[::1]:9000"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("grpcTrackers = %v, want %v", got, want)
+	}
+}
+
+func TestPieceCount(t *testing.T) {
+	tests := []struct {
+		length, pieceLength, want uint64
+	}{
+		{0, 16, 0},
+		{1, 16, 1},
+		{16, 16, 1},
+		{17, 16, 2},
+		{1<<64 - 1, 1 << 63, 2},
+		{1<<64 - 1, 1, 1<<64 - 1},
+	}
+
+	for _, tt := range tests {
+		if got := pieceCount(tt.length, tt.pieceLength); got != tt.want {
+			t.Errorf("pieceCount(%d, %d) = %d, want %d", tt.length, tt.pieceLength, got, tt.want)
+		}
+	}
+}
+
+func TestCheckRemoteInfo(t *testing.T) {
+	hash := testInfo().Hash
+
+	tests := []struct {
+		name   string
+		change func(info *api.FileInfo)
+		ok     bool
+	}{
+		{name: "valid", change: func(*api.FileInfo) {}, ok: true},
+		{name: "other hash", change: func(info *api.FileInfo) { info.Hash = "00" }},
+		{name: "empty name", change: func(info *api.FileInfo) { info.Name = "" }},
+		{name: "path in name", change: func(info *api.FileInfo) { info.Name = "../some.txt" }},
+		{name: "dot dot", change: func(info *api.FileInfo) { info.Name = ".." }},
+		{name: "zero piece length", change: func(info *api.FileInfo) { info.PieceLength = 0 }},
+		{name: "wrong piece count", change: func(info *api.FileInfo) { info.Pieces = 3 }},
+		{name: "missing piece hash", change: func(info *api.FileInfo) { info.PieceHashes = info.PieceHashes[:1] }},
+		{name: "huge length", change: func(info *api.FileInfo) { info.Length = 1<<64 - 1 }},
+	}
+
+	for _, tt := range tests {
+		info := testInfo()
+		tt.change(info)
+
+		if err := checkRemoteInfo(info, hash); (err == nil) != tt.ok {
+			t.Errorf("%s: checkRemoteInfo = %v, want ok %v", tt.name, err, tt.ok)
+		}
+	}
+}
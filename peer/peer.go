@@ -2,7 +2,9 @@ package main
 
 import (
 	"context"
+	"crypto/ed25519"
 	"errors"
+	"math/rand"
 	"sync"
 	"time"
 
@@ -12,227 +14,539 @@ import (
 	"google.golang.org/grpc/status"
 
 	"github.com/elizarpif/grpctorrent/api"
+	"github.com/elizarpif/grpctorrent/api/bitfield"
+	"github.com/elizarpif/grpctorrent/peer/dht"
+	"github.com/elizarpif/grpctorrent/peer/picker"
 	"github.com/elizarpif/logger"
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/google/uuid"
 )
 
 type Peer struct {
-	id        uuid.UUID
-	hashFiles map[string]*file
-	haveFiles map[string]*file
-	tracker   api.TrackerClient
+	id          uuid.UUID
+	ident       *identity
+	addr        string // адрес grpc-сервера, который пир объявляет трекеру
+	files       *registry
+	tracker     api.TrackerClient
+	trackerAddr string // адрес трекера для метаинфо
+	trackerKey  *trackerKey
+	signer      *signer             // подписывает запросы к трекеру и запросы кусков приватных файлов
+	nonces      *nonces             // nonce подписанных запросов кусков
+	pickerName  string              // стратегия выбора кусков при скачивании
+	transport   grpc.DialOption     // TLS или незашифрованное соединение с другими узлами
+	share       *shareRoots         // откуда разрешено раздавать файлы
+	trusted     []ed25519.PublicKey // издатели, чьи файлы можно скачивать; пусто - любые
+	reports     *reporter           // очередь скачанных кусков для трекера
+	dht         *dht.DHT            // поиск пиров без трекера
+	swarm       *swarmPeers         // участники роев, известные по обмену пирами
+	local       *localDiscovery     // пиры из локальной сети, nil - поиск выключен
+	bootstrap   []string            // пиры из файла настроек, у них ищется файл без трекера
 }
 
-func NewPeer(ctx context.Context, trackerAddr, peerServerAddr string) (*Peer, error) {
-	opts := []grpc.DialOption{grpc.WithInsecure(), grpc.WithPerRPCCredentials(newAuth(peerServerAddr))}
-	trackerClient, err := grpc.DialContext(ctx, trackerAddr, opts...)
+// config - настройки пира
+type config struct {
+	trackerAddr string
+	addr        string // адрес grpc-сервера пира
+	listen      string // адрес, на котором слушает grpc-сервер
+	picker      string // стратегия выбора кусков
+	ident       *identity
+	transport   grpc.DialOption // как соединяться с трекером и пирами
+	share       *shareRoots
+	trusted     []ed25519.PublicKey // доверенные издатели
+	lsdGroup    string              // multicast-группа для поиска в локальной сети, пусто - не искать
+	lsdInterval time.Duration
+	bootstrap   []string // известные пиры из файла настроек
+}
+
+func NewPeer(ctx context.Context, cfg *config) (*Peer, error) {
+	id := cfg.ident.id
+
+	sign := newSigner(cfg.ident, cfg.addr)
+
+	opts := []grpc.DialOption{cfg.transport, grpc.WithUnaryInterceptor(sign.unaryInterceptor)}
+	trackerClient, err := grpc.DialContext(ctx, cfg.trackerAddr, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	tracker := api.NewTrackerClient(trackerClient)
+
+	var local *localDiscovery
+	if cfg.lsdGroup != "" {
+		local, err = newLocalDiscovery(cfg.lsdGroup, cfg.lsdInterval, id.String(), cfg.addr, cfg.listen)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	node, err := dht.New(dht.NewID(cfg.ident.publicKey), cfg.addr, cfg.transport)
 	if err != nil {
 		return nil, err
 	}
 
 	return &Peer{
-		id:        uuid.New(),
-		hashFiles: make(map[string]*file),
-		haveFiles: make(map[string]*file),
-		tracker:   api.NewTrackerClient(trackerClient),
+		id:          id,
+		ident:       cfg.ident,
+		addr:        cfg.addr,
+		files:       newRegistry(),
+		tracker:     tracker,
+		trackerAddr: cfg.trackerAddr,
+		trackerKey:  newTrackerKey(),
+		signer:      sign,
+		nonces:      newNonces(),
+		pickerName:  cfg.picker,
+		transport:   cfg.transport,
+		share:       cfg.share,
+		trusted:     cfg.trusted,
+		reports:     newReporter(tracker),
+		dht:         node,
+		swarm:       newSwarmPeers(cfg.addr),
+		local:       local,
+		bootstrap:   cfg.bootstrap,
 	}, nil
 }
 
-func (p *Peer) UploadFile(ctx context.Context, f *api.File) (*empty.Empty, error) {
+// GetIdentity отдает постоянную личность пира, по peer_id его можно найти в ответах трекера
+func (p *Peer) GetIdentity(ctx context.Context, e *empty.Empty) (*api.PeerIdentity, error) {
+	return &api.PeerIdentity{
+		PeerId:    p.id.String(),
+		PublicKey: p.ident.publicKey,
+		Addresses: []string{p.addr},
+	}, nil
+}
+
+func (p *Peer) UploadFile(ctx context.Context, f *api.File) (*api.FileInfo, error) {
 	logger.GetLogger(ctx).WithField("filename", f.Name).Debug("upload file")
-	file, err := newFile(f.Name)
+
+	name, err := p.share.resolve(f.Name)
+	if err != nil {
+		logger.GetLogger(ctx).WithError(err).WithField("filename", f.Name).Error("refused to share file")
+		return nil, err
+	}
+
+	file, err := p.readShared(name, f.Torrent)
 	if err != nil {
 		return nil, err
 	}
 
+	// пир публикует файл от своего имени, подпись проверят трекер и скачивающие
+	file.sign(p.ident.privateKey)
+
 	hash := file.hash
 
-	p.hashFiles[hash] = file
-	p.haveFiles[file.name] = file
+	private := f.Private || f.Visibility == api.Visibility_PRIVATE
+
+	file = p.files.add(file)
+	file.setPrivate(private)
 
 	_, err = p.tracker.Upload(ctx, &api.UploadFileRequest{
-		ClientId:    p.id.String(),
-		Name:        file.name,
-		PieceLength: file.piecesLen,
-		Pieces:      uint64(len(file.piecesMap)),
-		Length:      file.length,
-		Hash:        hash,
+		ClientId:      p.id.String(),
+		Name:          file.name,
+		PieceLength:   file.piecesLen,
+		Pieces:        file.pieces,
+		Length:        file.length,
+		Hash:          hash,
+		Private:       private,
+		AllowedPeers:  f.AllowedPeers,
+		Visibility:    f.Visibility,
+		AllowedGroups: f.AllowedGroups,
+		PieceHashes:   file.pieceHashes,
+		PublisherKey:  file.publisherKey,
+		Signature:     file.signature,
+		PieceHash:     file.pieceHash,
 	})
 	if err != nil {
 		return nil, status.Error(codes.Canceled, "can't upload file to tracker")
 	}
 
-	return &empty.Empty{}, nil
+	p.provide(file, f.Visibility)
+
+	return p.describe(ctx, file), nil
+}
+
+// confirm сообщает трекеру, что содержимое скачанного файла сошлось с его хэшем:
+// описание, подтвержденное другим пиром, трекер больше не дает заменить
+func (p *Peer) confirm(ctx context.Context, f *file) {
+	_, err := p.tracker.Upload(ctx, &api.UploadFileRequest{
+		ClientId:     p.id.String(),
+		Name:         f.name,
+		PieceLength:  f.piecesLen,
+		Pieces:       f.pieces,
+		Length:       f.length,
+		Hash:         f.hash,
+		PieceHashes:  f.pieceHashes,
+		PublisherKey: f.publisherKey,
+		Signature:    f.signature,
+		PieceHash:    f.pieceHash,
+	})
+	if err != nil {
+		logger.GetLogger(ctx).WithError(err).WithField("hash", f.hash).Error("cannot confirm file to tracker")
+	}
+}
+
+// allPieces - номера всех кусков файла, ими описываются пиры с неизвестными кусками
+func allPieces(pieces uint64) []uint64 {
+	all := make([]uint64, pieces)
+	for i := range all {
+		all[i] = uint64(i)
+	}
+
+	return all
+}
+
+// provide объявляет файл в DHT и в локальной сети. Там нет проверки доступа,
+// поэтому объявляются только публичные файлы.
+func (p *Peer) provide(f *file, visibility api.Visibility) {
+	if f.isPrivate() || visibility != api.Visibility_PUBLIC {
+		return
+	}
+
+	p.dht.Provide(f.hash)
+
+	if p.local != nil {
+		p.local.provide(f.hash)
+	}
 }
 
 func (p *Peer) GetFileInfo(ctx context.Context, f *api.File) (*api.FileInfo, error) {
-	is, ok := p.haveFiles[f.Name]
+	is, ok := p.files.getByName(f.Name)
 	if ok {
-		return &api.FileInfo{
-			Name:        f.Name,
-			PieceLength: is.piecesLen,
-			Pieces:      uint64(len(is.piecesMap)),
-			Length:      is.length,
-			Hash:        is.hash,
-		}, nil
+		return p.describe(ctx, is), nil
 	}
 
 	logger.GetLogger(ctx).Error("cannot find file")
 	return nil, status.Error(codes.NotFound, "cannot find file")
 }
 
-type downloadFields struct {
-	isPieceDownload          map[uint64]bool
-	position                 uint64
-	anotherPeerAddr, hashStr string
-	piecesMap                map[uint]*api.Piece
+// readShared читает раздаваемый файл; с торрент-файлом куски и хэш берутся из него
+func (p *Peer) readShared(name string, torrent []byte) (*file, error) {
+	if len(torrent) == 0 {
+		return newFile(name)
+	}
+
+	info, _, err := decodeTorrent(torrent)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	f, err := newTorrentFile(name, info)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return f, nil
 }
 
-// скачивание одного кусочка
-func (p *Peer) downloadPiece(ctx context.Context, df *downloadFields) error {
-	position := df.position
+// describe - описание файла для пользователя: с метаинфо и magnet-ссылкой,
+// в которой этот пир указан как источник
+func (p *Peer) describe(ctx context.Context, f *file) *api.FileInfo {
+	info := f.info()
 
-	// если такой кусок еще не загружен
-	if !df.isPieceDownload[position] {
-		opts := []grpc.DialOption{grpc.WithInsecure()}
+	// без хэшей кусков метаинфо не собрать, тогда отдаем описание без него
+	metainfo, err := encodeMetainfo(info, []string{p.trackerAddr})
+	if err != nil {
+		logger.GetLogger(ctx).WithError(err).WithField("filename", f.name).Debug("no metainfo")
+	}
 
-		conn, err := grpc.DialContext(ctx, df.anotherPeerAddr, opts...)
-		if err != nil {
-			return err
-		}
-		defer conn.Close()
+	info.Metainfo = metainfo
+	info.Uri = (&magnet{
+		hash:     info.Hash,
+		name:     info.Name,
+		length:   info.Length,
+		trackers: []string{p.trackerAddr},
+		peers:    []string{p.addr},
+	}).String()
 
-		anotherPeer := api.NewPeerClient(conn)
-		piece, err := anotherPeer.GetPiece(ctx, &api.GetPieceRequest{
-			SerialNumber: df.position,
-			Hash:         df.hashStr,
-		})
-		if err != nil {
-			logger.GetLogger(ctx).WithError(err).WithField("remote_peer", df.anotherPeerAddr).Error("cannot get piece")
-			return nil
-		}
+	return info
+}
+
+type downloadFields struct {
+	position                 uint64
+	anotherPeerAddr, hashStr string
+	token                    string // токен трекера для приватного файла
+}
 
-		df.piecesMap[uint(position)] = piece
-		df.isPieceDownload[position] = true
+// скачивание одного кусочка, nil без ошибки - кусок получить не удалось
+func (p *Peer) downloadPiece(ctx context.Context, df *downloadFields) (*api.Piece, error) {
+	opts := []grpc.DialOption{p.transport}
+	if df.token != "" {
+		// токен годится только в запросе, подписанном ключом этого пира
+		opts = append(opts, grpc.WithUnaryInterceptor(p.signer.unaryInterceptor))
+	}
 
-		logger.GetLogger(ctx).
-			WithField("peer_addr", df.anotherPeerAddr).
-			WithField("position", position).
-			Debug("download")
+	conn, err := grpc.DialContext(ctx, df.anotherPeerAddr, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
 
-		_, err = p.tracker.PostPieceInfo(ctx, &api.PieceInfo{
-			HashFile: df.hashStr,
-			Serial:   df.position,
-		})
-		if err != nil {
-			logger.GetLogger(ctx).WithError(err).Error("cannot post piece info")
-			return err
-		}
+	anotherPeer := api.NewPeerClient(conn)
+	piece, err := anotherPeer.GetPiece(ctx, &api.GetPieceRequest{
+		SerialNumber: df.position,
+		Hash:         df.hashStr,
+		AccessToken:  df.token,
+	})
+	if err != nil {
+		logger.GetLogger(ctx).WithError(err).WithField("remote_peer", df.anotherPeerAddr).Error("cannot get piece")
+		return nil, nil
 	}
 
-	return nil
+	logger.GetLogger(ctx).
+		WithField("peer_addr", df.anotherPeerAddr).
+		WithField("position", df.position).
+		Debug("download")
+
+	return piece, nil
 }
 
 type fields struct {
-	mutex           *sync.RWMutex
-	addr            string
-	positions       []uint64
-	isPieceDownload map[uint64]bool
-	file            *file
+	mutex   *sync.Mutex
+	addr    string
+	source  picker.Set    // куски, доступные у пира
+	state   *picker.State // общее состояние скачивания файла
+	picker  picker.Picker // стратегия выбора следующего куска
+	file    *file
+	token   string   // токен трекера для приватного файла
+	workers *workers // все загрузчики этого файла
+	offline bool     // скачивание без трекера, куски ему не сообщаются
 }
 
 func (p *Peer) downloadFile(ctx context.Context, group *errgroup.Group, f *fields) {
+	f.workers.start()
+
 	group.Go(func() error {
-		f.mutex.Lock()
+		defer f.workers.exit()
 
 		anotherPeerAddr := f.addr
 		hashStr := f.file.hash
 
 		logger.GetLogger(ctx).WithField("addr", anotherPeerAddr).Debug("started cycle goroutine")
 
-		f.mutex.Unlock()
-
-		for _, position := range f.positions {
+		for {
 			f.mutex.Lock()
+			position, ok := f.picker.Pick(f.state, f.source)
+			if !ok {
+				f.mutex.Unlock()
+				return nil
+			}
+			f.state.Reserve(position)
+			f.mutex.Unlock()
 
-			err := p.downloadPiece(ctx, &downloadFields{
-				isPieceDownload: f.isPieceDownload,
+			piece, err := p.downloadPiece(ctx, &downloadFields{
 				position:        position,
 				anotherPeerAddr: anotherPeerAddr,
 				hashStr:         hashStr,
-				piecesMap:       f.file.piecesMap,
+				token:           f.token,
 			})
 
+			f.mutex.Lock()
+
 			if err != nil {
+				f.state.Release(position)
 				f.mutex.Unlock()
 				return err
 			}
 
-			if _, ok := p.hashFiles[hashStr]; !ok {
-				p.hashFiles[hashStr] = f.file
+			if piece != nil && !f.file.checkPiece(position, piece) {
+				logger.GetLogger(ctx).WithField("remote_peer", anotherPeerAddr).WithField("position", position).
+					Error("piece hash mismatch")
+				piece = nil
 			}
-			if _, ok := p.haveFiles[f.file.name]; !ok {
-				p.haveFiles[f.file.name] = f.file
+
+			if piece == nil {
+				// у этого пира кусок больше не спрашиваем, пусть попробуют другие
+				delete(f.source, position)
+				f.state.RemovePiece(position)
+				f.state.Release(position)
+				f.mutex.Unlock()
+				continue
 			}
 
+			// кусок сразу доступен другим пирам, а трекер узнает о нем со следующим отчетом
+			f.file.setPiece(piece)
+
+			f.state.Done(position)
 			f.mutex.Unlock()
 
+			if !f.offline {
+				p.reports.add(hashStr, f.file.pieces, position)
+			}
+
 			time.Sleep(time.Second)
 		}
-
-		return nil
 	})
 }
 
 func (p *Peer) Download(ctx context.Context, f *api.DownloadFileRequest) (*api.DownloadFileResponse, error) {
-	hashStr := f.Hash
-
-	info, err := p.tracker.GetFileInfo(ctx, &api.DownloadFileRequest{Hash: hashStr})
+	o, err := p.downloadInfo(ctx, f)
 	if err != nil {
-		return nil, status.Error(codes.NotFound, err.Error())
+		return nil, err
 	}
 
-	// сходить на сервер и получить список пиров для файла
-	list, err := p.tracker.GetPeers(ctx, &api.GetPeersRequest{
-		HashFile: hashStr,
-		PeerId:   p.id.String(),
-	})
+	info := o.info
+	hashStr := info.Hash
+
+	err = p.verifyPublisher(info)
 	if err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
+		logger.GetLogger(ctx).WithError(err).WithField("hash", hashStr).Error("rejected file metadata")
+		return nil, err
+	}
+
+	var (
+		lists []*api.ListPeers
+		token string // токен своего трекера, с ним качаем у пиров не из списков трекеров
+	)
+	if !o.offline {
+		req := &api.GetPeersRequest{
+			HashFile: hashStr,
+			PeerId:   p.id.String(),
+			Bitfield: true,
+		}
+
+		// сходить на сервер и получить список пиров для файла
+		list, err := p.tracker.GetPeers(ctx, req)
+		if err != nil {
+			logger.GetLogger(ctx).WithError(err).WithField("hash", hashStr).Error("cannot get peers from tracker")
+		} else {
+			lists = append(lists, list)
+			token = list.AccessToken
+		}
+
+		// трекеры из ссылки, торрент-файла и метаинфо знают пиров, которых не знает свой
+		lists = append(lists, p.peersFromTrackers(ctx, req, o.trackers)...)
 	}
 
-	file := &file{
-		name:      info.Name,
-		hash:      hashStr,
-		allPieces: false,
-		length:    info.Length,
-		piecesLen: info.PieceLength,
-		piecesMap: make(map[uint]*api.Piece), // карта кусков
+	tracked := 0 // пиров от трекеров
+	for _, list := range lists {
+		tracked += len(list.Peers)
+	}
+
+	direct := o.peers
+
+	// соседи по локальной сети, объявившие файл
+	if p.local != nil {
+		direct = append(direct, p.local.peers(hashStr)...)
+	}
+
+	// трекеры недоступны или никого не знают - ищем раздающих в DHT, если еще не искали
+	if tracked == 0 && !o.searched {
+		found := p.dht.FindPeers(ctx, hashStr)
+		logger.GetLogger(ctx).WithField("hash", hashStr).WithField("peers", len(found)).Info("peers from dht")
+
+		direct = append(direct, found...)
+	}
+
+	if tracked == 0 && len(direct) == 0 {
+		return nil, status.Error(codes.Unavailable, "no peers for file")
+	}
+
+	downloading := newEmptyFile(info)
+
+	// файл сразу попадает в реестр, куски раздаются по мере скачивания
+	file := p.files.add(downloading)
+
+	pick, err := picker.New(p.pickerName, rand.New(rand.NewSource(time.Now().UnixNano()))) //nolint:gosec // not for crypto
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
 	}
 
 	// обратиться к клиенту и скачать по кусочкам
 
-	isPieceDownload := make(map[uint64]bool)       // карта каждого куска
+	state := picker.NewState(info.Pieces)          // состояние каждого куска
 	peerAddrPositions := make(map[string][]uint64) // карта адреса пира к количеству достпуных кусок
 
-	for _, p := range list.Peers {
-		peerAddrPositions[p.Address] = p.SerialPieces
+	tokens := make(map[string]string) // токен трекера, давшего пира
+
+	for _, list := range lists {
+		for _, peer := range list.Peers {
+			// пир, известный нескольким трекерам, качает один загрузчик
+			if _, ok := peerAddrPositions[peer.Address]; ok {
+				continue
+			}
+
+			pieces := peer.SerialPieces
+			if peer.Pieces != nil {
+				bf, err := bitfield.Decode(peer.Pieces, info.Pieces)
+				if err != nil {
+					logger.GetLogger(ctx).WithError(err).WithField("peer", peer.Address).Error("invalid piece bitfield")
+					continue
+				}
+
+				pieces = bf.Pieces()
+			}
+
+			peerAddrPositions[peer.Address] = pieces
+			tokens[peer.Address] = list.AccessToken
+			state.AddSource(pieces)
+		}
 	}
 
-	mutex := &sync.RWMutex{}
+	// про пиров из ссылки трекер ничего не знает, считаем их сидами:
+	// куски, которых у них нет, отвалятся при первой неудачной попытке
+	for _, addr := range direct {
+		if _, ok := peerAddrPositions[addr]; ok || addr == p.addr {
+			continue
+		}
+
+		all := allPieces(info.Pieces)
+
+		peerAddrPositions[addr] = all
+		state.AddSource(all)
+	}
+
+	mutex := &sync.Mutex{}
 	group := &errgroup.Group{}
+	running := newWorkers()
 
 	logger.GetLogger(ctx).WithField("peer addr", peerAddrPositions).Debug("addresses")
 
+	start := func(addr string, positions []uint64) {
+		t, ok := tokens[addr]
+		if !ok {
+			t = token
+		}
+
+		p.downloadFile(ctx, group, &fields{
+			mutex:   mutex,
+			addr:    addr,
+			source:  picker.NewSet(positions),
+			state:   state,
+			picker:  pick,
+			file:    file,
+			token:   t,
+			workers: running,
+			offline: o.offline,
+		})
+	}
+
 	// пройтись по списку доступных пиров и скачать у них доступные файлы
+	sources := make(map[string]bool, len(peerAddrPositions))
 	for anotherPeerAddr, positions := range peerAddrPositions {
-		p.downloadFile(ctx, group, &fields{
-			mutex:           mutex,
-			addr:            anotherPeerAddr,
-			positions:       positions,
-			isPieceDownload: isPieceDownload,
-			file:            file,
+		sources[anotherPeerAddr] = true
+		start(anotherPeerAddr, positions)
+	}
+
+	// обмен пирами только для публичных файлов, куски новых пиров не известны, как у пиров из ссылки
+	if !info.Private && info.Visibility == api.Visibility_PUBLIC {
+		p.exchangePeers(ctx, group, &exchange{
+			hash:    hashStr,
+			sources: sources,
+			workers: running,
+			left: func() uint64 {
+				mutex.Lock()
+				defer mutex.Unlock()
+
+				return state.Left()
+			},
+			add: func(addr string) {
+				all := allPieces(info.Pieces)
+
+				mutex.Lock()
+				state.AddSource(all)
+				mutex.Unlock()
+
+				start(addr, all)
+			},
 		})
 	}
 
@@ -242,17 +556,21 @@ func (p *Peer) Download(ctx context.Context, f *api.DownloadFileRequest) (*api.D
 		return nil, status.Error(codes.Internal, "error in get pieces")
 	}
 
-	isAllFileDownload := true
-	for _, piece := range file.piecesMap {
-		if !isPieceDownload[piece.SerialNumber] {
-			logger.GetLogger(ctx).WithField("piece_position", piece.SerialNumber).Error("file not downloaded")
-			isAllFileDownload = false
-		}
+	if state.Left() != 0 {
+		logger.GetLogger(ctx).WithField("pieces_left", state.Left()).Error("file not downloaded")
+		return nil, status.Error(codes.Unavailable, "file not downloaded: no source has the remaining pieces")
 	}
 
-	file.allPieces = isAllFileDownload
+	file.mutex.Lock()
+	file.allPieces = true
+	file.mutex.Unlock()
 
 	err = file.MergePieces(ctx)
+	if errors.Is(err, errContentMismatch) {
+		// куски подложного описания раздавать нельзя
+		p.files.remove(hashStr)
+		return nil, status.Error(codes.DataLoss, err.Error())
+	}
 	if err != nil {
 		logger.GetLogger(ctx).Error("cannot merge file")
 		return nil, status.Error(codes.Internal, err.Error())
@@ -260,24 +578,176 @@ func (p *Peer) Download(ctx context.Context, f *api.DownloadFileRequest) (*api.D
 
 	logger.GetLogger(ctx).WithField("filepath", file.name).Info("downloaded")
 
+	// подтверждаем только файл из каталога своего трекера, чужие описания ему не публикуем
+	if o.tracked {
+		p.confirm(ctx, file)
+	}
+
+	p.provide(file, info.Visibility)
+
 	return &api.DownloadFileResponse{
 		FilePath: getDownloadFilename(file.name),
 	}, nil
 }
 
+// origin - откуда берется скачиваемый файл
+type origin struct {
+	info     *api.FileInfo
+	peers    []string // пиры из запроса и ссылки, у них качаем без списка от трекера
+	trackers []string // другие grpc-трекеры из ссылки и описания файла
+	tracked  bool     // описание дал свой трекер
+	searched bool     // раздающих уже искали в DHT, найденные - в peers
+	offline  bool     // пиры указаны явно или описание дали пиры: трекер не нужен ни для списка пиров, ни для отчетов
+}
+
+// downloadInfo - описание скачиваемого файла: из торрент-файла, метаинфо, каталога трекера
+// или от указанных пиров, а также пиры из запроса и magnet-ссылки и трекеры
+// из ссылки, торрент-файла и метаинфо. Остальных пиров дает трекер этого пира.
+func (p *Peer) downloadInfo(ctx context.Context, f *api.DownloadFileRequest) (*origin, error) {
+	var (
+		hash     = f.Hash
+		link     *magnet
+		info     *api.FileInfo
+		trackers []string
+		err      error
+	)
+
+	if f.Uri != "" {
+		link, err = parseMagnet(f.Uri)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		if hash != "" && hash != link.hash {
+			return nil, status.Error(codes.InvalidArgument, "hash doesnt match uri")
+		}
+
+		hash = link.hash
+	}
+
+	// с явно указанными пирами к трекеру не ходим совсем
+	o := &origin{offline: len(f.Peers) > 0}
+
+	// адреса из запроса и ссылки, у них качаем без списка от трекера
+	o.peers = uniquePeers(f.Peers)
+	if link != nil {
+		o.peers = uniquePeers(o.peers, link.peers)
+		trackers = append(trackers, link.trackers...)
+	}
+
+	var (
+		listed    []string // трекеры из торрент-файла или метаинфо
+		fromPeers = false
+	)
+
+	switch {
+	case len(f.Torrent) > 0:
+		info, listed, err = decodeTorrent(f.Torrent)
+	case len(f.Metainfo) > 0:
+		info, listed, err = decodeMetainfo(f.Metainfo)
+	case len(f.Peers) > 0:
+		if hash == "" {
+			return nil, status.Error(codes.InvalidArgument, "hash is required to download from peers")
+		}
+
+		info, err = p.infoFromPeers(ctx, hash, uniquePeers(o.peers, p.bootstrap))
+		if err != nil {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+
+		fromPeers = true
+	default:
+		info, err = p.tracker.GetFileInfo(ctx, &api.DownloadFileRequest{Hash: hash})
+		if err == nil {
+			o.tracked = true
+			break
+		}
+
+		if hash == "" {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+
+		// свой трекер недоступен или не знает файл - описание отдадут трекеры из ссылки или известные пиры
+		logger.GetLogger(ctx).WithError(err).WithField("hash", hash).Error("cannot get file info from tracker")
+
+		trackerErr := err
+
+		info, err = p.infoFromTrackers(ctx, hash, grpcTrackers(trackers, p.trackerAddr))
+		if err != nil && len(o.peers)+len(p.bootstrap) > 0 {
+			info, err = p.infoFromPeers(ctx, hash, uniquePeers(o.peers, p.bootstrap))
+			fromPeers = err == nil
+		}
+
+		if err != nil {
+			// известных пиров нет или у них нет файла - ищем раздающих в локальной сети и DHT
+			found := p.dht.FindPeers(ctx, hash)
+			logger.GetLogger(ctx).WithField("hash", hash).WithField("peers", len(found)).Info("peers from dht")
+
+			o.peers = append(o.peers, found...)
+			o.searched = true
+			o.offline = true
+
+			if p.local != nil {
+				found = append(p.local.peers(hash), found...)
+			}
+
+			info, err = p.infoFromPeers(ctx, hash, found)
+		}
+
+		if err != nil {
+			return nil, status.Error(codes.NotFound, trackerErr.Error())
+		}
+	}
+
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if hash != "" && hash != info.Hash {
+		return nil, status.Error(codes.InvalidArgument, "hash doesnt match file description")
+	}
+
+	if link != nil && link.length != 0 && link.length != info.Length {
+		return nil, status.Error(codes.InvalidArgument, "file length doesnt match uri")
+	}
+
+	if fromPeers {
+		o.offline = true
+
+		// пиры из файла настроек становятся источниками, только если у них есть файл
+		if len(p.bootstrap) > 0 {
+			o.peers = uniquePeers(o.peers, p.holders(ctx, info, p.bootstrap))
+		}
+	}
+
+	o.info = info
+	o.trackers = grpcTrackers(append(trackers, listed...), p.trackerAddr)
+
+	return o, nil
+}
+
 // пришел запрос "дай кусок"
 func (p *Peer) GetPiece(ctx context.Context, request *api.GetPieceRequest) (*api.Piece, error) {
 	log := logger.GetLogger(ctx)
 
-	file, exists := p.hashFiles[request.Hash]
+	file, exists := p.files.getByHash(request.Hash)
 	if !exists {
 		log.Error("file doesn't exists")
 		return nil, errors.New("file doesn't exists")
 	}
 
-	piece, exists := file.piecesMap[uint(request.SerialNumber)]
+	// приватный файл отдаем только по токену, выданному трекером тому, кто подписал запрос
+	if file.isPrivate() {
+		err := p.checkAccess(ctx, request)
+		if err != nil {
+			log.WithError(err).WithField("hash", request.Hash).Error("access denied")
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+	}
+
+	piece, exists := p.files.piece(request.Hash, request.SerialNumber)
 	if !exists {
-		log.WithField("serial", request.SerialNumber).WithField("map", file.piecesMap).Error("piece doesn't exists")
+		log.WithField("serial", request.SerialNumber).Error("piece doesn't exists")
 		return nil, errors.New("piece doesn't exists")
 	}
 
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	"net"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/elizarpif/grpctorrent/api"
+	"github.com/elizarpif/logger"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	pexInterval = 30 * time.Second // как часто скачивающий обменивается пирами с источниками
+	pexTimeout  = 5 * time.Second
+
+	pexMax  = 50               // сколько адресов уходит в одном сообщении
+	pexKeep = 200              // сколько адресов пир помнит по одному файлу
+	pexTTL  = 30 * time.Minute // адрес, о котором давно не слышали, забывается
+)
+
+// swarmPeers - участники роев, о которых пир узнал при обмене и при скачивании
+type swarmPeers struct {
+	self  string                          // свой адрес, его пир не запоминает
+	known map[string]map[string]time.Time // хэш файла - адрес - когда узнали
+
+	mutex *sync.Mutex
+}
+
+func newSwarmPeers(self string) *swarmPeers {
+	return &swarmPeers{self: self, known: make(map[string]map[string]time.Time), mutex: &sync.Mutex{}}
+}
+
+// add запоминает адреса, кроме своего, при переполнении вытесняются самые старые
+func (s *swarmPeers) add(hash string, addrs ...string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	peers, ok := s.known[hash]
+	if !ok {
+		peers = make(map[string]time.Time)
+		s.known[hash] = peers
+	}
+
+	now := time.Now()
+	for _, addr := range addrs {
+		if _, _, err := net.SplitHostPort(addr); err != nil || addr == s.self {
+			continue
+		}
+
+		if _, ok := peers[addr]; !ok && len(peers) >= pexKeep {
+			var oldest string
+			for a, at := range peers {
+				if oldest == "" || at.Before(peers[oldest]) {
+					oldest = a
+				}
+			}
+			delete(peers, oldest)
+		}
+
+		peers[addr] = now
+	}
+}
+
+// list - до pexMax недавних адресов роя, кроме except
+func (s *swarmPeers) list(hash, except string) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	peers := s.known[hash]
+
+	var res []string
+	for addr, at := range peers {
+		if addr != except && time.Since(at) < pexTTL {
+			res = append(res, addr)
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return peers[res[i]].After(peers[res[j]])
+	})
+
+	if len(res) > pexMax {
+		res = res[:pexMax]
+	}
+
+	return res
+}
+
+// ExchangePeers запоминает отправителя и присланные адреса и отвечает своими.
+// Для приватных файлов обмен закрыт: состав роя знает только трекер.
+func (p *Peer) ExchangePeers(ctx context.Context, req *api.PeerExchange) (*api.PeerExchange, error) {
+	file, ok := p.files.getByHash(req.Hash)
+	if !ok {
+		return nil, status.Error(codes.NotFound, "file doesn't exists")
+	}
+
+	if file.isPrivate() {
+		return nil, status.Error(codes.PermissionDenied, "peer exchange is disabled for private files")
+	}
+
+	sender, ok := remoteAddr(ctx, req.Address)
+	if ok {
+		p.swarm.add(req.Hash, sender)
+	}
+
+	p.swarm.add(req.Hash, receivedPeers(req.Peers, p.addr)...)
+
+	return &api.PeerExchange{Hash: req.Hash, Peers: p.swarm.list(req.Hash, sender)}, nil
+}
+
+// exchangeWith обменивается участниками роя с одним пиром и возвращает присланные им адреса
+func (p *Peer) exchangeWith(ctx context.Context, addr, hash string) []string {
+	ctx, cancel := context.WithTimeout(ctx, pexTimeout)
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, addr, p.transport)
+	if err != nil {
+		return nil
+	}
+	defer conn.Close()
+
+	resp, err := api.NewPeerClient(conn).ExchangePeers(ctx, &api.PeerExchange{
+		Hash:    hash,
+		Address: p.addr,
+		Peers:   p.swarm.list(hash, addr),
+	})
+	if err != nil {
+		logger.GetLogger(ctx).WithError(err).WithField("remote_peer", addr).Debug("cannot exchange peers")
+		return nil
+	}
+
+	peers := receivedPeers(resp.Peers, p.addr)
+	p.swarm.add(hash, peers...)
+
+	return peers
+}
+
+// receivedPeers - присланные адреса без повторов и без своего, не больше pexMax:
+// повторами отправитель не вытеснит из сообщения остальных
+func receivedPeers(peers []string, self string) []string {
+	seen := make(map[string]bool)
+
+	var res []string
+	for _, addr := range peers {
+		if len(res) == pexMax {
+			break
+		}
+
+		if addr == self || seen[addr] {
+			continue
+		}
+
+		seen[addr] = true
+		res = append(res, addr)
+	}
+
+	return res
+}
+
+// workers считает загрузчиков одного файла и сообщает о завершении каждого
+type workers struct {
+	active int32
+	exited chan struct{}
+}
+
+func newWorkers() *workers {
+	return &workers{exited: make(chan struct{}, 1)}
+}
+
+func (w *workers) start() {
+	atomic.AddInt32(&w.active, 1)
+}
+
+func (w *workers) exit() {
+	atomic.AddInt32(&w.active, -1)
+
+	select {
+	case w.exited <- struct{}{}:
+	default:
+	}
+}
+
+func (w *workers) idle() bool {
+	return atomic.LoadInt32(&w.active) == 0
+}
+
+// exchange - обмен пирами во время скачивания одного файла
+type exchange struct {
+	hash    string
+	sources map[string]bool   // у кого уже качаем
+	workers *workers          // загрузчики файла
+	left    func() uint64     // сколько кусков осталось скачать
+	add     func(addr string) // начать качать у нового пира
+}
+
+// exchangePeers сразу и потом раз в pexInterval спрашивает у источников знакомых им
+// участников роя и начинает качать у новых. Заканчивается, когда файл скачан
+// или загрузчики закончили, а новых пиров не нашлось.
+func (p *Peer) exchangePeers(ctx context.Context, group *errgroup.Group, x *exchange) {
+	for addr := range x.sources {
+		p.swarm.add(x.hash, addr)
+	}
+
+	group.Go(func() error {
+		ticker := time.NewTicker(pexInterval)
+		defer ticker.Stop()
+
+		for {
+			if x.left() == 0 {
+				return nil
+			}
+
+			addrs := make([]string, 0, len(x.sources))
+			for addr := range x.sources {
+				addrs = append(addrs, addr)
+			}
+
+			var added int
+			for _, addr := range addrs {
+				for _, found := range p.exchangeWith(ctx, addr, x.hash) {
+					if x.sources[found] || found == p.addr {
+						continue
+					}
+
+					logger.GetLogger(ctx).WithField("remote_peer", found).WithField("from", addr).Debug("peer from exchange")
+
+					x.sources[found] = true
+					x.add(found)
+					added++
+				}
+			}
+
+			if added == 0 && x.workers.idle() {
+				return nil
+			}
+
+			// до следующего обмена раньше просыпаемся, только если загрузчики закончили
+			for waiting := true; waiting; {
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-ticker.C:
+					waiting = false
+				case <-x.workers.exited:
+					waiting = !x.workers.idle() && x.left() != 0
+				}
+			}
+		}
+	})
+}
+
+// remoteAddr - адрес отправителя: ip соединения и порт из объявленного адреса
+func remoteAddr(ctx context.Context, declared string) (string, bool) {
+	_, port, err := net.SplitHostPort(declared)
+	if err != nil || port == "" || port == "0" {
+		return "", false
+	}
+
+	pr, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", false
+	}
+
+	tcp, ok := pr.Addr.(*net.TCPAddr)
+	if !ok {
+		return "", false
+	}
+
+	return net.JoinHostPort(tcp.IP.String(), port), true
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/elizarpif/grpctorrent/api"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// lanPeers - n разных адресов пиров
+func lanPeers(n int) []string {
+	peers := make([]string, n)
+	for i := range peers {
+		peers[i] = fmt.Sprintf("10.0.%d.%d:9002", i/256, i%256)
+	}
+
+	return peers
+}
+
+func TestSwarmPeers(t *testing.T) {
+	s := newSwarmPeers(seederAddr)
+
+	s.add(publicHash, seederAddr, "no port", "10.0.0.1:9002", "10.0.0.1:9002")
+	if got := s.list(publicHash, ""); !reflect.DeepEqual(got, []string{"10.0.0.1:9002"}) {
+		t.Errorf("list = %v, want the valid address once", got)
+	}
+
+	// в сообщение уходят не больше pexMax адресов, кроме того, кому отвечают
+	s.add(publicHash, lanPeers(pexKeep)...)
+	got := s.list(publicHash, "10.0.0.2:9002")
+	if len(got) != pexMax {
+		t.Errorf("list of %d addresses, want %d", len(got), pexMax)
+	}
+	for _, addr := range got {
+		if addr == "10.0.0.2:9002" {
+			t.Error("list includes the excepted address")
+		}
+	}
+
+	// сверх pexKeep вытесняется самый старый адрес
+	s.known[publicHash]["10.0.0.7:9002"] = time.Now().Add(-time.Minute)
+	s.add(publicHash, "10.1.0.1:9002")
+
+	if len(s.known[publicHash]) != pexKeep {
+		t.Errorf("%d addresses kept, want %d", len(s.known[publicHash]), pexKeep)
+	}
+	if _, ok := s.known[publicHash]["10.0.0.7:9002"]; ok {
+		t.Error("oldest address is kept")
+	}
+
+	// давно не слышанный адрес не отдается
+	s.known[publicHash]["10.1.0.1:9002"] = time.Now().Add(-pexTTL)
+	for _, addr := range s.list(publicHash, "") {
+		if addr == "10.1.0.1:9002" {
+			t.Error("expired address is listed")
+		}
+	}
+}
+
+func TestReceivedPeers(t *testing.T) {
+	many := lanPeers(pexMax + 10)
+
+	tests := []struct {
+		name  string
+		peers []string
+		want  []string
+	}{
+		{name: "empty"},
+		{name: "own address", peers: []string{"10.0.0.1:9002", seederAddr}, want: []string{"10.0.0.1:9002"}},
+		{name: "duplicates", peers: []string{"10.0.0.1:9002", "10.0.0.2:9002", "10.0.0.1:9002"}, want: []string{"10.0.0.1:9002", "10.0.0.2:9002"}},
+		{name: "capped", peers: many, want: many[:pexMax]},
+		{
+			// повторы не занимают места остальных
+			name:  "duplicates before the cap",
+			peers: append([]string{many[0], many[0], many[0], seederAddr}, many[1:]...),
+			want:  many[:pexMax],
+		},
+	}
+
+	for _, tt := range tests {
+		if got := receivedPeers(tt.peers, seederAddr); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: receivedPeers = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// startPexPeer запускает публичный сервис пира с публичным и приватным файлами
+func startPexPeer(t *testing.T) (*Peer, api.PeerClient) {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := newSeeder(&keyTracker{})
+	p.addr = lis.Addr().String()
+	p.swarm = newSwarmPeers(p.addr)
+
+	server := grpc.NewServer()
+	api.RegisterPeerServer(server, &publicPeer{p})
+
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial(p.addr, grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return p, api.NewPeerClient(conn)
+}
+
+func TestExchangePeers(t *testing.T) {
+	p, client := startPexPeer(t)
+	ctx := context.Background()
+
+	// отправитель присылает свой адрес, адрес получателя, повторы и адресов больше предела
+	sent := append([]string{p.addr, "10.0.0.1:9002", "10.0.0.1:9002"}, lanPeers(2*pexMax)...)
+
+	resp, err := client.ExchangePeers(ctx, &api.PeerExchange{Hash: publicHash, Address: "somewhere:9009", Peers: sent})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	known := p.swarm.known[publicHash]
+
+	// отправитель записан под ip соединения, из присланного принято не больше pexMax
+	if _, ok := known["127.0.0.1:9009"]; !ok {
+		t.Errorf("sender is not recorded: %v", known)
+	}
+	if len(known) != pexMax+1 {
+		t.Errorf("%d addresses accepted, want %d and the sender", len(known)-1, pexMax)
+	}
+	if _, ok := known[p.addr]; ok {
+		t.Error("own address is recorded")
+	}
+
+	// отправителю не возвращается его же адрес
+	if len(resp.Peers) != pexMax {
+		t.Errorf("response of %d addresses, want %d", len(resp.Peers), pexMax)
+	}
+	for _, addr := range resp.Peers {
+		if addr == "127.0.0.1:9009" || addr == p.addr {
+			t.Errorf("response includes %s", addr)
+		}
+	}
+
+	// о приватном и неизвестном файле пир не рассказывает и ничего не запоминает
+	for hash, code := range map[string]codes.Code{privateHash: codes.PermissionDenied, "33333333333333333333333333333333": codes.NotFound} {
+		_, err := client.ExchangePeers(ctx, &api.PeerExchange{Hash: hash, Address: "somewhere:9009", Peers: []string{"10.2.0.1:9002"}})
+		if status.Code(err) != code {
+			t.Errorf("exchange for %s: %v, want %v", hash, err, code)
+		}
+		if _, ok := p.swarm.known[hash]; ok {
+			t.Errorf("addresses for %s are recorded", hash)
+		}
+	}
+}
+
+// pexServer отвечает на обмен заданным списком
+type pexServer struct {
+	api.UnimplementedPeerServer
+
+	peers []string
+}
+
+func (s *pexServer) ExchangePeers(ctx context.Context, req *api.PeerExchange) (*api.PeerExchange, error) {
+	return &api.PeerExchange{Hash: req.Hash, Peers: s.peers}, nil
+}
+
+func TestExchangeWith(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	many := lanPeers(2 * pexMax)
+	server := grpc.NewServer()
+	api.RegisterPeerServer(server, &pexServer{peers: append([]string{seederAddr, many[0], many[0]}, many...)})
+
+	go func() { _ = server.Serve(lis) }()
+	defer server.Stop()
+
+	p := newManualPeer()
+	p.swarm = newSwarmPeers(p.addr)
+
+	// из ответа источника пир берет не больше pexMax адресов, без себя и повторов
+	got := p.exchangeWith(context.Background(), lis.Addr().String(), publicHash)
+	if !reflect.DeepEqual(got, many[:pexMax]) {
+		t.Errorf("exchangeWith = %v", got)
+	}
+	if len(p.swarm.known[publicHash]) != pexMax {
+		t.Errorf("%d addresses recorded, want %d", len(p.swarm.known[publicHash]), pexMax)
+	}
+}
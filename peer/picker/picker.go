@@ -0,0 +1,195 @@
+// Package picker содержит стратегии выбора следующего куска для скачивания.
+// Один и тот же код используется пиром при реальном скачивании и симулятором роя.
+package picker
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// Source - набор кусков, доступных у одного источника (другого пира)
+type Source interface {
+	Has(piece uint64) bool
+}
+
+// Set - источник, заданный списком номеров кусков
+type Set map[uint64]bool
+
+func NewSet(pieces []uint64) Set {
+	s := make(Set, len(pieces))
+	for _, p := range pieces {
+		s[p] = true
+	}
+
+	return s
+}
+
+func (s Set) Has(piece uint64) bool {
+	return s[piece]
+}
+
+// State - состояние скачивания файла: какие куски уже скачаны, какие качаются
+// и у скольких известных источников есть каждый кусок.
+// State не потокобезопасен, синхронизация остается за вызывающим.
+type State struct {
+	done         []bool
+	inFlight     []bool
+	availability []int
+	left         uint64
+}
+
+func NewState(pieces uint64) *State {
+	return &State{
+		done:         make([]bool, pieces),
+		inFlight:     make([]bool, pieces),
+		availability: make([]int, pieces),
+		left:         pieces,
+	}
+}
+
+// Pieces - всего кусков в файле
+func (s *State) Pieces() uint64 {
+	return uint64(len(s.done))
+}
+
+// Left - сколько кусков еще не скачано
+func (s *State) Left() uint64 {
+	return s.left
+}
+
+func (s *State) IsDone(piece uint64) bool {
+	return piece < s.Pieces() && s.done[piece]
+}
+
+func (s *State) IsInFlight(piece uint64) bool {
+	return piece < s.Pieces() && s.inFlight[piece]
+}
+
+// Availability - у скольких источников есть кусок
+func (s *State) Availability(piece uint64) int {
+	if piece >= s.Pieces() {
+		return 0
+	}
+
+	return s.availability[piece]
+}
+
+// AddSource учитывает куски нового источника
+func (s *State) AddSource(pieces []uint64) {
+	for _, p := range pieces {
+		s.AddPiece(p)
+	}
+}
+
+// RemoveSource убирает куски ушедшего источника
+func (s *State) RemoveSource(pieces []uint64) {
+	for _, p := range pieces {
+		s.RemovePiece(p)
+	}
+}
+
+// AddPiece - у источника появился кусок
+func (s *State) AddPiece(piece uint64) {
+	if piece < s.Pieces() {
+		s.availability[piece]++
+	}
+}
+
+// RemovePiece - источник больше не раздает кусок
+func (s *State) RemovePiece(piece uint64) {
+	if piece < s.Pieces() && s.availability[piece] > 0 {
+		s.availability[piece]--
+	}
+}
+
+// Reserve помечает кусок как качающийся, чтобы его не выбрали для другого источника
+func (s *State) Reserve(piece uint64) {
+	s.inFlight[piece] = true
+}
+
+// Release снимает резерв, если скачать кусок не удалось
+func (s *State) Release(piece uint64) {
+	s.inFlight[piece] = false
+}
+
+// Done помечает кусок как скачанный
+func (s *State) Done(piece uint64) {
+	s.inFlight[piece] = false
+	if !s.done[piece] {
+		s.done[piece] = true
+		s.left--
+	}
+}
+
+// Picker выбирает следующий кусок, который стоит запросить у источника
+type Picker interface {
+	Pick(state *State, src Source) (uint64, bool)
+}
+
+// wanted - кусок нужен и есть у источника
+func wanted(state *State, src Source, piece uint64) bool {
+	return !state.done[piece] && !state.inFlight[piece] && src.Has(piece)
+}
+
+// Sequential выбирает куски по порядку номеров
+type Sequential struct{}
+
+func (Sequential) Pick(state *State, src Source) (uint64, bool) {
+	for i := uint64(0); i < state.Pieces(); i++ {
+		if wanted(state, src, i) {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+// RarestFirst выбирает самый редкий среди источников кусок,
+// при равенстве - случайный из самых редких
+type RarestFirst struct {
+	rnd *rand.Rand
+}
+
+func NewRarestFirst(rnd *rand.Rand) *RarestFirst {
+	return &RarestFirst{rnd: rnd}
+}
+
+func (r *RarestFirst) Pick(state *State, src Source) (uint64, bool) {
+	var (
+		best  uint64
+		min   = -1
+		equal = 0
+	)
+
+	for i := uint64(0); i < state.Pieces(); i++ {
+		if !wanted(state, src, i) {
+			continue
+		}
+
+		avail := state.availability[i]
+		switch {
+		case min == -1 || avail < min:
+			best, min, equal = i, avail, 1
+		case avail == min:
+			// reservoir sampling среди одинаково редких
+			equal++
+			if r.rnd != nil && r.rnd.Intn(equal) == 0 {
+				best = i
+			}
+		}
+	}
+
+	return best, min != -1
+}
+
+// New возвращает стратегию по имени
+func New(name string, rnd *rand.Rand) (Picker, error) {
+	switch name {
+	case "sequential", "":
+		return Sequential{}, nil
+	case "rarest":
+		return NewRarestFirst(rnd), nil
+	}
+
+	return nil, fmt.Errorf("unknown picker %q", name)
+}
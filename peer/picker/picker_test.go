@@ -0,0 +1,156 @@
+package picker
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestStateAccounting(t *testing.T) {
+	s := NewState(4)
+
+	s.AddSource([]uint64{0, 1, 1, 7}) // 7 - за пределами файла
+	s.AddPiece(2)
+	s.RemovePiece(3) // доступность не уходит ниже нуля
+
+	for piece, want := range []int{1, 2, 1, 0} {
+		if got := s.Availability(uint64(piece)); got != want {
+			t.Errorf("availability of %d = %d, want %d", piece, got, want)
+		}
+	}
+	if got := s.Availability(7); got != 0 {
+		t.Errorf("availability outside the file = %d, want 0", got)
+	}
+
+	s.RemoveSource([]uint64{1})
+	if got := s.Availability(1); got != 1 {
+		t.Errorf("availability after RemoveSource = %d, want 1", got)
+	}
+
+	s.Reserve(0)
+	if !s.IsInFlight(0) {
+		t.Error("reserved piece is not in flight")
+	}
+
+	s.Done(0)
+	s.Done(0) // повторный Done не уменьшает остаток
+	if s.IsInFlight(0) || !s.IsDone(0) {
+		t.Error("done piece is still in flight or not done")
+	}
+	if s.Left() != 3 {
+		t.Errorf("left = %d, want 3", s.Left())
+	}
+
+	s.Reserve(1)
+	s.Release(1)
+	if s.IsInFlight(1) || s.IsDone(1) {
+		t.Error("released piece is in flight or done")
+	}
+	if s.IsDone(10) || s.IsInFlight(10) {
+		t.Error("piece outside the file is done or in flight")
+	}
+}
+
+func TestSequential(t *testing.T) {
+	tests := []struct {
+		name     string
+		pieces   uint64
+		source   []uint64
+		done     []uint64
+		reserved []uint64
+		want     uint64
+		ok       bool
+	}{
+		{name: "first piece", pieces: 4, source: []uint64{0, 1, 2, 3}, want: 0, ok: true},
+		{name: "skips done", pieces: 4, source: []uint64{0, 1, 2, 3}, done: []uint64{0, 1}, want: 2, ok: true},
+		{name: "skips reserved", pieces: 4, source: []uint64{0, 1, 2, 3}, reserved: []uint64{0}, want: 1, ok: true},
+		{name: "only source pieces", pieces: 4, source: []uint64{3}, want: 3, ok: true},
+		{name: "nothing wanted", pieces: 2, source: []uint64{0, 1}, done: []uint64{0}, reserved: []uint64{1}},
+		{name: "empty source", pieces: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewState(tt.pieces)
+			for _, p := range tt.done {
+				s.Done(p)
+			}
+			for _, p := range tt.reserved {
+				s.Reserve(p)
+			}
+
+			got, ok := Sequential{}.Pick(s, NewSet(tt.source))
+			if ok != tt.ok || (ok && got != tt.want) {
+				t.Errorf("Pick = %d, %v, want %d, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestRarestFirst(t *testing.T) {
+	s := NewState(5)
+	s.AddSource([]uint64{0, 1, 2, 3, 4})
+	s.AddSource([]uint64{0, 1, 2, 3})
+	s.AddSource([]uint64{0, 1})
+
+	// 4 есть у одного источника, 2 и 3 - у двух
+	r := NewRarestFirst(rand.New(rand.NewSource(1)))
+
+	got, ok := r.Pick(s, NewSet([]uint64{0, 1, 2, 3, 4}))
+	if !ok || got != 4 {
+		t.Fatalf("Pick = %d, %v, want 4", got, ok)
+	}
+
+	// у источника нет 4 - выбирается один из самых редких среди его кусков
+	s.Reserve(4)
+	seen := make(map[uint64]bool)
+	for i := 0; i < 100; i++ {
+		got, ok = r.Pick(s, NewSet([]uint64{0, 1, 2, 3}))
+		if !ok || (got != 2 && got != 3) {
+			t.Fatalf("Pick = %d, %v, want 2 or 3", got, ok)
+		}
+		seen[got] = true
+	}
+	if len(seen) != 2 {
+		t.Errorf("ties are not broken randomly: picked only %v", seen)
+	}
+
+	for _, p := range []uint64{0, 1, 2, 3} {
+		s.Done(p)
+	}
+	if got, ok := r.Pick(s, NewSet([]uint64{0, 1, 2, 3, 4})); ok {
+		t.Errorf("Pick = %d, want nothing", got)
+	}
+}
+
+func TestRarestFirstWithoutRand(t *testing.T) {
+	s := NewState(3)
+	s.AddSource([]uint64{0, 1, 2})
+
+	// без генератора при равенстве остается первый кусок
+	got, ok := NewRarestFirst(nil).Pick(s, NewSet([]uint64{0, 1, 2}))
+	if !ok || got != 0 {
+		t.Errorf("Pick = %d, %v, want 0", got, ok)
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{name: ""},
+		{name: "sequential"},
+		{name: "rarest"},
+		{name: "random", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		p, err := New(tt.name, rand.New(rand.NewSource(1)))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("New(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if err == nil && p == nil {
+			t.Errorf("New(%q) returned no picker", tt.name)
+		}
+	}
+}
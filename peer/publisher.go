@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+
+	"github.com/elizarpif/grpctorrent/api"
+	"github.com/elizarpif/grpctorrent/api/auth"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// parsePublishers разбирает ключи доверенных издателей в base64, как их отдает GetIdentity
+func parsePublishers(keys []string) ([]ed25519.PublicKey, error) {
+	publishers := make([]ed25519.PublicKey, 0, len(keys))
+
+	for _, k := range keys {
+		key, err := base64.StdEncoding.DecodeString(k)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid publisher key " + k)
+		}
+
+		publishers = append(publishers, key)
+	}
+
+	return publishers, nil
+}
+
+// verifyPublisher проверяет подпись описания файла. Если заданы доверенные издатели,
+// неподписанные файлы и файлы чужих издателей не скачиваются.
+func (p *Peer) verifyPublisher(info *api.FileInfo) error {
+	if len(info.Signature) == 0 {
+		if len(p.trusted) > 0 {
+			return status.Error(codes.PermissionDenied, "file is not signed by a publisher")
+		}
+		return nil
+	}
+
+	if uint64(len(info.PieceHashes)) != info.Pieces {
+		return status.Error(codes.DataLoss, "piece hashes dont match pieces")
+	}
+
+	err := auth.VerifyFile(info.PublisherKey, &auth.FileMeta{
+		Name:        info.Name,
+		Length:      info.Length,
+		PieceLength: info.PieceLength,
+		Hash:        info.Hash,
+		PieceHashes: info.PieceHashes,
+		PieceHash:   info.PieceHash.String(),
+	}, info.Signature)
+	if err != nil {
+		return status.Error(codes.DataLoss, err.Error())
+	}
+
+	if len(p.trusted) == 0 {
+		return nil
+	}
+
+	for _, key := range p.trusted {
+		if bytes.Equal(key, info.PublisherKey) {
+			return nil
+		}
+	}
+
+	return status.Error(codes.PermissionDenied, "publisher is not trusted")
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"sync"
+
+	"github.com/elizarpif/grpctorrent/api"
+)
+
+// registry - потокобезопасный реестр файлов пира.
+// Файлы в него попадают при загрузке на трекер и при скачивании первого куска,
+// после чего каждый докачанный кусок сразу становится доступен через GetPiece.
+type registry struct {
+	byHash map[string]*file // хэш файла к файлу
+	byName map[string]*file // имя файла к файлу
+
+	mutex *sync.RWMutex
+}
+
+func newRegistry() *registry {
+	return &registry{
+		byHash: make(map[string]*file),
+		byName: make(map[string]*file),
+		mutex:  &sync.RWMutex{},
+	}
+}
+
+// add добавляет файл, если файла с таким хэшем еще нет, и возвращает файл из реестра
+func (r *registry) add(f *file) *file {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if is, ok := r.byHash[f.hash]; ok {
+		return is
+	}
+
+	r.byHash[f.hash] = f
+	r.byName[f.name] = f
+
+	return f
+}
+
+// remove убирает файл, чтобы его куски больше не раздавались
+func (r *registry) remove(hash string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	f, ok := r.byHash[hash]
+	if !ok {
+		return
+	}
+
+	delete(r.byHash, hash)
+	if r.byName[f.name] == f {
+		delete(r.byName, f.name)
+	}
+}
+
+func (r *registry) getByHash(hash string) (*file, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	f, ok := r.byHash[hash]
+	return f, ok
+}
+
+func (r *registry) getByName(name string) (*file, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	f, ok := r.byName[name]
+	return f, ok
+}
+
+// piece возвращает кусок файла, если он уже есть у пира
+func (r *registry) piece(hash string, serial uint64) (*api.Piece, bool) {
+	f, ok := r.getByHash(hash)
+	if !ok {
+		return nil, false
+	}
+
+	return f.getPiece(serial)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/elizarpif/grpctorrent/api"
+)
+
+func testFile(name, hash string) *file {
+	return newEmptyFile(&api.FileInfo{Name: name, Hash: hash, Length: 2, PieceLength: 1, Pieces: 2})
+}
+
+func TestRegistry(t *testing.T) {
+	r := newRegistry()
+
+	first := testFile("a.txt", privateHash)
+	if got := r.add(first); got != first {
+		t.Fatal("add returned another file")
+	}
+
+	// файл с тем же хэшем не заменяет зарегистрированный, даже под другим именем
+	if got := r.add(testFile("b.txt", privateHash)); got != first {
+		t.Error("second add of the same hash replaced the file")
+	}
+	if _, ok := r.getByName("b.txt"); ok {
+		t.Error("duplicate is listed by its name")
+	}
+
+	if f, ok := r.getByName("a.txt"); !ok || f != first {
+		t.Errorf("getByName = %v, %v", f, ok)
+	}
+
+	// куска еще нет, после setPiece он раздается
+	if _, ok := r.piece(privateHash, 0); ok {
+		t.Error("piece is served before it is downloaded")
+	}
+	first.setPiece(&api.Piece{SerialNumber: 0, Payload: []byte{1}})
+	if piece, ok := r.piece(privateHash, 0); !ok || piece.Payload[0] != 1 {
+		t.Errorf("piece = %v, %v", piece, ok)
+	}
+	if _, ok := r.piece(publicHash, 0); ok {
+		t.Error("piece of an unknown file")
+	}
+
+	r.remove(privateHash)
+	if _, ok := r.getByHash(privateHash); ok {
+		t.Error("removed file is found by hash")
+	}
+	if _, ok := r.getByName("a.txt"); ok {
+		t.Error("removed file is found by name")
+	}
+	if _, ok := r.piece(privateHash, 0); ok {
+		t.Error("removed file still serves pieces")
+	}
+
+	r.remove(privateHash) // повторное удаление ничего не ломает
+}
+
+func TestRegistryRemoveKeepsNamesake(t *testing.T) {
+	r := newRegistry()
+
+	// одноименный файл с другим хэшем занимает имя
+	r.add(testFile("a.txt", privateHash))
+	other := r.add(testFile("a.txt", publicHash))
+
+	r.remove(privateHash)
+
+	if f, ok := r.getByName("a.txt"); !ok || f != other {
+		t.Errorf("getByName after removing the older file = %v, %v", f, ok)
+	}
+	if _, ok := r.getByHash(publicHash); !ok {
+		t.Error("namesake is removed")
+	}
+}
+
+func TestRegistryConcurrent(t *testing.T) {
+	r := newRegistry()
+	wg := &sync.WaitGroup{}
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				hash := strconv.Itoa(j)
+				f := r.add(testFile(hash, hash))
+				f.setPiece(&api.Piece{SerialNumber: uint64(i % 2)})
+				r.piece(hash, 0)
+
+				if j%3 == 0 {
+					r.remove(hash)
+				}
+			}
+		}(i)
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/elizarpif/grpctorrent/api"
+	"github.com/elizarpif/grpctorrent/api/bitfield"
+	"github.com/elizarpif/logger"
+)
+
+const (
+	reportInterval = 2 * time.Second // как часто отправлять накопленные куски
+	reportBatch    = 64              // столько кусков отправляются, не дожидаясь интервала
+	reportTimeout  = 10 * time.Second
+
+	// пока трекер недоступен, пауза между попытками растет от минимальной до максимальной
+	reportMinBackoff = time.Second
+	reportMaxBackoff = time.Minute
+)
+
+// reporter копит скачанные куски и сообщает о них трекеру пачками
+// через PostPieceReport. Скачивание его не ждет: если трекер недоступен,
+// куски остаются в очереди до следующей попытки. При остановке пира
+// остаток очереди отправляется последней попыткой.
+type reporter struct {
+	tracker api.TrackerClient
+
+	interval   time.Duration
+	minBackoff time.Duration
+	maxBackoff time.Duration
+
+	pending map[string]*bitfield.Bitfield // хэш файла - еще не отправленные куски
+	count   int                           // сколько кусков в pending
+	wake    chan struct{}                 // набралась пачка, пора отправлять
+
+	mutex *sync.Mutex
+}
+
+func newReporter(tracker api.TrackerClient) *reporter {
+	return &reporter{
+		tracker:    tracker,
+		interval:   reportInterval,
+		minBackoff: reportMinBackoff,
+		maxBackoff: reportMaxBackoff,
+		pending:    make(map[string]*bitfield.Bitfield),
+		wake:       make(chan struct{}, 1),
+		mutex:      &sync.Mutex{},
+	}
+}
+
+// add ставит кусок в очередь. pieces - сколько кусков в файле.
+func (r *reporter) add(hash string, pieces, serial uint64) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	bf, ok := r.pending[hash]
+	if !ok {
+		bf = bitfield.New(pieces)
+		r.pending[hash] = bf
+	}
+
+	if bf.Has(serial) {
+		return
+	}
+
+	bf.Set(serial)
+	r.count++
+
+	if r.count >= reportBatch {
+		select {
+		case r.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// run отправляет очередь по интервалу или по заполнению пачки, пока не отменят ctx
+func (r *reporter) run(ctx context.Context) error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	backoff := r.minBackoff
+
+	for {
+		select {
+		case <-ctx.Done():
+			return r.stop(ctx)
+		case <-ticker.C:
+		case <-r.wake:
+		}
+
+		if r.flush(ctx) {
+			backoff = r.minBackoff
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return r.stop(ctx)
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > r.maxBackoff {
+			backoff = r.maxBackoff
+		}
+	}
+}
+
+// stop отправляет остаток очереди: ctx уже отменен, поэтому запрос идет
+// в новом контексте со своим таймаутом. Не принятые трекером куски теряются.
+func (r *reporter) stop(ctx context.Context) error {
+	r.flush(logger.SetContext(logger.GetLogger(ctx)))
+
+	return ctx.Err()
+}
+
+// flush отправляет очередь одним запросом. Если трекер не ответил,
+// куски возвращаются в очередь и flush сообщает о неудаче.
+func (r *reporter) flush(ctx context.Context) bool {
+	batch := r.take()
+	if len(batch) == 0 {
+		return true
+	}
+
+	report := &api.PieceReport{}
+	for hash, bf := range batch {
+		report.Files = append(report.Files, &api.PieceInfo{HashFile: hash, Pieces: bf.Encode()})
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx, reportTimeout)
+	defer cancel()
+
+	res, err := r.tracker.PostPieceReport(reqCtx, report)
+	if err != nil {
+		logger.GetLogger(ctx).WithError(err).WithField("files", len(batch)).Error("cannot post piece report")
+		r.putBack(batch)
+		return false
+	}
+
+	// отклоненные куски трекер не примет и при повторе, их не возвращаем
+	for _, hash := range res.Rejected {
+		logger.GetLogger(ctx).WithField("hash", hash).Error("tracker rejected piece report")
+	}
+
+	return true
+}
+
+func (r *reporter) take() map[string]*bitfield.Bitfield {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	batch := r.pending
+	r.pending = make(map[string]*bitfield.Bitfield)
+	r.count = 0
+
+	return batch
+}
+
+// putBack возвращает неотправленные куски, объединяя их с добавленными за это время
+func (r *reporter) putBack(batch map[string]*bitfield.Bitfield) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for hash, bf := range batch {
+		cur, ok := r.pending[hash]
+		if !ok {
+			r.pending[hash] = bf
+			r.count += int(bf.Count())
+			continue
+		}
+
+		for _, serial := range bf.Pieces() {
+			if !cur.Has(serial) {
+				cur.Set(serial)
+				r.count++
+			}
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/elizarpif/grpctorrent/api"
+	"github.com/elizarpif/grpctorrent/api/bitfield"
+	"google.golang.org/grpc"
+)
+
+const reportPieces = 200 // кусков в файлах тестов
+
+// reportTracker - трекер, который отвергает первые fail отчетов, а остальные запоминает
+type reportTracker struct {
+	api.TrackerClient
+
+	fail     int
+	rejected []string // хэши, которые трекер не примет
+
+	calls   []time.Time
+	reports []*api.PieceReport
+	posted  chan struct{} // принят отчет
+
+	mutex *sync.Mutex
+}
+
+func newReportTracker(fail int) *reportTracker {
+	return &reportTracker{fail: fail, posted: make(chan struct{}, 100), mutex: &sync.Mutex{}}
+}
+
+func (t *reportTracker) PostPieceReport(ctx context.Context, in *api.PieceReport, opts ...grpc.CallOption) (*api.PieceReportResult, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.calls = append(t.calls, time.Now())
+	if len(t.calls) <= t.fail {
+		return nil, errors.New("tracker is down")
+	}
+
+	t.reports = append(t.reports, in)
+	t.posted <- struct{}{}
+
+	return &api.PieceReportResult{Rejected: t.rejected}, nil
+}
+
+// reported - принятые куски по файлам
+func (t *reportTracker) reported(tb testing.TB) map[string][]uint64 {
+	tb.Helper()
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	res := make(map[string][]uint64)
+	for _, report := range t.reports {
+		for _, f := range report.Files {
+			bf, err := bitfield.Decode(f.Pieces, reportPieces)
+			if err != nil {
+				tb.Fatal(err)
+			}
+			res[f.HashFile] = append(res[f.HashFile], bf.Pieces()...)
+		}
+	}
+
+	return res
+}
+
+// attempts ждет, пока трекер получит хотя бы n запросов
+func (t *reportTracker) attempts(tb testing.TB, n int) {
+	tb.Helper()
+
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(time.Millisecond) {
+		t.mutex.Lock()
+		got := len(t.calls)
+		t.mutex.Unlock()
+
+		if got >= n {
+			return
+		}
+		if time.Now().After(deadline) {
+			tb.Fatalf("%d report attempts, want %d", got, n)
+		}
+	}
+}
+
+func (t *reportTracker) wait(tb testing.TB) {
+	tb.Helper()
+
+	select {
+	case <-t.posted:
+	case <-time.After(5 * time.Second):
+		tb.Fatal("no report posted")
+	}
+}
+
+func TestReporterBatch(t *testing.T) {
+	tracker := newReportTracker(0)
+	r := newReporter(tracker)
+
+	r.add(privateHash, reportPieces, 0)
+	r.add(privateHash, reportPieces, 5)
+	r.add(privateHash, reportPieces, 5) // повтор не считается
+	r.add(publicHash, reportPieces, 1)
+
+	if r.count != 3 || len(r.wake) != 0 {
+		t.Errorf("count %d, wake %d before a full batch", r.count, len(r.wake))
+	}
+
+	// все файлы уходят одним запросом
+	if !r.flush(context.Background()) || len(tracker.reports) != 1 || len(tracker.reports[0].Files) != 2 {
+		t.Fatalf("reports = %v", tracker.reports)
+	}
+	if got, want := tracker.reported(t), map[string][]uint64{privateHash: {0, 5}, publicHash: {1}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("reported %v, want %v", got, want)
+	}
+
+	// пустая очередь к трекеру не ходит
+	if !r.flush(context.Background()) || len(tracker.calls) != 1 {
+		t.Errorf("empty flush made a request: %d calls", len(tracker.calls))
+	}
+
+	// полная пачка будит отправку, не дожидаясь интервала
+	for i := uint64(0); i < reportBatch; i++ {
+		r.add(publicHash, reportPieces, i)
+	}
+	if len(r.wake) != 1 {
+		t.Error("full batch did not wake the reporter")
+	}
+}
+
+func TestReporterPutBack(t *testing.T) {
+	tracker := newReportTracker(1)
+	r := newReporter(tracker)
+
+	r.add(privateHash, reportPieces, 0)
+	r.add(privateHash, reportPieces, 1)
+
+	if r.flush(context.Background()) {
+		t.Fatal("failed report counted as sent")
+	}
+
+	// куски вернулись в очередь и объединились с добавленными после
+	r.add(privateHash, reportPieces, 1)
+	r.add(privateHash, reportPieces, 2)
+	r.add(publicHash, reportPieces, 3)
+
+	if r.count != 4 {
+		t.Errorf("count = %d after put back, want 4", r.count)
+	}
+
+	tracker.rejected = []string{publicHash}
+	if !r.flush(context.Background()) {
+		t.Fatal("second flush failed")
+	}
+	if got, want := tracker.reported(t), map[string][]uint64{privateHash: {0, 1, 2}, publicHash: {3}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("reported %v, want %v", got, want)
+	}
+
+	// отвергнутые трекером куски не повторяются
+	if r.count != 0 || len(r.pending) != 0 {
+		t.Errorf("pending after a rejected report: %v", r.pending)
+	}
+}
+
+func TestReporterBackoff(t *testing.T) {
+	const failures = 4
+
+	tracker := newReportTracker(failures)
+	r := newReporter(tracker)
+	r.interval = 10 * time.Millisecond
+	r.minBackoff = 20 * time.Millisecond
+	r.maxBackoff = 50 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- r.run(ctx) }()
+
+	r.add(privateHash, reportPieces, 0)
+	tracker.attempts(t, 2)
+
+	// куски, добавленные во время неудач, уходят вместе с первыми
+	r.add(privateHash, reportPieces, 1)
+	tracker.wait(t)
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("run = %v", err)
+	}
+
+	tracker.mutex.Lock()
+	calls := append([]time.Time{}, tracker.calls...)
+	tracker.mutex.Unlock()
+
+	if len(calls) != failures+1 || len(tracker.reports) != 1 {
+		t.Fatalf("%d requests and %d reports, want %d and 1", len(calls), len(tracker.reports), failures+1)
+	}
+
+	// паузы после неудач растут вдвое до максимума
+	for i, backoff := range []time.Duration{20, 40, 50, 50} {
+		if gap := calls[i+1].Sub(calls[i]); gap < backoff*time.Millisecond {
+			t.Errorf("gap after failure %d is %v, want at least %v ms", i+1, gap, backoff)
+		}
+	}
+
+	if got := tracker.reported(t)[privateHash]; !reflect.DeepEqual(got, []uint64{0, 1}) {
+		t.Errorf("reported %v", got)
+	}
+}
+
+func TestReporterFlushOnStop(t *testing.T) {
+	tests := []struct {
+		name string
+		fail int // столько первых отчетов трекер не примет
+	}{
+		{name: "waiting for the interval"},
+		// трекер вернулся, пока пир ждал следующей попытки
+		{name: "waiting after a failure", fail: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := newReportTracker(tt.fail)
+			r := newReporter(tracker)
+			r.minBackoff = time.Hour
+
+			if tt.fail > 0 {
+				r.interval = time.Millisecond
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+
+			done := make(chan error, 1)
+			go func() { done <- r.run(ctx) }()
+
+			r.add(privateHash, reportPieces, 7)
+
+			// до отмены трекер успевает отвергнуть все неудачные отчеты
+			tracker.attempts(t, tt.fail)
+
+			cancel()
+			if err := <-done; !errors.Is(err, context.Canceled) {
+				t.Errorf("run = %v", err)
+			}
+
+			if got := tracker.reported(t)[privateHash]; !reflect.DeepEqual(got, []uint64{7}) {
+				t.Errorf("reported on stop %v, want [7]", got)
+			}
+		})
+	}
+}
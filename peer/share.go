@@ -0,0 +1,131 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// shareRoots - каталоги, файлы из которых пир может раздавать.
+// Путь из запроса на загрузку раскрывается до настоящего, без симлинков,
+// и должен лежать внутри одного из корней.
+type shareRoots struct {
+	roots   []string // абсолютные пути без симлинков
+	include []string // если заданы, раздаются только подходящие файлы
+	exclude []string
+}
+
+// newShareRoots проверяет корни и шаблоны. Шаблоны в синтаксисе filepath.Match
+// сравниваются с путем относительно корня и с именем файла.
+func newShareRoots(roots, include, exclude []string) (*shareRoots, error) {
+	if len(roots) == 0 {
+		return nil, errors.New("no share roots")
+	}
+
+	s := &shareRoots{include: include, exclude: exclude}
+
+	for _, root := range roots {
+		abs, err := filepath.Abs(root)
+		if err != nil {
+			return nil, err
+		}
+
+		real, err := filepath.EvalSymlinks(abs)
+		if err != nil {
+			return nil, err
+		}
+
+		s.roots = append(s.roots, real)
+	}
+
+	for _, pattern := range append(append([]string(nil), include...), exclude...) {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, errors.New("invalid share pattern " + pattern)
+		}
+	}
+
+	return s, nil
+}
+
+// resolve возвращает настоящий путь к файлу, если его можно раздавать.
+// Относительный путь считается от первого корня.
+func (s *shareRoots) resolve(name string) (string, error) {
+	if name == "" {
+		return "", status.Error(codes.InvalidArgument, "empty file name")
+	}
+
+	if !filepath.IsAbs(name) {
+		name = filepath.Join(s.roots[0], name)
+	}
+
+	name = filepath.Clean(name)
+
+	real, err := filepath.EvalSymlinks(name)
+	if err != nil {
+		// не подсказываем, какие файлы есть вне корней
+		if _, ok := s.relative(name); !ok {
+			return "", status.Error(codes.PermissionDenied, "file is outside share roots")
+		}
+		return "", status.Error(codes.NotFound, "cannot find file")
+	}
+
+	rel, ok := s.relative(real)
+	if !ok {
+		return "", status.Error(codes.PermissionDenied, "file is outside share roots")
+	}
+
+	if !s.allowed(rel) {
+		return "", status.Error(codes.PermissionDenied, "file is excluded from sharing")
+	}
+
+	fi, err := os.Stat(real)
+	if err != nil || !fi.Mode().IsRegular() {
+		return "", status.Error(codes.InvalidArgument, "not a regular file")
+	}
+
+	return real, nil
+}
+
+// relative - путь относительно корня, в котором лежит файл
+func (s *shareRoots) relative(real string) (string, bool) {
+	for _, root := range s.roots {
+		rel, err := filepath.Rel(root, real)
+		if err != nil {
+			continue
+		}
+
+		if rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return rel, true
+		}
+	}
+
+	return "", false
+}
+
+func (s *shareRoots) allowed(rel string) bool {
+	if matchAny(s.exclude, rel) {
+		return false
+	}
+
+	return len(s.include) == 0 || matchAny(s.include, rel)
+}
+
+func matchAny(patterns []string, rel string) bool {
+	base := filepath.Base(rel)
+
+	for _, pattern := range patterns {
+		// ошибки шаблонов отсеяны в newShareRoots
+		if ok, _ := filepath.Match(pattern, rel); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, base); ok {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// shareTree создает каталог для проверок:
+//
+//	root/a.txt, root/sub/b.txt, root/.hidden, root/secret.key
+//	root/link-in -> sub/b.txt, root/link-out -> ../outside/x.txt, root/dir-out -> ../outside
+//	root2/c.txt, rootmore/d.txt (имя начинается с имени корня)
+//	outside/x.txt, outside/link-root -> ../root/a.txt
+func shareTree(t *testing.T) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "share")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// временный каталог сам может быть за симлинком
+	dir, err = filepath.EvalSymlinks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, d := range []string{"root/sub", "root2", "rootmore", "outside"} {
+		if err := os.MkdirAll(filepath.Join(dir, d), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, f := range []string{"root/a.txt", "root/sub/b.txt", "root/.hidden", "root/secret.key", "root2/c.txt", "rootmore/d.txt", "outside/x.txt"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, f), []byte(f), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	links := map[string]string{
+		"root/link-in":      "sub/b.txt",
+		"root/link-out":     "../outside/x.txt",
+		"root/dir-out":      "../outside",
+		"outside/link-root": "../root/a.txt",
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(dir, name)); err != nil {
+			t.Skipf("symlinks are unavailable: %v", err)
+		}
+	}
+
+	return dir
+}
+
+func TestShareRootsResolve(t *testing.T) {
+	dir := shareTree(t)
+	defer os.RemoveAll(dir)
+
+	s, err := newShareRoots([]string{filepath.Join(dir, "root"), filepath.Join(dir, "root2")}, nil, []string{".*", "*.key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := func(rel string) string { return filepath.Join(dir, rel) }
+
+	tests := []struct {
+		name string
+		file string
+		want string     // настоящий путь при успехе
+		code codes.Code // код ошибки
+	}{
+		{name: "relative", file: "a.txt", want: path("root/a.txt")},
+		{name: "nested", file: "sub/b.txt", want: path("root/sub/b.txt")},
+		{name: "absolute inside", file: path("root/sub/b.txt"), want: path("root/sub/b.txt")},
+		{name: "second root", file: path("root2/c.txt"), want: path("root2/c.txt")},
+		{name: "dot dot inside", file: "sub/../a.txt", want: path("root/a.txt")},
+		{name: "symlink inside", file: "link-in", want: path("root/sub/b.txt")},
+		{name: "symlink from outside into root", file: path("outside/link-root"), want: path("root/a.txt")},
+		{name: "dot dot to second root", file: "../root2/c.txt", want: path("root2/c.txt")},
+
+		{name: "dot dot", file: "../outside/x.txt", code: codes.PermissionDenied},
+		{name: "dot dot through sub", file: "sub/../../outside/x.txt", code: codes.PermissionDenied},
+		{name: "absolute outside", file: path("outside/x.txt"), code: codes.PermissionDenied},
+		{name: "absolute system file", file: "/etc/passwd", code: codes.PermissionDenied},
+		{name: "root name prefix", file: path("rootmore/d.txt"), code: codes.PermissionDenied},
+		{name: "symlink escaping", file: "link-out", code: codes.PermissionDenied},
+		{name: "directory symlink escaping", file: "dir-out/x.txt", code: codes.PermissionDenied},
+		{name: "missing outside", file: "../outside/missing.txt", code: codes.PermissionDenied},
+		{name: "missing inside", file: "missing.txt", code: codes.NotFound},
+		{name: "excluded dotfile", file: ".hidden", code: codes.PermissionDenied},
+		{name: "excluded key", file: "secret.key", code: codes.PermissionDenied},
+		{name: "directory", file: "sub", code: codes.InvalidArgument},
+		{name: "root itself", file: ".", code: codes.PermissionDenied}, // "." подходит под ".*"
+		{name: "empty", file: "", code: codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.resolve(tt.file)
+
+			if tt.want != "" {
+				if err != nil || got != tt.want {
+					t.Errorf("resolve(%q) = %q, %v, want %q", tt.file, got, err, tt.want)
+				}
+				return
+			}
+
+			if status.Code(err) != tt.code {
+				t.Errorf("resolve(%q) = %q, %v, want code %v", tt.file, got, err, tt.code)
+			}
+		})
+	}
+}
+
+func TestShareRootsInclude(t *testing.T) {
+	dir := shareTree(t)
+	defer os.RemoveAll(dir)
+
+	s, err := newShareRoots([]string{filepath.Join(dir, "root")}, []string{"sub/*"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.resolve("sub/b.txt"); err != nil {
+		t.Errorf("included file rejected: %v", err)
+	}
+
+	// проверяется настоящий путь, а не имя симлинка
+	if _, err := s.resolve("link-in"); err != nil {
+		t.Errorf("symlink to an included file rejected: %v", err)
+	}
+
+	if _, err := s.resolve("a.txt"); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("file outside include patterns: %v, want PermissionDenied", err)
+	}
+}
+
+func TestShareRootThroughSymlink(t *testing.T) {
+	dir := shareTree(t)
+	defer os.RemoveAll(dir)
+
+	// корень сам задан симлинком - сравниваются настоящие пути
+	link := filepath.Join(dir, "root-link")
+	if err := os.Symlink("root", link); err != nil {
+		t.Skipf("symlinks are unavailable: %v", err)
+	}
+
+	s, err := newShareRoots([]string{link}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := s.resolve(filepath.Join(link, "a.txt"))
+	if err != nil || got != filepath.Join(dir, "root", "a.txt") {
+		t.Errorf("resolve through root symlink = %q, %v", got, err)
+	}
+
+	if _, err := s.resolve(filepath.Join(link, "..", "outside", "x.txt")); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("dot dot from root symlink: %v, want PermissionDenied", err)
+	}
+}
+
+func TestNewShareRootsRejects(t *testing.T) {
+	dir := shareTree(t)
+	defer os.RemoveAll(dir)
+
+	root := filepath.Join(dir, "root")
+
+	tests := []struct {
+		name             string
+		roots            []string
+		include, exclude []string
+	}{
+		{name: "no roots"},
+		{name: "missing root", roots: []string{filepath.Join(dir, "missing")}},
+		{name: "bad include", roots: []string{root}, include: []string{"["}},
+		{name: "bad exclude", roots: []string{root}, exclude: []string{"[a-"}},
+	}
+
+	for _, tt := range tests {
+		if _, err := newShareRoots(tt.roots, tt.include, tt.exclude); err == nil {
+			t.Errorf("%s: newShareRoots accepted", tt.name)
+		}
+	}
+}
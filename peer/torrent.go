@@ -0,0 +1,113 @@
+//nolint:gosec // sha1 задан форматом торрент-файлов BitTorrent v1
+package main
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"sync"
+
+	"github.com/elizarpif/grpctorrent/api"
+	"github.com/elizarpif/grpctorrent/api/bencode"
+)
+
+// decodeTorrent разбирает торрент-файл BitTorrent v1 с одним файлом.
+// Хэшем файла в нашей сети становится infohash, куски проверяются по sha1 из pieces.
+func decodeTorrent(data []byte) (*api.FileInfo, []string, error) {
+	v, err := bencode.Unmarshal(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	doc, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, nil, errors.New("torrent is not a dictionary")
+	}
+
+	infoDict, ok := doc["info"].(map[string]interface{})
+	if !ok {
+		return nil, nil, errors.New("torrent has no info dictionary")
+	}
+
+	if _, ok := infoDict["files"]; ok {
+		return nil, nil, errors.New("only single-file torrents are supported")
+	}
+
+	name, _ := infoDict["name"].(string)
+	pieces, _ := infoDict["pieces"].(string)
+	length, lok := infoDict["length"].(int64)
+	pieceLength, pok := infoDict["piece length"].(int64)
+
+	if name == "" || !lok || !pok || length < 0 || pieceLength <= 0 {
+		return nil, nil, errors.New("torrent misses file fields")
+	}
+
+	if len(pieces)%sha1.Size != 0 || uint64(len(pieces)/sha1.Size) != pieceCount(uint64(length), uint64(pieceLength)) {
+		return nil, nil, errors.New("piece hashes dont match file length")
+	}
+
+	// infohash считается от байтов словаря info как они есть в файле
+	raw, err := bencode.Raw(data, "info")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	infohash := sha1.Sum(raw)
+
+	info := &api.FileInfo{
+		Name:        name,
+		PieceLength: uint64(pieceLength),
+		Pieces:      uint64(len(pieces) / sha1.Size),
+		Length:      uint64(length),
+		Hash:        hex.EncodeToString(infohash[:]),
+		PieceHash:   api.PieceHash_SHA1,
+	}
+
+	if private, _ := infoDict["private"].(int64); private == 1 {
+		info.Private = true
+		info.Visibility = api.Visibility_PRIVATE
+	}
+
+	for i := 0; i < len(pieces); i += sha1.Size {
+		info.PieceHashes = append(info.PieceHashes, []byte(pieces[i:i+sha1.Size]))
+	}
+
+	return info, announceList(doc), nil
+}
+
+// newTorrentFile читает файл для раздачи по торрент-файлу: содержимое делится
+// на куски длины из торрента и каждый кусок сверяется с его sha1
+func newTorrentFile(name string, info *api.FileInfo) (*file, error) {
+	content, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if uint64(len(content)) != info.Length {
+		return nil, errors.New("file length doesnt match torrent")
+	}
+
+	pMap := splitFileBy(content, int(info.PieceLength))
+
+	for serial, piece := range pMap {
+		if !bytes.Equal(hashPiece(api.PieceHash_SHA1, piece.Payload), info.PieceHashes[serial]) {
+			return nil, errors.New("file doesnt match torrent")
+		}
+	}
+
+	return &file{
+		name:      info.Name,
+		hash:      info.Hash,
+		allPieces: true,
+		length:    info.Length,
+		piecesLen: info.PieceLength,
+		pieces:    info.Pieces,
+		piecesMap: pMap,
+		mutex:     &sync.RWMutex{},
+
+		pieceHashes: info.PieceHashes,
+		pieceHash:   api.PieceHash_SHA1,
+	}, nil
+}
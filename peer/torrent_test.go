@@ -0,0 +1,200 @@
+//nolint:gosec // sha1 задан форматом торрент-файлов BitTorrent v1
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/elizarpif/grpctorrent/api"
+	"github.com/elizarpif/grpctorrent/api/bencode"
+)
+
+const torrentContent = "hello, torrent world!!" // 22 байта - три куска по 8
+
+// torrentInfo - словарь info торрент-файла с torrentContent, change заменяет или удаляет (nil) поля
+func torrentInfo(change map[string]interface{}) map[string]interface{} {
+	var pieces []byte
+	for i := 0; i < len(torrentContent); i += 8 {
+		end := i + 8
+		if end > len(torrentContent) {
+			end = len(torrentContent)
+		}
+
+		sum := sha1.Sum([]byte(torrentContent[i:end]))
+		pieces = append(pieces, sum[:]...)
+	}
+
+	info := map[string]interface{}{
+		"name":         "hello.txt",
+		"length":       len(torrentContent),
+		"piece length": 8,
+		"pieces":       pieces,
+	}
+	for k, v := range change {
+		if v == nil {
+			delete(info, k)
+			continue
+		}
+		info[k] = v
+	}
+
+	return info
+}
+
+func marshal(t *testing.T, v interface{}) []byte {
+	t.Helper()
+
+	data, err := bencode.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+func TestDecodeTorrent(t *testing.T) {
+	info := torrentInfo(map[string]interface{}{"private": 1})
+	data := marshal(t, map[string]interface{}{
+		"announce":      "http://tracker:8000/announce",
+		"announce-list": []interface{}{[]string{"localhost:9000"}, []string{"http://tracker:8000/announce"}},
+		"info":          info,
+	})
+
+	got, trackers, err := decodeTorrent(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// infohash - sha1 от байтов словаря info
+	infohash := sha1.Sum(marshal(t, info))
+
+	if got.Hash != hex.EncodeToString(infohash[:]) {
+		t.Errorf("hash = %s, want infohash %x", got.Hash, infohash)
+	}
+	if got.Name != "hello.txt" || got.Length != 22 || got.PieceLength != 8 || got.Pieces != 3 {
+		t.Errorf("unexpected file fields %+v", got)
+	}
+	if got.PieceHash != api.PieceHash_SHA1 || len(got.PieceHashes) != 3 || len(got.PieceHashes[2]) != sha1.Size {
+		t.Errorf("unexpected piece hashes %v %v", got.PieceHash, got.PieceHashes)
+	}
+	if !got.Private || got.Visibility != api.Visibility_PRIVATE {
+		t.Error("private torrent decoded as public")
+	}
+
+	want := []string{"localhost:9000", "http://tracker:8000/announce"}
+	if !reflect.DeepEqual(trackers, want) {
+		t.Errorf("trackers = %v, want %v", trackers, want)
+	}
+}
+
+func TestDecodeTorrentInfohashKeepsBytes(t *testing.T) {
+	// неизвестные поля info тоже входят в infohash
+	plain, _, err := decodeTorrent(marshal(t, map[string]interface{}{"info": torrentInfo(nil)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sourced, _, err := decodeTorrent(marshal(t, map[string]interface{}{
+		"info": torrentInfo(map[string]interface{}{"source": "tracker"}),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if plain.Hash == sourced.Hash {
+		t.Error("extra info field doesnt change the infohash")
+	}
+}
+
+func TestDecodeTorrentMalformed(t *testing.T) {
+	doc := func(change map[string]interface{}) []byte {
+		return marshal(t, map[string]interface{}{"info": torrentInfo(change)})
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "not bencode", data: []byte("d4:info")},
+		{name: "not a dictionary", data: []byte("l4:infoe")},
+		{name: "no info", data: []byte("d8:announce3:urle")},
+		{name: "info is a list", data: []byte("d4:infolee")},
+		{name: "multi-file", data: doc(map[string]interface{}{"files": []interface{}{}})},
+		{name: "no name", data: doc(map[string]interface{}{"name": nil})},
+		{name: "no length", data: doc(map[string]interface{}{"length": nil})},
+		{name: "negative length", data: doc(map[string]interface{}{"length": -1})},
+		{name: "zero piece length", data: doc(map[string]interface{}{"piece length": 0})},
+		{name: "piece length is a string", data: doc(map[string]interface{}{"piece length": "8"})},
+		{name: "too few pieces", data: doc(map[string]interface{}{"length": 100})},
+		{name: "too many pieces", data: doc(map[string]interface{}{"length": 8})},
+		{name: "cut piece hash", data: doc(map[string]interface{}{"pieces": strings.Repeat("x", 3*sha1.Size-1)})},
+	}
+
+	for _, tt := range tests {
+		if info, _, err := decodeTorrent(tt.data); err == nil {
+			t.Errorf("%s: decodeTorrent = %+v, want error", tt.name, info)
+		}
+	}
+}
+
+func TestDecodeTorrentHugeLength(t *testing.T) {
+	data := marshal(t, map[string]interface{}{"info": torrentInfo(map[string]interface{}{
+		"length":       int64(1<<63 - 1),
+		"piece length": int64(1 << 62),
+		"pieces":       strings.Repeat("x", 2*sha1.Size),
+	})})
+
+	info, _, err := decodeTorrent(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Pieces != 2 {
+		t.Errorf("pieces = %d, want 2", info.Pieces)
+	}
+}
+
+func TestNewTorrentFile(t *testing.T) {
+	info, _, err := decodeTorrent(marshal(t, map[string]interface{}{"info": torrentInfo(nil)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		ok      bool
+	}{
+		{name: "matching", content: torrentContent, ok: true},
+		{name: "changed byte", content: strings.Replace(torrentContent, "w", "W", 1)},
+		{name: "shorter", content: torrentContent[:20]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := ioutil.TempFile("", "torrent")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.Remove(f.Name())
+
+			_, err = f.WriteString(tt.content)
+			f.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := newTorrentFile(f.Name(), info)
+			if (err == nil) != tt.ok {
+				t.Fatalf("newTorrentFile error = %v, want ok %v", err, tt.ok)
+			}
+			if err == nil && (got.hash != info.Hash || len(got.piecesMap) != 3) {
+				t.Errorf("unexpected file %+v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/elizarpif/grpctorrent/api"
+	"github.com/elizarpif/logger"
+	"google.golang.org/grpc"
+)
+
+const trackerTimeout = 10 * time.Second // сколько ждать ответа чужого трекера
+
+// grpcTrackers - трекеры из описания файла, с которыми можно говорить по grpc, кроме своего.
+// Адреса с url (http://.../announce, udp://...) - трекеры BitTorrent, они знают
+// только BitTorrent-клиентов, которые не отдают куски по grpc.
+func grpcTrackers(list []string, own string) []string {
+	var res []string
+	for _, addr := range list {
+		if strings.Contains(addr, "://") || addr == own || contains(res, addr) {
+			continue
+		}
+
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			continue
+		}
+
+		res = append(res, addr)
+	}
+
+	return res
+}
+
+// dialTracker соединяется с чужим трекером. Запросы подписываются, как и к своему,
+// но для адреса чужого трекера: свой трекер такой запрос не примет.
+func (p *Peer) dialTracker(ctx context.Context, addr string) (*grpc.ClientConn, error) {
+	return grpc.DialContext(ctx, addr, p.transport, grpc.WithUnaryInterceptor(p.signer.unaryInterceptor))
+}
+
+// infoFromTrackers берет описание файла у первого трекера из ссылки, который его отдал
+func (p *Peer) infoFromTrackers(ctx context.Context, hash string, trackers []string) (*api.FileInfo, error) {
+	for _, addr := range trackers {
+		info, err := p.infoFrom(ctx, addr, hash)
+		if err != nil {
+			logger.GetLogger(ctx).WithError(err).WithField("tracker", addr).Debug("no file info from tracker")
+			continue
+		}
+
+		return info, nil
+	}
+
+	return nil, errors.New("no tracker has the file")
+}
+
+func (p *Peer) infoFrom(ctx context.Context, addr, hash string) (*api.FileInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, trackerTimeout)
+	defer cancel()
+
+	conn, err := p.dialTracker(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	info, err := api.NewTrackerClient(conn).GetFileInfo(ctx, &api.DownloadFileRequest{Hash: hash})
+	if err != nil {
+		return nil, err
+	}
+
+	return info, checkRemoteInfo(info, hash)
+}
+
+// peersFromTrackers спрашивает пиров файла у трекеров из его описания или ссылки.
+// Недоступный трекер пропускается.
+func (p *Peer) peersFromTrackers(ctx context.Context, req *api.GetPeersRequest, trackers []string) []*api.ListPeers {
+	var lists []*api.ListPeers
+
+	for _, addr := range trackers {
+		list, err := p.peersFrom(ctx, addr, req)
+		if err != nil {
+			logger.GetLogger(ctx).WithError(err).WithField("tracker", addr).Error("cannot get peers from tracker")
+			continue
+		}
+
+		lists = append(lists, list)
+	}
+
+	return lists
+}
+
+func (p *Peer) peersFrom(ctx context.Context, addr string, req *api.GetPeersRequest) (*api.ListPeers, error) {
+	ctx, cancel := context.WithTimeout(ctx, trackerTimeout)
+	defer cancel()
+
+	conn, err := p.dialTracker(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	return api.NewTrackerClient(conn).GetPeers(ctx, req)
+}
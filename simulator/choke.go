@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// chokePolicy решает, каких заинтересованных соседей раздающий пир обслуживает
+type chokePolicy interface {
+	// onDemand - слоты раздаются по запросу, без периодического пересмотра
+	onDemand() bool
+	rechoke(s *sim, u *peer, interested []int) []int
+}
+
+func newChokePolicy(name string) (chokePolicy, error) {
+	switch name {
+	case "all":
+		return unchokeAll{}, nil
+	case "random":
+		return randomUnchoke{}, nil
+	case "tit-for-tat":
+		return titForTat{optimisticEvery: 3}, nil
+	}
+
+	return nil, fmt.Errorf("unknown choke policy %q", name)
+}
+
+// unchokeAll - поведение текущего пира: отдаем всем, кто спросил, пока есть слоты
+type unchokeAll struct{}
+
+func (unchokeAll) onDemand() bool { return true }
+
+func (unchokeAll) rechoke(*sim, *peer, []int) []int { return nil }
+
+// randomUnchoke - каждый период случайные соседи
+type randomUnchoke struct{}
+
+func (randomUnchoke) onDemand() bool { return false }
+
+func (randomUnchoke) rechoke(s *sim, _ *peer, interested []int) []int {
+	s.rnd.Shuffle(len(interested), func(i, j int) {
+		interested[i], interested[j] = interested[j], interested[i]
+	})
+
+	if len(interested) > s.cfg.slots {
+		interested = interested[:s.cfg.slots]
+	}
+
+	return interested
+}
+
+// titForTat - обслуживаем тех, кто больше всего отдал нам за период,
+// плюс один оптимистично открытый сосед. Раздающие без загрузок
+// предпочитают самых быстрых получателей.
+type titForTat struct {
+	optimisticEvery int
+}
+
+func (titForTat) onDemand() bool { return false }
+
+func (t titForTat) rechoke(s *sim, u *peer, interested []int) []int {
+	score := func(n int) float64 {
+		if u.complete {
+			return s.peers[n].down
+		}
+		return u.received[n]
+	}
+
+	sort.SliceStable(interested, func(i, j int) bool {
+		return score(interested[i]) > score(interested[j])
+	})
+
+	regular := s.cfg.slots - 1
+	if regular < 0 {
+		regular = 0
+	}
+	if len(interested) <= regular {
+		return interested
+	}
+
+	res := append([]int{}, interested[:regular]...)
+	rest := interested[regular:]
+
+	// оптимистичное открытие меняется раз в несколько периодов
+	opt := rest[0]
+	if u.rechokes%t.optimisticEvery == 0 || !u.unchoked[opt] {
+		opt = rest[s.rnd.Intn(len(rest))]
+	}
+	for _, n := range rest {
+		if u.unchoked[n] && u.rechokes%t.optimisticEvery != 0 {
+			opt = n
+			break
+		}
+	}
+
+	return append(res, opt)
+}
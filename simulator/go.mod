@@ -0,0 +1,15 @@
+module github.com/elizarpif/grpctorrent/simulator
+
+go 1.14
+
+require (
+	github.com/elizarpif/grpctorrent/peer v0.0.0
+	github.com/elizarpif/grpctorrent/tracker v0.0.0
+	github.com/elizarpif/logger v0.0.2
+)
+
+replace (
+	github.com/elizarpif/grpctorrent/api => ../api
+	github.com/elizarpif/grpctorrent/peer => ../peer
+	github.com/elizarpif/grpctorrent/tracker => ../tracker
+)
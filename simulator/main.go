@@ -0,0 +1,160 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/elizarpif/logger"
+)
+
+// диапазон скоростей в КБ/с, из которого равномерно выбирается скорость пира
+type bandwidth struct {
+	min, max float64
+}
+
+func (b *bandwidth) String() string {
+	return fmt.Sprintf("%g:%g", b.min, b.max)
+}
+
+func (b *bandwidth) Set(v string) error {
+	parts := strings.SplitN(v, ":", 2)
+
+	min, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return err
+	}
+
+	max := min
+	if len(parts) == 2 {
+		max, err = strconv.ParseFloat(parts[1], 64)
+		if err != nil {
+			return err
+		}
+	}
+
+	if min <= 0 || max < min {
+		return fmt.Errorf("invalid bandwidth range %q", v)
+	}
+
+	b.min, b.max = min, max
+	return nil
+}
+
+// скорость в байтах в секунду
+func (b *bandwidth) draw(rnd *rand.Rand) float64 {
+	return (b.min + rnd.Float64()*(b.max-b.min)) * 1024
+}
+
+type config struct {
+	seed      int64
+	seeders   int
+	leechers  int
+	pieces    int
+	pieceSize int
+
+	upload     bandwidth
+	download   bandwidth
+	seedUpload bandwidth
+
+	arrival  float64 // пиров в секунду, 0 - все приходят сразу
+	churn    float64 // среднее время жизни качающего до ухода, 0 - не уходят
+	rejoin   float64 // среднее время до возвращения ушедшего, 0 - не возвращаются
+	seedTime float64 // сколько докачавший раздает перед уходом, отрицательное - не уходит
+
+	picker   string
+	policy   string
+	slots    int
+	requests int
+	maxConns int
+
+	announce    float64
+	rechoke     float64
+	sampleEvery float64
+	maxTime     float64
+
+	out string
+}
+
+func parseConfig() *config {
+	cfg := &config{
+		upload:     bandwidth{min: 64, max: 1024},
+		download:   bandwidth{min: 256, max: 4096},
+		seedUpload: bandwidth{min: 4096, max: 4096},
+	}
+
+	flag.Int64Var(&cfg.seed, "seed", 1, "random seed, the same seed gives the same run")
+	flag.IntVar(&cfg.seeders, "seeders", 1, "number of initial seeders")
+	flag.IntVar(&cfg.leechers, "leechers", 1000, "number of downloading peers")
+	flag.IntVar(&cfg.pieces, "pieces", 256, "number of pieces in the file")
+	flag.IntVar(&cfg.pieceSize, "piece-size", 256*1024, "piece length in bytes")
+
+	flag.Var(&cfg.upload, "upload", "leecher upload bandwidth range, KB/s (min:max)")
+	flag.Var(&cfg.download, "download", "leecher download bandwidth range, KB/s (min:max)")
+	flag.Var(&cfg.seedUpload, "seed-upload", "initial seeder upload bandwidth range, KB/s (min:max)")
+
+	flag.Float64Var(&cfg.arrival, "arrival", 0, "leecher arrival rate per second, 0 for a flash crowd")
+	flag.Float64Var(&cfg.churn, "churn", 0, "mean leecher session length in seconds before leaving, 0 disables churn")
+	flag.Float64Var(&cfg.rejoin, "rejoin", 0, "mean time in seconds before a leecher that left comes back, 0 never")
+	flag.Float64Var(&cfg.seedTime, "seed-time", 0, "seconds a finished leecher keeps seeding, negative forever")
+
+	flag.StringVar(&cfg.picker, "picker", "rarest", "piece selection strategy: sequential or rarest")
+	flag.StringVar(&cfg.policy, "policy", "tit-for-tat", "upload policy: all, random or tit-for-tat")
+	flag.IntVar(&cfg.slots, "slots", 4, "upload slots per peer")
+	flag.IntVar(&cfg.requests, "requests", 4, "pieces a peer downloads in parallel")
+	flag.IntVar(&cfg.maxConns, "max-conns", 50, "connections a peer opens from the tracker peer list")
+
+	flag.Float64Var(&cfg.announce, "announce", 30, "announce interval in seconds")
+	flag.Float64Var(&cfg.rechoke, "rechoke", 10, "rechoke interval in seconds")
+	flag.Float64Var(&cfg.sampleEvery, "sample", 10, "availability sampling interval in seconds")
+	flag.Float64Var(&cfg.maxTime, "max-time", 24*3600, "simulated time limit in seconds")
+
+	flag.StringVar(&cfg.out, "out", "", "directory for completion.csv and availability.csv, empty to skip")
+	flag.Parse()
+
+	return cfg
+}
+
+func (cfg *config) validate() error {
+	if cfg.seeders < 1 || cfg.leechers < 0 || cfg.pieces < 1 || cfg.pieceSize < 1 {
+		return fmt.Errorf("swarm needs at least one seeder and one piece")
+	}
+
+	if cfg.slots < 1 || cfg.requests < 1 || cfg.maxConns < 1 {
+		return fmt.Errorf("slots, requests and max-conns must be positive")
+	}
+
+	if cfg.announce <= 0 || cfg.rechoke <= 0 || cfg.sampleEvery <= 0 {
+		return fmt.Errorf("intervals must be positive")
+	}
+
+	return nil
+}
+
+func main() {
+	log := logger.NewLogger()
+	cfg := parseConfig()
+
+	if err := cfg.validate(); err != nil {
+		log.WithError(err).Fatal("invalid config")
+	}
+
+	s, err := newSim(cfg)
+	if err != nil {
+		log.WithError(err).Fatal("cannot create simulator")
+	}
+
+	s.run()
+
+	s.printSummary(os.Stdout)
+
+	if cfg.out != "" {
+		err = s.writeCSV(cfg.out)
+		if err != nil {
+			log.WithError(err).WithField("dir", cfg.out).Fatal("cannot write results")
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import "container/heap"
+
+type eventKind int
+
+const (
+	evJoin     eventKind = iota // пир приходит в рой
+	evLeave                     // пир уходит из роя
+	evAnnounce                  // пир обращается к трекеру за списком пиров
+	evRechoke                   // раздающий пир пересматривает, кого обслуживать
+	evPiece                     // кусок докачан
+	evSample                    // снимок доступности кусков
+)
+
+type event struct {
+	at    float64 // время события в секундах
+	seq   uint64  // порядок добавления, для детерминизма при равном времени
+	kind  eventKind
+	peer  int
+	epoch int // эпоха пира на момент планирования, события прошлых сессий игнорируются
+	tr    *transfer
+}
+
+// очередь событий, упорядоченная по времени
+type queue []*event
+
+func (q queue) Len() int { return len(q) }
+
+func (q queue) Less(i, j int) bool {
+	if q[i].at != q[j].at {
+		return q[i].at < q[j].at
+	}
+
+	return q[i].seq < q[j].seq
+}
+
+func (q queue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *queue) Push(x interface{}) { *q = append(*q, x.(*event)) }
+
+func (q *queue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+
+	return e
+}
+
+func (s *sim) schedule(e *event) {
+	s.seq++
+	e.seq = s.seq
+	heap.Push(&s.events, e)
+}
+
+func (s *sim) next() *event {
+	return heap.Pop(&s.events).(*event)
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// время скачивания докачавших пиров от первого прихода в рой
+func (s *sim) completionTimes() []float64 {
+	var res []float64
+	for _, p := range s.peers {
+		if !p.seeder && p.complete {
+			res = append(res, p.finished-p.firstJoin)
+		}
+	}
+
+	sort.Float64s(res)
+	return res
+}
+
+func percentile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return math.NaN()
+	}
+
+	i := int(math.Ceil(q*float64(len(sorted)))) - 1
+	if i < 0 {
+		i = 0
+	}
+
+	return sorted[i]
+}
+
+func (s *sim) printSummary(w io.Writer) {
+	times := s.completionTimes()
+
+	fmt.Fprintf(w, "picker=%s policy=%s seed=%d simulated=%.0fs\n", s.cfg.picker, s.cfg.policy, s.cfg.seed, s.now)
+	fmt.Fprintf(w, "completed %d of %d leechers\n", len(times), s.cfg.leechers)
+
+	if len(times) == 0 {
+		return
+	}
+
+	sum := 0.0
+	for _, t := range times {
+		sum += t
+	}
+
+	fmt.Fprintf(w, "completion time, s: mean=%.1f p10=%.1f p50=%.1f p90=%.1f p99=%.1f max=%.1f\n",
+		sum/float64(len(times)),
+		percentile(times, 0.1), percentile(times, 0.5), percentile(times, 0.9), percentile(times, 0.99),
+		times[len(times)-1])
+
+	// гистограмма распределения
+	const buckets = 10
+
+	width := (times[len(times)-1] - times[0]) / buckets
+	counts := make([]int, buckets)
+	for _, t := range times {
+		i := buckets - 1
+		if width > 0 {
+			i = int((t - times[0]) / width)
+		}
+		if i >= buckets {
+			i = buckets - 1
+		}
+		counts[i]++
+	}
+
+	for i, c := range counts {
+		bar := strings.Repeat("#", c*50/len(times))
+		fmt.Fprintf(w, "%8.1f-%-8.1f %6d %s\n", times[0]+width*float64(i), times[0]+width*float64(i+1), c, bar)
+	}
+
+	last := s.samples[len(s.samples)-1]
+	fmt.Fprintf(w, "final availability: min=%d mean=%.1f max=%d distributed copies=%.2f\n",
+		last.min, last.mean, last.max, last.copies)
+}
+
+func (s *sim) writeCSV(dir string) error {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return err
+	}
+
+	completion := [][]string{{"peer", "join", "finish", "duration"}}
+	for _, p := range s.peers {
+		if p.seeder || !p.complete {
+			continue
+		}
+		completion = append(completion, []string{
+			p.addr, ftoa(p.firstJoin), ftoa(p.finished), ftoa(p.finished - p.firstJoin),
+		})
+	}
+
+	err = writeCSVFile(filepath.Join(dir, "completion.csv"), completion)
+	if err != nil {
+		return err
+	}
+
+	// кривые доступности: сводка и число копий каждого куска в каждый момент
+	header := []string{"time", "seeders", "leechers", "min", "mean", "max", "missing", "distributed_copies"}
+	for i := 0; i < s.cfg.pieces; i++ {
+		header = append(header, "piece_"+strconv.Itoa(i))
+	}
+
+	availability := [][]string{header}
+	for _, smp := range s.samples {
+		row := []string{
+			ftoa(smp.at),
+			strconv.Itoa(smp.seeders),
+			strconv.Itoa(smp.leechers),
+			strconv.Itoa(smp.min),
+			ftoa(smp.mean),
+			strconv.Itoa(smp.max),
+			strconv.Itoa(smp.missing),
+			ftoa(smp.copies),
+		}
+		for _, c := range smp.perPiece {
+			row = append(row, strconv.Itoa(c))
+		}
+		availability = append(availability, row)
+	}
+
+	return writeCSVFile(filepath.Join(dir, "availability.csv"), availability)
+}
+
+func writeCSVFile(name string, rows [][]string) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	err = w.WriteAll(rows)
+	if err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+func ftoa(v float64) string {
+	return strconv.FormatFloat(v, 'f', 3, 64)
+}
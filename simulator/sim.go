@@ -0,0 +1,568 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/elizarpif/grpctorrent/peer/picker"
+	"github.com/elizarpif/grpctorrent/tracker/selection"
+)
+
+type peer struct {
+	idx    int
+	addr   string
+	seeder bool    // изначальный раздающий, никогда не уходит
+	up     float64 // скорость отдачи, байт/с
+	down   float64 // скорость загрузки, байт/с
+
+	active    bool
+	epoch     int     // номер сессии, растет при каждом уходе
+	announced float64 // время последнего обращения к трекеру
+
+	have   []bool
+	pieces []uint64 // номера имеющихся кусков, в том виде, как их видит трекер
+	state  *picker.State
+	picker picker.Picker
+
+	neighbors  []int        // соединения в порядке установления
+	isNeighbor map[int]bool // то же для быстрой проверки
+
+	unchoked  map[int]bool      // кого обслуживаем
+	uploads   map[int]*transfer // текущие отдачи по получателю
+	downloads map[int]*transfer // текущие загрузки по отдающему
+	received  map[int]float64   // байт получено от соседа за период пересмотра
+
+	rechokes int // счетчик пересмотров для оптимистичного открытия
+
+	firstJoin float64
+	finished  float64
+	complete  bool
+	gone      bool // ушел насовсем
+}
+
+// передача одного куска между двумя пирами
+type transfer struct {
+	from, to  int
+	piece     uint64
+	cancelled bool
+}
+
+// Has - пир как источник кусков для picker
+func (p *peer) Has(piece uint64) bool {
+	return p.have[piece]
+}
+
+type sim struct {
+	cfg *config
+	rnd *rand.Rand
+
+	now    float64
+	seq    uint64
+	events queue
+
+	peers  []*peer
+	swarm  []int // активные пиры в порядке анонса, как в hashPeers трекера
+	copies []int // сколько активных пиров имеют каждый кусок
+	policy chokePolicy
+
+	samples []sample
+}
+
+type sample struct {
+	at       float64
+	seeders  int
+	leechers int
+	min      int
+	max      int
+	mean     float64
+	missing  int     // куски, которых нет ни у одного активного пира
+	copies   float64 // distributed copies: полные копии плюс доля кусков сверх минимума
+	perPiece []int
+}
+
+func newSim(cfg *config) (*sim, error) {
+	policy, err := newChokePolicy(cfg.policy)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &sim{
+		cfg:    cfg,
+		rnd:    rand.New(rand.NewSource(cfg.seed)), //nolint:gosec // детерминированный генератор
+		copies: make([]int, cfg.pieces),
+		policy: policy,
+	}
+
+	arrival := 0.0 // время прихода очередного качающего, приходы - пуассоновский поток
+
+	total := cfg.seeders + cfg.leechers
+	for i := 0; i < total; i++ {
+		seeder := i < cfg.seeders
+
+		p := &peer{
+			idx:    i,
+			addr:   fmt.Sprintf("10.%d.%d.%d:9001", i>>16&0xff, i>>8&0xff, i&0xff),
+			seeder: seeder,
+			up:     cfg.upload.draw(s.rnd),
+			down:   cfg.download.draw(s.rnd),
+			have:   make([]bool, cfg.pieces),
+		}
+		if seeder {
+			p.up = cfg.seedUpload.draw(s.rnd)
+			for j := range p.have {
+				p.have[j] = true
+				p.pieces = append(p.pieces, uint64(j))
+			}
+			p.complete = true
+		}
+
+		pick, err := picker.New(cfg.picker, rand.New(rand.NewSource(s.rnd.Int63()))) //nolint:gosec // детерминированный генератор
+		if err != nil {
+			return nil, err
+		}
+		p.picker = pick
+
+		s.peers = append(s.peers, p)
+
+		at := 0.0
+		if !seeder && cfg.arrival > 0 {
+			arrival += s.rnd.ExpFloat64() / cfg.arrival
+			at = arrival
+		}
+		s.schedule(&event{at: at, kind: evJoin, peer: i})
+	}
+
+	s.schedule(&event{at: 0, kind: evSample})
+
+	return s, nil
+}
+
+func (s *sim) run() {
+	for len(s.events) > 0 {
+		e := s.next()
+		if e.at > s.cfg.maxTime {
+			s.now = s.cfg.maxTime
+			break
+		}
+		s.now = e.at
+
+		switch e.kind {
+		case evJoin:
+			s.join(e.peer)
+		case evLeave:
+			s.leave(e.peer, e.epoch)
+		case evAnnounce:
+			s.announce(e.peer, e.epoch)
+		case evRechoke:
+			s.rechoke(e.peer, e.epoch)
+		case evPiece:
+			s.pieceDone(e.tr)
+		case evSample:
+			s.sample()
+			if s.finished() {
+				return
+			}
+			s.schedule(&event{at: s.now + s.cfg.sampleEvery, kind: evSample})
+		}
+	}
+}
+
+// все качающие либо докачали, либо ушли насовсем
+func (s *sim) finished() bool {
+	for _, p := range s.peers {
+		if !p.seeder && !p.complete && !p.gone {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *sim) join(idx int) {
+	p := s.peers[idx]
+	if p.gone || p.active {
+		return
+	}
+
+	if p.epoch == 0 {
+		p.firstJoin = s.now
+	}
+
+	p.active = true
+	p.neighbors = nil
+	p.isNeighbor = make(map[int]bool)
+	p.unchoked = make(map[int]bool)
+	p.uploads = make(map[int]*transfer)
+	p.downloads = make(map[int]*transfer)
+	p.received = make(map[int]float64)
+
+	p.state = picker.NewState(uint64(s.cfg.pieces))
+	for _, piece := range p.pieces {
+		p.state.Done(piece)
+		s.copies[piece]++
+	}
+
+	s.swarm = append(s.swarm, idx)
+
+	s.schedule(&event{at: s.now, kind: evAnnounce, peer: idx, epoch: p.epoch})
+	if !s.policy.onDemand() {
+		s.schedule(&event{at: s.now + s.cfg.rechoke, kind: evRechoke, peer: idx, epoch: p.epoch})
+	}
+
+	// уход по причине текучести
+	if !p.seeder && s.cfg.churn > 0 && !p.complete {
+		s.schedule(&event{at: s.now + s.rnd.ExpFloat64()*s.cfg.churn, kind: evLeave, peer: idx, epoch: p.epoch})
+	}
+}
+
+func (s *sim) leave(idx, epoch int) {
+	p := s.peers[idx]
+	if !p.active || p.epoch != epoch {
+		return
+	}
+
+	p.active = false
+	p.epoch++
+
+	for _, piece := range p.pieces {
+		s.copies[piece]--
+	}
+
+	for i, v := range s.swarm {
+		if v == idx {
+			s.swarm = append(s.swarm[:i], s.swarm[i+1:]...)
+			break
+		}
+	}
+
+	affected := make([]int, 0, len(p.neighbors))
+
+	for _, n := range p.neighbors {
+		np := s.peers[n]
+		np.state.RemoveSource(p.pieces)
+		delete(np.isNeighbor, idx)
+		delete(np.unchoked, idx)
+		delete(np.received, idx)
+		for i, v := range np.neighbors {
+			if v == idx {
+				np.neighbors = append(np.neighbors[:i], np.neighbors[i+1:]...)
+				break
+			}
+		}
+
+		// загрузки соседа у уходящего пира прерываются
+		if tr, ok := np.downloads[idx]; ok {
+			tr.cancelled = true
+			np.state.Release(tr.piece)
+			delete(np.downloads, idx)
+		}
+
+		// отдачи соседа уходящему пиру тоже
+		if tr, ok := np.uploads[idx]; ok {
+			tr.cancelled = true
+			delete(np.uploads, idx)
+		}
+
+		affected = append(affected, n)
+	}
+
+	for _, tr := range p.downloads {
+		tr.cancelled = true
+	}
+	for _, tr := range p.uploads {
+		tr.cancelled = true
+	}
+
+	sort.Ints(affected)
+	for _, n := range affected {
+		s.request(n)
+	}
+
+	// докачавшие уходят насовсем, остальные могут вернуться
+	if p.complete || s.cfg.rejoin <= 0 {
+		p.gone = !p.seeder
+		return
+	}
+
+	s.schedule(&event{at: s.now + s.rnd.ExpFloat64()*s.cfg.rejoin, kind: evJoin, peer: idx})
+}
+
+// обращение к трекеру: тот же отбор пиров, что и в Tracker.GetPeers
+func (s *sim) announce(idx, epoch int) {
+	p := s.peers[idx]
+	if !p.active || p.epoch != epoch {
+		return
+	}
+
+	p.announced = s.now
+
+	candidates := make([]selection.Candidate, 0, len(s.swarm))
+	for _, i := range s.swarm {
+		c := s.peers[i]
+		if len(c.pieces) == 0 {
+			// трекер возвращает только пиров, сообщивших хотя бы об одном куске
+			continue
+		}
+		candidates = append(candidates, selection.Candidate{
+			ID:     fmt.Sprint(c.idx),
+			Addr:   c.addr,
+			Pieces: c.pieces,
+			Seeder: c.complete,
+			Age:    time.Duration((s.now - c.announced) * float64(time.Second)),
+		})
+	}
+
+	var wanted []uint64
+	for piece, ok := range p.have {
+		if !ok {
+			wanted = append(wanted, uint64(piece))
+		}
+	}
+
+	byAddr := make(map[string]int, len(candidates))
+	for _, i := range s.swarm {
+		byAddr[s.peers[i].addr] = i
+	}
+
+	selected := selection.Select(candidates, selection.Request{
+		Requester: fmt.Sprint(p.idx),
+		MaxPeers:  s.cfg.maxConns,
+		Wanted:    wanted,
+		Rand:      s.rnd,
+	})
+
+	for _, c := range selected {
+		if len(p.neighbors) >= s.cfg.maxConns {
+			break
+		}
+
+		s.connect(idx, byAddr[c.Addr])
+	}
+
+	s.request(idx)
+
+	if !p.complete {
+		s.schedule(&event{at: s.now + s.cfg.announce, kind: evAnnounce, peer: idx, epoch: epoch})
+	}
+}
+
+func (s *sim) connect(a, b int) {
+	pa, pb := s.peers[a], s.peers[b]
+	if a == b || pa.isNeighbor[b] || !pb.active {
+		return
+	}
+
+	pa.neighbors = append(pa.neighbors, b)
+	pa.isNeighbor[b] = true
+	pa.state.AddSource(pb.pieces)
+
+	pb.neighbors = append(pb.neighbors, a)
+	pb.isNeighbor[a] = true
+	pb.state.AddSource(pa.pieces)
+
+	s.request(b)
+}
+
+// интересен ли получателю раздающий пир
+func (s *sim) interested(to, from *peer) bool {
+	if to.complete {
+		return false
+	}
+	if from.complete {
+		return true
+	}
+
+	for _, piece := range from.pieces {
+		if !to.have[piece] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// скорость передачи одного куска: доля отдающего канала, но не больше доли принимающего
+func (s *sim) rate(from, to *peer) float64 {
+	up := from.up / float64(s.cfg.slots)
+	down := to.down / float64(s.cfg.requests)
+	if up < down {
+		return up
+	}
+
+	return down
+}
+
+// пир пытается запросить куски у открытых для него соседей
+func (s *sim) request(idx int) {
+	d := s.peers[idx]
+	if !d.active || d.complete {
+		return
+	}
+
+	for _, u := range d.neighbors {
+		if len(d.downloads) >= s.cfg.requests {
+			return
+		}
+
+		up := s.peers[u]
+		if !up.unchoked[idx] {
+			if !s.policy.onDemand() || len(up.unchoked) >= s.cfg.slots || !s.interested(d, up) {
+				continue
+			}
+			up.unchoked[idx] = true
+		}
+
+		if _, busy := d.downloads[u]; busy {
+			continue
+		}
+
+		piece, ok := d.picker.Pick(d.state, up)
+		if !ok {
+			if s.policy.onDemand() {
+				delete(up.unchoked, idx)
+			}
+			continue
+		}
+
+		d.state.Reserve(piece)
+		tr := &transfer{from: u, to: idx, piece: piece}
+		d.downloads[u] = tr
+		up.uploads[idx] = tr
+
+		s.schedule(&event{
+			at:   s.now + float64(s.cfg.pieceSize)/s.rate(up, d),
+			kind: evPiece,
+			tr:   tr,
+		})
+	}
+}
+
+func (s *sim) pieceDone(tr *transfer) {
+	if tr.cancelled {
+		return
+	}
+
+	d, u := s.peers[tr.to], s.peers[tr.from]
+	delete(d.downloads, tr.from)
+	delete(u.uploads, tr.to)
+
+	d.received[tr.from] += float64(s.cfg.pieceSize)
+
+	if !d.have[tr.piece] {
+		d.have[tr.piece] = true
+		d.pieces = append(d.pieces, tr.piece)
+		d.state.Done(tr.piece)
+		s.copies[tr.piece]++
+
+		for _, n := range d.neighbors {
+			s.peers[n].state.AddPiece(tr.piece)
+		}
+	}
+
+	if d.state.Left() == 0 && !d.complete {
+		d.complete = true
+		d.finished = s.now
+
+		for _, n := range d.neighbors {
+			delete(s.peers[n].unchoked, tr.to)
+		}
+
+		if s.cfg.seedTime >= 0 {
+			s.schedule(&event{at: s.now + s.cfg.seedTime, kind: evLeave, peer: tr.to, epoch: d.epoch})
+		}
+	}
+
+	s.request(tr.to)
+
+	// у получателя появился новый кусок, его могут захотеть те, кого он обслуживает
+	s.offer(d)
+
+	// освободилось место у отдающего
+	s.offer(u)
+}
+
+// раздающий пир предлагает куски соседям, которые могут у него качать
+func (s *sim) offer(u *peer) {
+	free := s.policy.onDemand() && len(u.unchoked) < s.cfg.slots
+
+	for _, n := range u.neighbors {
+		if !free && !u.unchoked[n] {
+			continue
+		}
+		if len(s.peers[n].downloads) >= s.cfg.requests {
+			continue
+		}
+
+		s.request(n)
+	}
+}
+
+func (s *sim) rechoke(idx, epoch int) {
+	u := s.peers[idx]
+	if !u.active || u.epoch != epoch {
+		return
+	}
+
+	interested := make([]int, 0, len(u.neighbors))
+	for _, n := range u.neighbors {
+		if s.interested(s.peers[n], u) {
+			interested = append(interested, n)
+		}
+	}
+
+	unchoke := s.policy.rechoke(s, u, interested)
+
+	u.unchoked = make(map[int]bool, len(unchoke))
+	for _, n := range unchoke {
+		u.unchoked[n] = true
+	}
+	u.received = make(map[int]float64)
+	u.rechokes++
+
+	for _, n := range unchoke {
+		s.request(n)
+	}
+
+	s.schedule(&event{at: s.now + s.cfg.rechoke, kind: evRechoke, peer: idx, epoch: epoch})
+}
+
+func (s *sim) sample() {
+	smp := sample{at: s.now, min: -1, perPiece: make([]int, len(s.copies))}
+	copy(smp.perPiece, s.copies)
+
+	for _, i := range s.swarm {
+		if s.peers[i].complete {
+			smp.seeders++
+		} else {
+			smp.leechers++
+		}
+	}
+
+	total := 0
+	for _, c := range s.copies {
+		total += c
+		if smp.min == -1 || c < smp.min {
+			smp.min = c
+		}
+		if c > smp.max {
+			smp.max = c
+		}
+		if c == 0 {
+			smp.missing++
+		}
+	}
+
+	above := 0
+	for _, c := range s.copies {
+		if c > smp.min {
+			above++
+		}
+	}
+
+	smp.mean = float64(total) / float64(len(s.copies))
+	smp.copies = float64(smp.min) + float64(above)/float64(len(s.copies))
+
+	s.samples = append(s.samples, smp)
+}
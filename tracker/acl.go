@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"sort"
+	"strings"
+
+	"github.com/elizarpif/grpctorrent/api"
+	"github.com/elizarpif/grpctorrent/api/auth"
+	"github.com/google/uuid"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const adminServicePrefix = "/api.TrackerAdmin/"
+
+// requester - кто спрашивает каталог: peer_id из подписи и его группы.
+// Анонимный запрос получает uuid.Nil и видит только публичные файлы.
+func (s *Server) requester(ctx context.Context) (uuid.UUID, []string) {
+	signed, ok := ctx.Value(identityKey{}).(*auth.Identity)
+	if !ok {
+		return uuid.Nil, nil
+	}
+
+	id, err := uuid.Parse(signed.PeerID)
+	if err != nil {
+		return uuid.Nil, nil
+	}
+
+	return id, s.groupsOf(id)
+}
+
+// groupsOf - группы, в которые администратор включил пира
+func (s *Server) groupsOf(id uuid.UUID) []string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.groups[id.String()]
+}
+
+// adminInterceptor пускает к админскому API только с токеном администратора
+// в заголовке authorization: Bearer <token>
+func (s *Server) adminInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (interface{}, error) {
+	if !strings.HasPrefix(info.FullMethod, adminServicePrefix) {
+		return handler(ctx, req)
+	}
+
+	if s.adminToken == "" {
+		return nil, status.Error(codes.PermissionDenied, "admin api is disabled")
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, v := range md.Get("authorization") {
+		token := strings.TrimPrefix(v, "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.adminToken)) == 1 {
+			return handler(ctx, req)
+		}
+	}
+
+	return nil, status.Error(codes.Unauthenticated, "invalid admin token")
+}
+
+func (s *Server) GetFileACL(ctx context.Context, req *api.DownloadFileRequest) (*api.FileACL, error) {
+	sw := s.getSwarm(req.Hash, false)
+	if sw == nil || sw.fileInfo() == nil {
+		return nil, status.Error(codes.NotFound, "cannot find file")
+	}
+
+	return sw.acl(req.Hash), nil
+}
+
+func (s *Server) SetFileACL(ctx context.Context, req *api.FileACL) (*api.FileACL, error) {
+	if _, ok := api.Visibility_name[int32(req.Visibility)]; !ok {
+		return nil, status.Error(codes.InvalidArgument, "unknown visibility")
+	}
+
+	var owner uuid.UUID
+	if req.Owner != "" {
+		var err error
+		owner, err = uuid.Parse(req.Owner)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid owner peer id")
+		}
+	}
+
+	sw := s.getSwarm(req.Hash, false)
+	if sw == nil {
+		return nil, status.Error(codes.NotFound, "cannot find file")
+	}
+
+	sw.mutex.Lock()
+	if sw.info == nil {
+		sw.mutex.Unlock()
+		return nil, status.Error(codes.NotFound, "cannot find file")
+	}
+
+	// пустой owner оставляет прежнего владельца
+	if owner == uuid.Nil {
+		owner = sw.owner
+	}
+
+	sw.setACL(owner, req.Visibility, req.AllowedPeers, req.AllowedGroups)
+	sw.mutex.Unlock()
+
+	return sw.acl(req.Hash), nil
+}
+
+func (s *Server) GetPeerGroups(ctx context.Context, req *api.GetPeerGroupsRequest) (*api.PeerGroups, error) {
+	id, err := uuid.Parse(req.PeerId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid peer id")
+	}
+
+	return &api.PeerGroups{PeerId: id.String(), Groups: s.groupsOf(id)}, nil
+}
+
+func (s *Server) SetPeerGroups(ctx context.Context, req *api.PeerGroups) (*api.PeerGroups, error) {
+	id, err := uuid.Parse(req.PeerId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid peer id")
+	}
+
+	set := make(map[string]bool, len(req.Groups))
+	for _, g := range req.Groups {
+		if g != "" {
+			set[g] = true
+		}
+	}
+
+	groups := sortedKeys(set)
+
+	s.mutex.Lock()
+	if len(groups) == 0 {
+		delete(s.groups, id.String())
+	} else {
+		s.groups[id.String()] = groups
+	}
+	s.mutex.Unlock()
+
+	return &api.PeerGroups{PeerId: id.String(), Groups: groups}, nil
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,317 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/elizarpif/grpctorrent/api"
+	"github.com/golang/protobuf/ptypes/empty"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// aclFixture - файл с владельцем, допущенным пиром и группой "team", пир из группы и посторонний
+type aclFixture struct {
+	s    *Server
+	hash string
+
+	owner, allowed, member, stranger *testPeer
+}
+
+func newACLFixture(t *testing.T, visibility api.Visibility) *aclFixture {
+	t.Helper()
+
+	f := &aclFixture{
+		s:        newTestServer(t),
+		hash:     fmt.Sprintf("%032x", 1),
+		owner:    newTestPeer(t, 0),
+		allowed:  newTestPeer(t, 1),
+		member:   newTestPeer(t, 2),
+		stranger: newTestPeer(t, 3),
+	}
+
+	_, err := f.s.SetPeerGroups(context.Background(), &api.PeerGroups{PeerId: f.member.id.String(), Groups: []string{"team"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := uploadRequest(f.owner, f.hash, 0)
+	req.Visibility = visibility
+	req.AllowedPeers = []string{f.allowed.id.String()}
+	req.AllowedGroups = []string{"team"}
+
+	if _, err := f.s.Upload(f.owner.ctx(), req); err != nil {
+		t.Fatal(err)
+	}
+
+	return f
+}
+
+// listed - есть ли файл в каталоге, который видит запрос ctx
+func (f *aclFixture) listed(t *testing.T, ctx context.Context) bool {
+	t.Helper()
+
+	resp, err := f.s.GetAvailableFiles(ctx, &empty.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range resp.Files {
+		if file.Hash == f.hash {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestFileAccess(t *testing.T) {
+	type want struct {
+		info, peers, listed bool
+	}
+
+	tests := []struct {
+		visibility api.Visibility
+		owner      want
+		allowed    want
+		member     want
+		stranger   want
+	}{
+		{
+			visibility: api.Visibility_PUBLIC,
+			owner:      want{info: true, peers: true, listed: true},
+			allowed:    want{info: true, peers: true, listed: true},
+			member:     want{info: true, peers: true, listed: true},
+			stranger:   want{info: true, peers: true, listed: true},
+		},
+		{
+			// по ссылке файл доступен всем, в каталоге его видит только владелец
+			visibility: api.Visibility_UNLISTED,
+			owner:      want{info: true, peers: true, listed: true},
+			allowed:    want{info: true, peers: true},
+			member:     want{info: true, peers: true},
+			stranger:   want{info: true, peers: true},
+		},
+		{
+			visibility: api.Visibility_PRIVATE,
+			owner:      want{info: true, peers: true, listed: true},
+			allowed:    want{info: true, peers: true, listed: true},
+			member:     want{info: true, peers: true, listed: true},
+			stranger:   want{},
+		},
+	}
+
+	for _, tt := range tests {
+		f := newACLFixture(t, tt.visibility)
+
+		for _, c := range []struct {
+			who  string
+			peer *testPeer
+			want want
+		}{
+			{who: "owner", peer: f.owner, want: tt.owner},
+			{who: "allowed peer", peer: f.allowed, want: tt.allowed},
+			{who: "group member", peer: f.member, want: tt.member},
+			{who: "stranger", peer: f.stranger, want: tt.stranger},
+		} {
+			t.Run(tt.visibility.String()+"/"+c.who, func(t *testing.T) {
+				ctx := c.peer.ctx()
+
+				info, err := f.s.GetFileInfo(ctx, &api.DownloadFileRequest{Hash: f.hash})
+				if (err == nil) != c.want.info {
+					t.Errorf("GetFileInfo = %v, %v, want allowed %v", info, err, c.want.info)
+				}
+
+				list, err := f.s.GetPeers(ctx, &api.GetPeersRequest{HashFile: f.hash, PeerId: c.peer.id.String()})
+				if (err == nil) != c.want.peers {
+					t.Errorf("GetPeers error = %v, want allowed %v", err, c.want.peers)
+				}
+				if err != nil && status.Code(err) != codes.PermissionDenied {
+					t.Errorf("GetPeers error code = %v, want PermissionDenied", status.Code(err))
+				}
+
+				// токен доступа выдается только к приватному файлу
+				if err == nil && (list.AccessToken != "") != (tt.visibility == api.Visibility_PRIVATE) {
+					t.Errorf("access token %q for a %v file", list.AccessToken, tt.visibility)
+				}
+
+				if got := f.listed(t, ctx); got != c.want.listed {
+					t.Errorf("listed = %v, want %v", got, c.want.listed)
+				}
+
+				// посторонний не может и отметить куски в приватном рое
+				_, err = f.s.PostPieceInfo(ctx, &api.PieceInfo{HashFile: f.hash, Serial: 0})
+				if (err == nil) != c.want.peers {
+					t.Errorf("PostPieceInfo error = %v, want allowed %v", err, c.want.peers)
+				}
+			})
+		}
+
+		// анонимный запрос каталога видит только публичные файлы
+		if got := f.listed(t, context.Background()); got != (tt.visibility == api.Visibility_PUBLIC) {
+			t.Errorf("%v: anonymous listed = %v", tt.visibility, got)
+		}
+		if _, err := f.s.GetFileInfo(context.Background(), &api.DownloadFileRequest{Hash: f.hash}); (err == nil) == (tt.visibility == api.Visibility_PRIVATE) {
+			t.Errorf("%v: anonymous GetFileInfo error = %v", tt.visibility, err)
+		}
+	}
+}
+
+func TestGroupMembershipChange(t *testing.T) {
+	f := newACLFixture(t, api.Visibility_PRIVATE)
+
+	// пир теряет доступ вместе с группой
+	_, err := f.s.SetPeerGroups(context.Background(), &api.PeerGroups{PeerId: f.member.id.String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := f.s.GetFileInfo(f.member.ctx(), &api.DownloadFileRequest{Hash: f.hash}); err == nil {
+		t.Error("peer removed from the group still sees the file")
+	}
+	if f.listed(t, f.member.ctx()) {
+		t.Error("peer removed from the group still lists the file")
+	}
+}
+
+func TestOwnerReupload(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(req *api.UploadFileRequest)
+		want   *api.FileACL // без Hash и Owner
+	}{
+		{
+			// права, заданные через SetFileACL, остаются
+			name: "without acl fields",
+			want: &api.FileACL{Visibility: api.Visibility_UNLISTED, AllowedGroups: []string{"admins"}},
+		},
+		{
+			name: "with acl fields",
+			change: func(req *api.UploadFileRequest) {
+				req.Visibility = api.Visibility_PRIVATE
+				req.AllowedPeers = []string{"b1b1b1b1-0000-0000-0000-000000000000"}
+			},
+			want: &api.FileACL{Visibility: api.Visibility_PRIVATE, AllowedPeers: []string{"b1b1b1b1-0000-0000-0000-000000000000"}},
+		},
+		{
+			name:   "private flag",
+			change: func(req *api.UploadFileRequest) { req.Private = true },
+			want:   &api.FileACL{Visibility: api.Visibility_PRIVATE},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newACLFixture(t, api.Visibility_PRIVATE)
+
+			_, err := f.s.SetFileACL(context.Background(), &api.FileACL{
+				Hash:          f.hash,
+				Visibility:    api.Visibility_UNLISTED,
+				AllowedGroups: []string{"admins"},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := uploadRequest(f.owner, f.hash, 0)
+			if tt.change != nil {
+				tt.change(req)
+			}
+
+			if _, err := f.s.Upload(f.owner.ctx(), req); err != nil {
+				t.Fatal(err)
+			}
+
+			got := f.s.getSwarm(f.hash, false).acl(f.hash)
+			if got.Owner != f.owner.id.String() || got.Visibility != tt.want.Visibility ||
+				fmt.Sprint(got.AllowedPeers) != fmt.Sprint(tt.want.AllowedPeers) ||
+				fmt.Sprint(got.AllowedGroups) != fmt.Sprint(tt.want.AllowedGroups) {
+				t.Errorf("acl = %+v, want %+v", got, tt.want)
+			}
+
+			// описание файла показывает ту же видимость
+			info := f.s.getSwarm(f.hash, false).fileInfo()
+			if info.Visibility != tt.want.Visibility || info.Private != (tt.want.Visibility == api.Visibility_PRIVATE) {
+				t.Errorf("file info visibility %v, private %v", info.Visibility, info.Private)
+			}
+		})
+	}
+}
+
+func TestAdminInterceptor(t *testing.T) {
+	const method = "/api.TrackerAdmin/SetFileACL"
+
+	tests := []struct {
+		name       string
+		adminToken string
+		method     string
+		header     []string // значения authorization
+		code       codes.Code
+	}{
+		{name: "valid token", adminToken: "secret", header: []string{"Bearer secret"}, code: codes.OK},
+		{name: "wrong token", adminToken: "secret", header: []string{"Bearer guess"}, code: codes.Unauthenticated},
+		{name: "token prefix", adminToken: "secret", header: []string{"Bearer secre"}, code: codes.Unauthenticated},
+		{name: "no token", adminToken: "secret", code: codes.Unauthenticated},
+		{name: "empty bearer", adminToken: "secret", header: []string{"Bearer "}, code: codes.Unauthenticated},
+		{name: "disabled", header: []string{"Bearer "}, code: codes.PermissionDenied},
+		{name: "tracker method needs no token", adminToken: "secret", method: "/api.Tracker/GetAvailableFiles", code: codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer(nil, tt.adminToken)
+
+			ctx := context.Background()
+			if tt.header != nil {
+				ctx = metadata.NewIncomingContext(ctx, metadata.MD{"authorization": tt.header})
+			}
+
+			m := tt.method
+			if m == "" {
+				m = method
+			}
+
+			called := false
+			_, err := s.adminInterceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: m},
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					called = true
+					return nil, nil
+				})
+
+			if status.Code(err) != tt.code {
+				t.Errorf("error = %v, want %v", err, tt.code)
+			}
+			if called != (tt.code == codes.OK) {
+				t.Errorf("handler called = %v", called)
+			}
+		})
+	}
+}
+
+func TestSetFileACLRejects(t *testing.T) {
+	f := newACLFixture(t, api.Visibility_PUBLIC)
+
+	tests := []struct {
+		name string
+		req  *api.FileACL
+		code codes.Code
+	}{
+		{name: "unknown file", req: &api.FileACL{Hash: fmt.Sprintf("%032x", 99)}, code: codes.NotFound},
+		{name: "unknown visibility", req: &api.FileACL{Hash: f.hash, Visibility: 42}, code: codes.InvalidArgument},
+		{name: "bad owner", req: &api.FileACL{Hash: f.hash, Owner: "me"}, code: codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		if _, err := f.s.SetFileACL(context.Background(), tt.req); status.Code(err) != tt.code {
+			t.Errorf("%s: SetFileACL = %v, want %v", tt.name, err, tt.code)
+		}
+	}
+
+	if _, err := f.s.SetPeerGroups(context.Background(), &api.PeerGroups{PeerId: "me"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("SetPeerGroups with a bad peer id: %v", err)
+	}
+}
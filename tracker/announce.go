@@ -0,0 +1,178 @@
+package main
+
+import (
+	"errors"
+	"time"
+
+	"github.com/elizarpif/grpctorrent/tracker/selection"
+	"github.com/google/uuid"
+)
+
+const (
+	// как часто BitTorrent-клиенту повторять объявление, в секундах
+	announceInterval = 30 * 60
+
+	// сколько пиров отдавать за раз, и BitTorrent-клиентам, и в GetPeers
+	defaultNumWant = 50
+	maxNumWant     = 200
+
+	// клиент, который не объявлялся столько, убирается из роя
+	clientTTL = 2 * announceInterval * time.Second
+
+	// больше BitTorrent-клиентов в одном рое трекер не держит
+	maxSwarmClients = 1000
+)
+
+var errUnknownHash = errors.New("unknown info_hash")
+
+// пространство имен для peer_id BitTorrent-клиентов: их сессии не пересекаются
+// с пирами, которые подписывают запросы своим ключом
+var btNamespace = uuid.MustParse("6f1c3c9e-6a43-4e53-9a7e-3b9e5f0e2d41")
+
+// announce - объявление BitTorrent-клиента по http или udp
+type announce struct {
+	hash    string // infohash в hex
+	peerID  []byte // peer_id клиента
+	addr    string // host:port, по которому клиента видит трекер
+	left    uint64 // сколько байт клиенту осталось скачать
+	event   string // started, completed, stopped или пусто
+	numWant int
+}
+
+// announceResult - пиры для клиента и состояние роя
+type announceResult struct {
+	peers     []selection.Candidate
+	seeders   int
+	leechers  int
+	completed int
+}
+
+// announce обновляет сессию клиента в рое и подбирает ему пиров.
+// Клиент не подписывает запросы, поэтому в приватные рои его не пускаем, а рои
+// заводят только пиры: клиент объявляется лишь в рое файла, уже загруженного на трекер.
+// Клиентам отдаются только клиенты, пирам в GetPeers - только пиры.
+func (s *Server) announce(a *announce) (*announceResult, error) {
+	id := uuid.NewSHA1(btNamespace, a.peerID)
+
+	sw := s.getSwarm(a.hash, false)
+	if sw == nil {
+		return nil, errUnknownHash
+	}
+
+	if a.event == "stopped" {
+		sw.mutex.Lock()
+		sw.removeClient(id, a.hash)
+		sw.mutex.Unlock()
+
+		return &announceResult{}, nil
+	}
+
+	now := time.Now()
+
+	err := func() error {
+		sw.mutex.Lock()
+		defer sw.mutex.Unlock()
+
+		if sw.info == nil {
+			return errUnknownHash
+		}
+
+		if !sw.authorized(id, nil) {
+			return errors.New("file is private")
+		}
+
+		sw.expireClients(a.hash, now)
+
+		joined := findPeer(sw.clients, id) != nil
+		if !joined && len(sw.clients) >= maxSwarmClients {
+			return errors.New("swarm is full")
+		}
+
+		p := s.clientSession(id, a.peerID, a.addr)
+
+		f, ok := p.file(a.hash)
+		if !ok {
+			f = &availableFile{hash: a.hash, pieces: make(map[uint]bool)}
+			p.setFile(f)
+		}
+
+		// про куски клиент сообщает только остаток: либо все, либо неизвестно какие
+		wasSeeder := f.seeder
+		f.seeder = a.left == 0
+		f.seen = now
+		if f.seeder {
+			for i := uint64(0); i < sw.info.Pieces; i++ {
+				f.pieces[uint(i)] = true
+			}
+		}
+
+		if a.event == "completed" && !wasSeeder {
+			sw.clientCompleted++
+		}
+
+		if !joined {
+			sw.clients = append(sw.clients, p)
+		}
+
+		return nil
+	}()
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err := sw.clientCandidates(a.hash)
+	if err != nil {
+		return nil, err
+	}
+
+	numWant := a.numWant
+	if numWant <= 0 {
+		numWant = defaultNumWant
+	}
+	if numWant > maxNumWant {
+		numWant = maxNumWant
+	}
+
+	res := &announceResult{peers: selection.Select(candidates, selection.Request{
+		Requester: id.String(),
+		MaxPeers:  numWant,
+	})}
+
+	res.seeders, res.leechers, res.completed = sw.clientStats(a.hash)
+
+	return res, nil
+}
+
+// scrape - статистика BitTorrent-клиентов роя для анонимного запроса; приватные рои не раскрываются
+func (s *Server) scrape(hash string) (seeders, leechers, completed int, ok bool) {
+	sw := s.getSwarm(hash, false)
+	if sw == nil {
+		return 0, 0, 0, false
+	}
+
+	if _, allowed := sw.access(uuid.Nil, nil); !allowed {
+		return 0, 0, 0, false
+	}
+
+	seeders, leechers, completed = sw.clientStats(hash)
+	return seeders, leechers, completed, true
+}
+
+// scrapeAll - хэши роев, которые можно показать анонимному запросу целиком
+func (s *Server) scrapeAll() []string {
+	s.mutex.RLock()
+	swarms := make(map[string]*swarm, len(s.swarms))
+	for hash, sw := range s.swarms {
+		swarms[hash] = sw
+	}
+	s.mutex.RUnlock()
+
+	var hashes []string
+	for hash, sw := range swarms {
+		if sw.listed(uuid.Nil, nil) {
+			hashes = append(hashes, hash)
+		}
+	}
+
+	return hashes
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/elizarpif/grpctorrent/api/auth"
+	"github.com/elizarpif/logger"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// методы, в которых пир действует от своего имени и обязан подписать запрос
+var signedMethods = map[string]bool{
+	"/api.Tracker/Upload":          true,
+	"/api.Tracker/GetPeers":        true,
+	"/api.Tracker/PostPieceInfo":   true,
+	"/api.Tracker/PostPieceReport": true,
+}
+
+// методы, доступные и анонимно: подписанный запрос видит еще и приватные файлы,
+// к которым пир допущен
+var optionallySignedMethods = map[string]bool{
+	"/api.Tracker/GetFileInfo":       true,
+	"/api.Tracker/GetAvailableFiles": true,
+	"/api.Tracker/Scrape":            true,
+}
+
+type identityKey struct{}
+
+// authenticator проверяет подписи запросов пиров. peer_id выведен из ключа,
+// поэтому привязку ключа к пиру хранить не нужно; каждый nonce принимается только один раз.
+// Запрос должен быть подписан для одного из адресов трекера: иначе чужой трекер,
+// которому пир отправил подписанный запрос, переслал бы его сюда.
+type authenticator struct {
+	addresses []string             // адреса, по которым пиры обращаются к трекеру
+	nonces    map[string]time.Time // использованные nonce и время запроса
+	lastSweep time.Time
+
+	mutex *sync.Mutex
+}
+
+func newAuthenticator(addresses []string) *authenticator {
+	return &authenticator{
+		addresses: addresses,
+		nonces:    make(map[string]time.Time),
+		mutex:     &sync.Mutex{},
+	}
+}
+
+func (a *authenticator) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (interface{}, error) {
+	if !signedMethods[info.FullMethod] && !(optionallySignedMethods[info.FullMethod] && isSigned(ctx)) {
+		return handler(ctx, req)
+	}
+
+	ident, err := a.verify(ctx, info.FullMethod, req)
+	if err != nil {
+		logger.GetLogger(ctx).WithError(err).WithField("method", info.FullMethod).Error("rejected unauthenticated request")
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	return handler(context.WithValue(ctx, identityKey{}, ident), req)
+}
+
+func isSigned(ctx context.Context) bool {
+	md, ok := metadata.FromIncomingContext(ctx)
+	return ok && len(md.Get(auth.SignatureKey)) > 0
+}
+
+func (a *authenticator) verify(ctx context.Context, method string, req interface{}) (*auth.Identity, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "missing metadata")
+	}
+
+	msg, ok := req.(proto.Message)
+	if !ok {
+		return nil, status.Error(codes.Internal, "unexpected request type")
+	}
+
+	now := time.Now()
+
+	ident, err := auth.Verify(md, a.addresses, method, msg, now)
+	if err != nil {
+		return nil, err
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.sweep(now)
+
+	if _, seen := a.nonces[ident.Nonce]; seen {
+		return nil, status.Error(codes.Unauthenticated, "replayed nonce")
+	}
+
+	a.nonces[ident.Nonce] = ident.Timestamp
+
+	return ident, nil
+}
+
+// sweep забывает nonce, которые уже не пройдут проверку времени
+func (a *authenticator) sweep(now time.Time) {
+	if now.Sub(a.lastSweep) < auth.MaxClockSkew {
+		return
+	}
+
+	for nonce, ts := range a.nonces {
+		if now.Sub(ts) > auth.MaxClockSkew {
+			delete(a.nonces, nonce)
+		}
+	}
+
+	a.lastSweep = now
+}
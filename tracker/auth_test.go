@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/elizarpif/grpctorrent/api"
+	"github.com/elizarpif/grpctorrent/api/auth"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const getPeersMethod = "/api.Tracker/GetPeers"
+
+// intercept пропускает запрос через перехватчик и возвращает личность, с которой вызван метод
+func intercept(a *authenticator, md metadata.MD, method string, req interface{}) (*auth.Identity, error) {
+	ctx := context.Background()
+	if md != nil {
+		ctx = metadata.NewIncomingContext(ctx, md)
+	}
+
+	var ident *auth.Identity
+	_, err := a.unaryInterceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: method},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			ident, _ = ctx.Value(identityKey{}).(*auth.Identity)
+			return nil, nil
+		})
+
+	return ident, err
+}
+
+func TestAuthenticator(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := newAuthenticator([]string{"localhost:9000", "tracker.lan:9000"})
+	req := &api.GetPeersRequest{HashFile: "abc"}
+
+	sign := func(target string) metadata.MD {
+		md, err := auth.Sign(key, "localhost:9002", target, getPeersMethod, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return md
+	}
+
+	// запрос к любому из адресов трекера принимается
+	for _, target := range []string{"localhost:9000", "tracker.lan:9000"} {
+		ident, err := intercept(a, sign(target), getPeersMethod, req)
+		if err != nil || ident == nil || ident.PeerID != auth.PeerID(key.Public().(ed25519.PublicKey)) {
+			t.Errorf("request to %s: %+v, %v", target, ident, err)
+		}
+	}
+
+	md := sign("localhost:9000")
+	if _, err := intercept(a, md, getPeersMethod, req); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := intercept(a, md, getPeersMethod, req); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("replayed nonce: %v, want Unauthenticated", err)
+	}
+
+	if _, err := intercept(a, nil, getPeersMethod, req); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("unsigned GetPeers: %v, want Unauthenticated", err)
+	}
+
+	// каталог доступен и без подписи, тогда личности нет
+	ident, err := intercept(a, nil, "/api.Tracker/GetAvailableFiles", req)
+	if err != nil || ident != nil {
+		t.Errorf("anonymous GetAvailableFiles: %+v, %v", ident, err)
+	}
+}
+
+// TestAuthenticatorRejectsRelayedRequest - трекер из метаинфо или ссылки получает от пира
+// подписанные GetFileInfo и GetPeers и пересылает их трекеру пира, пока они свежие.
+// Подпись верна, но запрос подписан для адреса чужого трекера и отвергается.
+func TestAuthenticatorRejectsRelayedRequest(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	home := newAuthenticator([]string{"localhost:9000"})
+	hostile := newAuthenticator([]string{"evil.example:9000"})
+
+	requests := []struct {
+		method string
+		req    proto.Message
+	}{
+		{method: "/api.Tracker/GetFileInfo", req: &api.DownloadFileRequest{Hash: "abc"}},
+		{method: getPeersMethod, req: &api.GetPeersRequest{HashFile: "abc"}},
+	}
+
+	for _, r := range requests {
+		// так пир подписывает запрос к трекеру, указанному в announce-list
+		md, err := auth.Sign(key, "localhost:9002", "evil.example:9000", r.method, r.req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := intercept(hostile, md, r.method, r.req); err != nil {
+			t.Errorf("%s: rejected by the tracker it was sent to: %v", r.method, err)
+		}
+
+		if ident, err := intercept(home, md, r.method, r.req); status.Code(err) != codes.Unauthenticated {
+			t.Errorf("%s relayed to the home tracker: %+v, %v, want Unauthenticated", r.method, ident, err)
+		}
+	}
+}
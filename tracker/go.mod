@@ -11,4 +11,7 @@ require (
 	github.com/sirupsen/logrus v1.7.0
 	golang.org/x/sync v0.0.0-20190423024810-112230192c58
 	google.golang.org/grpc v1.33.2
+	google.golang.org/protobuf v1.25.0
 )
+
+replace github.com/elizarpif/grpctorrent/api => ../api
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/hex"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/elizarpif/grpctorrent/api/bencode"
+	"github.com/elizarpif/grpctorrent/tracker/selection"
+	"github.com/elizarpif/logger"
+)
+
+// infohash и peer_id в протоколе BitTorrent - по 20 байт
+const btIDLen = 20
+
+// handleAnnounce - /announce http-трекера BitTorrent
+func (s *Server) handleAnnounce(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	infoHash, peerID := q.Get("info_hash"), q.Get("peer_id")
+	if len(infoHash) != btIDLen || len(peerID) != btIDLen {
+		writeFailure(w, "invalid info_hash or peer_id")
+		return
+	}
+
+	port, err := strconv.ParseUint(q.Get("port"), 10, 16)
+	if err != nil || port == 0 {
+		writeFailure(w, "invalid port")
+		return
+	}
+
+	left, err := strconv.ParseUint(q.Get("left"), 10, 64)
+	if err != nil {
+		writeFailure(w, "invalid left")
+		return
+	}
+
+	// адрес берем из соединения, параметру ip не верим
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		writeFailure(w, "invalid remote address")
+		return
+	}
+
+	numWant, _ := strconv.Atoi(q.Get("numwant"))
+
+	res, err := s.announce(&announce{
+		hash:    hex.EncodeToString([]byte(infoHash)),
+		peerID:  []byte(peerID),
+		addr:    net.JoinHostPort(host, strconv.FormatUint(port, 10)),
+		left:    left,
+		event:   q.Get("event"),
+		numWant: numWant,
+	})
+	if err != nil {
+		logger.GetLogger(r.Context()).WithError(err).Error("announce failed")
+		writeFailure(w, err.Error())
+		return
+	}
+
+	resp := map[string]interface{}{
+		"interval":   announceInterval,
+		"complete":   res.seeders,
+		"incomplete": res.leechers,
+	}
+
+	if q.Get("compact") == "0" {
+		resp["peers"] = s.peerDicts(res.peers)
+	} else {
+		resp["peers"], resp["peers6"] = compactPeers(res.peers)
+	}
+
+	writeBencode(w, resp)
+}
+
+// handleScrape - /scrape http-трекера BitTorrent, без info_hash - по всем публичным роям
+func (s *Server) handleScrape(w http.ResponseWriter, r *http.Request) {
+	var hashes []string
+	for _, h := range r.URL.Query()["info_hash"] {
+		if len(h) != btIDLen {
+			writeFailure(w, "invalid info_hash")
+			return
+		}
+
+		hashes = append(hashes, hex.EncodeToString([]byte(h)))
+	}
+
+	if len(hashes) == 0 {
+		hashes = s.scrapeAll()
+	}
+
+	files := map[string]interface{}{}
+	for _, hash := range hashes {
+		raw, err := hex.DecodeString(hash)
+		if err != nil || len(raw) != btIDLen {
+			// файлы, загруженные пирами, адресуются md5, а не infohash
+			continue
+		}
+
+		seeders, leechers, completed, ok := s.scrape(hash)
+		if !ok {
+			continue
+		}
+
+		files[string(raw)] = map[string]interface{}{
+			"complete":   seeders,
+			"incomplete": leechers,
+			"downloaded": completed,
+		}
+	}
+
+	writeBencode(w, map[string]interface{}{"files": files})
+}
+
+// compactPeers кодирует адреса пиров по 6 байт для IPv4 и по 18 для IPv6.
+// Адреса с именами хостов в компактный ответ не попадают.
+func compactPeers(peers []selection.Candidate) (v4, v6 []byte) {
+	v4, v6 = []byte{}, []byte{}
+
+	for _, c := range peers {
+		ip, port, ok := splitIPPort(c.Addr)
+		if !ok {
+			continue
+		}
+
+		if ip4 := ip.To4(); ip4 != nil {
+			v4 = append(append(v4, ip4...), byte(port>>8), byte(port))
+		} else {
+			v6 = append(append(v6, ip.To16()...), byte(port>>8), byte(port))
+		}
+	}
+
+	return v4, v6
+}
+
+// peerDicts - полный список пиров с peer_id, как его прислал клиент
+func (s *Server) peerDicts(peers []selection.Candidate) []interface{} {
+	list := []interface{}{}
+
+	for _, c := range peers {
+		host, port, err := net.SplitHostPort(c.Addr)
+		if err != nil {
+			continue
+		}
+
+		p, err := strconv.Atoi(port)
+		if err != nil {
+			continue
+		}
+
+		list = append(list, map[string]interface{}{"peer id": s.clientPeerID(c.ID), "ip": host, "port": p})
+	}
+
+	return list
+}
+
+func splitIPPort(addr string) (net.IP, uint16, bool) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, 0, false
+	}
+
+	ip := net.ParseIP(host)
+	p, err := strconv.ParseUint(port, 10, 16)
+	if ip == nil || err != nil {
+		return nil, 0, false
+	}
+
+	return ip, uint16(p), true
+}
+
+// по протоколу ошибки тоже отдаются с кодом 200, в поле failure reason
+func writeFailure(w http.ResponseWriter, reason string) {
+	writeBencode(w, map[string]interface{}{"failure reason": reason})
+}
+
+func writeBencode(w http.ResponseWriter, v interface{}) {
+	data, err := bencode.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	_, _ = w.Write(data)
+}
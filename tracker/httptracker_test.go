@@ -0,0 +1,272 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elizarpif/grpctorrent/api"
+	"github.com/elizarpif/grpctorrent/api/bencode"
+	"github.com/elizarpif/grpctorrent/tracker/selection"
+	"github.com/google/uuid"
+)
+
+// infohash файла из торрента: 20 байт, хэш файла на трекере - их hex
+var testInfoHash = strings.Repeat("\xab", btIDLen)
+
+// btClient - BitTorrent-клиент с peer_id и адресом, с которого он приходит на трекер
+type btClient struct {
+	peerID string
+	ip     string
+	port   int
+}
+
+func newBTClient(n int) *btClient {
+	return &btClient{
+		peerID: fmt.Sprintf("-XX0001-%012d", n),
+		ip:     "10.0.0." + strconv.Itoa(n),
+		port:   6880 + n,
+	}
+}
+
+// announce объявляет клиента по http; extra дополняет или заменяет параметры запроса
+func (c *btClient) announce(t *testing.T, s *Server, left int, extra url.Values) map[string]interface{} {
+	t.Helper()
+
+	q := url.Values{
+		"info_hash": {testInfoHash},
+		"peer_id":   {c.peerID},
+		"port":      {strconv.Itoa(c.port)},
+		"left":      {strconv.Itoa(left)},
+	}
+	for k, v := range extra {
+		q[k] = v
+	}
+
+	return get(t, s.handleAnnounce, "/announce?"+q.Encode(), c.ip)
+}
+
+// get выполняет запрос к http-трекеру и разбирает бенкод-ответ
+func get(t *testing.T, handler http.HandlerFunc, path, ip string) map[string]interface{} {
+	t.Helper()
+
+	r := httptest.NewRequest("GET", path, nil)
+	r.RemoteAddr = net.JoinHostPort(ip, "40000")
+	w := httptest.NewRecorder()
+
+	handler(w, r)
+
+	v, err := bencode.Unmarshal(w.Body.Bytes())
+	if err != nil {
+		t.Fatalf("%s: %v in %q", path, err, w.Body.String())
+	}
+
+	dict, ok := v.(map[string]interface{})
+	if !ok {
+		t.Fatalf("%s: response is not a dictionary: %q", path, w.Body.String())
+	}
+
+	return dict
+}
+
+// torrentServer - трекер с файлом, который пир загрузил из торрента с testInfoHash
+func torrentServer(t *testing.T, visibility api.Visibility) (*Server, *testPeer) {
+	t.Helper()
+
+	s := newTestServer(t)
+	owner := newTestPeer(t, 0)
+
+	req := uploadRequest(owner, hex.EncodeToString([]byte(testInfoHash)), 0)
+	req.Visibility = visibility
+	if _, err := s.Upload(owner.ctx(), req); err != nil {
+		t.Fatal(err)
+	}
+
+	return s, owner
+}
+
+func TestHTTPAnnounce(t *testing.T) {
+	s, owner := torrentServer(t, api.Visibility_PUBLIC)
+	seeder, leecher := newBTClient(1), newBTClient(2)
+
+	resp := seeder.announce(t, s, 0, url.Values{"event": {"started"}})
+	if resp["interval"] != int64(announceInterval) || resp["complete"] != int64(1) || resp["incomplete"] != int64(0) {
+		t.Errorf("first announce: %v", resp)
+	}
+	if resp["peers"] != "" {
+		t.Errorf("client got peers %q, want none: itself and signed peers are not listed", resp["peers"])
+	}
+
+	resp = leecher.announce(t, s, 100, nil)
+	if resp["peers"] != "\x0a\x00\x00\x01\x1a\xe1" || resp["peers6"] != "" {
+		t.Errorf("compact peers %q %q, want 10.0.0.1:6881", resp["peers"], resp["peers6"])
+	}
+	if resp["complete"] != int64(1) || resp["incomplete"] != int64(1) {
+		t.Errorf("counts: %v", resp)
+	}
+
+	// полный список - с peer_id, как его прислал клиент; пира с подписью в нем нет
+	resp = leecher.announce(t, s, 100, url.Values{"compact": {"0"}})
+	peers, _ := resp["peers"].([]interface{})
+	if len(peers) != 1 {
+		t.Fatalf("peers = %v, want the seeder only", resp["peers"])
+	}
+	if p := peers[0].(map[string]interface{}); p["peer id"] != seeder.peerID || p["ip"] != seeder.ip || p["port"] != int64(seeder.port) {
+		t.Errorf("peer = %v", p)
+	}
+
+	// пир с подписью получает в GetPeers только пиров, не клиентов
+	downloader := newTestPeer(t, 1)
+	list, err := s.GetPeers(downloader.ctx(), &api.GetPeersRequest{HashFile: hex.EncodeToString([]byte(testInfoHash)), PeerId: downloader.id.String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Peers) != 1 || list.Peers[0].PeerId != owner.id.String() {
+		t.Errorf("GetPeers = %v, want the owner only", list.Peers)
+	}
+
+	// и в Scrape пиров клиенты не считаются
+	stats, err := s.Scrape(context.Background(), &api.ScrapeRequest{Hashes: []string{hex.EncodeToString([]byte(testInfoHash))}})
+	if err != nil || len(stats.Files) != 1 || stats.Files[0].Seeders != 1 || stats.Files[0].Leechers != 0 {
+		t.Errorf("Scrape = %v, %v", stats, err)
+	}
+
+	leecher.announce(t, s, 0, url.Values{"event": {"completed"}})
+
+	scrape := get(t, s.handleScrape, "/scrape?"+url.Values{"info_hash": {testInfoHash}}.Encode(), "10.0.0.9")
+	files, _ := scrape["files"].(map[string]interface{})
+	file, _ := files[testInfoHash].(map[string]interface{})
+	if file["complete"] != int64(2) || file["incomplete"] != int64(0) || file["downloaded"] != int64(1) {
+		t.Errorf("scrape = %v", scrape)
+	}
+
+	// остановившийся клиент из роя пропадает
+	leecher.announce(t, s, 0, url.Values{"event": {"stopped"}})
+	resp = seeder.announce(t, s, 0, nil)
+	if resp["complete"] != int64(1) || resp["peers"] != "" {
+		t.Errorf("after stopped: %v", resp)
+	}
+}
+
+func TestHTTPAnnounceRejects(t *testing.T) {
+	s, _ := torrentServer(t, api.Visibility_PUBLIC)
+	private, _ := torrentServer(t, api.Visibility_PRIVATE)
+
+	client := newBTClient(1)
+
+	tests := []struct {
+		name  string
+		s     *Server
+		extra url.Values
+	}{
+		{name: "short info_hash", s: s, extra: url.Values{"info_hash": {"abc"}}},
+		{name: "short peer_id", s: s, extra: url.Values{"peer_id": {"-XX0001-"}}},
+		{name: "no port", s: s, extra: url.Values{"port": {""}}},
+		{name: "zero port", s: s, extra: url.Values{"port": {"0"}}},
+		{name: "port out of range", s: s, extra: url.Values{"port": {"65536"}}},
+		{name: "negative left", s: s, extra: url.Values{"left": {"-1"}}},
+		{name: "unknown file", s: s, extra: url.Values{"info_hash": {strings.Repeat("\xcd", btIDLen)}}},
+		{name: "private file", s: private},
+	}
+
+	for _, tt := range tests {
+		resp := client.announce(t, tt.s, 0, tt.extra)
+		if _, ok := resp["failure reason"]; !ok {
+			t.Errorf("%s: announce = %v, want failure", tt.name, resp)
+		}
+	}
+
+	// отвергнутые объявления не заводят ни роев, ни сессий
+	if len(s.swarms) != 1 || len(s.clients) != 0 || len(private.clients) != 0 {
+		t.Errorf("%d swarms, %d and %d client sessions after rejected announces", len(s.swarms), len(s.clients), len(private.clients))
+	}
+
+	if resp := get(t, s.handleScrape, "/scrape?info_hash=abc", "10.0.0.1"); resp["failure reason"] == nil {
+		t.Errorf("scrape with a short info_hash: %v", resp)
+	}
+
+	// приватный рой в scrape не виден
+	resp := get(t, private.handleScrape, "/scrape?"+url.Values{"info_hash": {testInfoHash}}.Encode(), "10.0.0.1")
+	if files, _ := resp["files"].(map[string]interface{}); len(files) != 0 {
+		t.Errorf("private swarm scraped: %v", resp)
+	}
+}
+
+func TestAnnounceExpiry(t *testing.T) {
+	s, _ := torrentServer(t, api.Visibility_PUBLIC)
+	hash := hex.EncodeToString([]byte(testInfoHash))
+	gone, alive := newBTClient(1), newBTClient(2)
+
+	gone.announce(t, s, 0, nil)
+
+	// клиент замолчал: и объявление файла, и сессия старше clientTTL
+	sw := s.getSwarm(hash, false)
+	p := sw.clients[0]
+	f, _ := p.file(hash)
+	f.seen = time.Now().Add(-clientTTL - time.Minute)
+	p.seen = f.seen
+	s.clientsSwept = time.Time{}
+
+	resp := alive.announce(t, s, 100, nil)
+	if resp["peers"] != "" || resp["complete"] != int64(0) {
+		t.Errorf("expired client still listed: %v", resp)
+	}
+
+	if len(sw.clients) != 1 || sw.clients[0].id == p.id {
+		t.Errorf("swarm keeps %d clients", len(sw.clients))
+	}
+	if _, ok := s.clients[p.id]; ok {
+		t.Error("session of the expired client is kept")
+	}
+	if _, ok := p.file(hash); ok {
+		t.Error("expired client keeps the file")
+	}
+}
+
+func TestAnnounceSwarmFull(t *testing.T) {
+	s, _ := torrentServer(t, api.Visibility_PUBLIC)
+	hash := hex.EncodeToString([]byte(testInfoHash))
+
+	first := newBTClient(1)
+	first.announce(t, s, 0, nil)
+
+	sw := s.getSwarm(hash, false)
+	for len(sw.clients) < maxSwarmClients {
+		p := NewPeer(uuid.New(), []string{"10.1.0.1:6881"})
+		p.setFile(&availableFile{hash: hash, pieces: map[uint]bool{}, seen: time.Now()})
+		sw.clients = append(sw.clients, p)
+	}
+
+	if resp := newBTClient(2).announce(t, s, 0, nil); resp["failure reason"] != "swarm is full" {
+		t.Errorf("new client in a full swarm: %v", resp)
+	}
+
+	// уже объявившийся клиент остается в рое
+	if resp := first.announce(t, s, 0, url.Values{"numwant": {"5"}}); resp["failure reason"] != nil {
+		t.Errorf("known client in a full swarm: %v", resp)
+	}
+}
+
+func TestCompactPeers(t *testing.T) {
+	v4, v6 := compactPeers([]selection.Candidate{
+		{Addr: "10.0.0.1:6881"},
+		{Addr: "[2001:db8::1]:6882"},
+		{Addr: "localhost:9002"}, // имя хоста в компактный ответ не попадает
+		{Addr: "10.0.0.2"},       // без порта
+	})
+
+	if string(v4) != "\x0a\x00\x00\x01\x1a\xe1" {
+		t.Errorf("v4 = %x", v4)
+	}
+	if want := "\x20\x01\x0d\xb8" + strings.Repeat("\x00", 11) + "\x01\x1a\xe2"; string(v6) != want {
+		t.Errorf("v6 = %x", v6)
+	}
+}
@@ -1,10 +1,14 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"net"
 	"net/http"
+	"strings"
 
 	"github.com/elizarpif/grpctorrent/api"
+	"github.com/elizarpif/grpctorrent/api/tlsconfig"
 	"github.com/elizarpif/logger"
 	"github.com/grpc-ecosystem/grpc-gateway/runtime"
 
@@ -20,38 +24,112 @@ const (
 func main() {
 	log := logger.NewLogger()
 
+	tlsConfig := &tlsconfig.Config{}
+	tlsConfig.RegisterFlags(flag.CommandLine)
+	tokenKeyPath := flag.String("token-key", "", "file with the key signing private swarm access tokens, created if missing; "+
+		"empty for a key that lives until restart")
+	udpAddr := flag.String("udp", "", "address for the BitTorrent udp tracker, empty disables it")
+	adminToken := flag.String("admin-token", "", "bearer token for the admin api editing file access; empty disables it")
+	addresses := flag.String("address", grpcAddress, "comma-separated addresses peers reach the tracker at; "+
+		"requests signed for any other address are rejected")
+	flag.Parse()
+
+	tokenKey, err := loadTokenKey(*tokenKeyPath)
+	if err != nil {
+		log.WithError(err).Fatal("cannot load token key")
+	}
+
 	lis, err := net.Listen("tcp", grpcAddress)
 	if err != nil {
 		log.WithError(err).WithField("address", grpcAddress).Fatal("listen for grpc")
 	}
 
-	grpcServer := grpc.NewServer()
+	server := NewServer(tokenKey, *adminToken)
+
+	serverOpts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(newAuthenticator(splitList(*addresses)).unaryInterceptor, server.adminInterceptor),
+	}
+
+	creds, err := tlsConfig.ServerOption()
+	if err != nil {
+		log.WithError(err).Fatal("invalid tls config")
+	}
+	if creds != nil {
+		serverOpts = append(serverOpts, creds)
+	}
+
+	// шлюз ходит в свой же grpc-сервер, при mtls - со своим сертификатом
+	dialOpt, err := tlsConfig.DialOption()
+	if err != nil {
+		log.WithError(err).Fatal("invalid tls config")
+	}
+
+	grpcServer := grpc.NewServer(serverOpts...)
 	defer grpcServer.GracefulStop()
 
-	server := NewServer()
 	api.RegisterTrackerServer(grpcServer, server)
+	api.RegisterTrackerAdminServer(grpcServer, server)
 
 	ctx := logger.SetContext(log)
 
 	mux := runtime.NewServeMux()
-	err = api.RegisterTrackerHandlerFromEndpoint(ctx, mux, grpcAddress, []grpc.DialOption{grpc.WithInsecure()})
+	err = api.RegisterTrackerHandlerFromEndpoint(ctx, mux, grpcAddress, []grpc.DialOption{dialOpt})
 	if err != nil {
 		log.WithError(err).Fatal("cannot register")
 	}
 
+	err = api.RegisterTrackerAdminHandlerFromEndpoint(ctx, mux, grpcAddress, []grpc.DialOption{dialOpt})
+	if err != nil {
+		log.WithError(err).Fatal("cannot register admin")
+	}
+
+	// рядом со шлюзом - http-трекер BitTorrent для обычных клиентов
+	handler := http.NewServeMux()
+	handler.HandleFunc("/announce", server.handleAnnounce)
+	handler.HandleFunc("/scrape", server.handleScrape)
+	handler.Handle("/", mux)
+
 	srv := &http.Server{
-		Addr:    httpAddr,
-		Handler: mux,
+		Addr:        httpAddr,
+		Handler:     handler,
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+
+	if tlsConfig.Enabled() {
+		srv.TLSConfig, err = tlsConfig.Server()
+		if err != nil {
+			log.WithError(err).Fatal("invalid tls config")
+		}
 	}
 
 	group := errgroup.Group{}
 	group.Go(func() error {
-		log.WithField("address", grpcAddress).Info("start grpc server")
+		log.WithField("address", grpcAddress).WithField("public_addresses", *addresses).Info("start grpc server")
 		return grpcServer.Serve(lis)
 	})
 
+	if *udpAddr != "" {
+		conn, err := net.ListenPacket("udp", *udpAddr)
+		if err != nil {
+			log.WithError(err).WithField("address", *udpAddr).Fatal("listen for udp")
+		}
+
+		udp, err := newUDPTracker(server, conn)
+		if err != nil {
+			log.WithError(err).Fatal("cannot create udp tracker")
+		}
+
+		group.Go(func() error {
+			log.WithField("address", *udpAddr).Info("start udp tracker")
+			return udp.serve(ctx)
+		})
+	}
+
 	group.Go(func() error {
-		log.WithField("address", httpAddr).Info("start http server")
+		log.WithField("address", httpAddr).WithField("tls", srv.TLSConfig != nil).Info("start http server")
+		if srv.TLSConfig != nil {
+			return srv.ListenAndServeTLS("", "")
+		}
 		return srv.ListenAndServe()
 	})
 
@@ -60,3 +138,16 @@ func main() {
 		log.WithError(err).Fatal("group wait")
 	}
 }
+
+// splitList разбирает значение флага со списком через запятую
+func splitList(value string) []string {
+	var list []string
+	for _, v := range strings.Split(value, ",") {
+		v = strings.TrimSpace(v)
+		if v != "" {
+			list = append(list, v)
+		}
+	}
+
+	return list
+}
@@ -0,0 +1,140 @@
+// Package selection отбирает пиров, которых трекер возвращает в ответ на GetPeers.
+// Тот же код используется симулятором роя.
+package selection
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// за столько времени без обращений к трекеру вес пира падает вдвое
+const recentWindow = 5 * time.Minute
+
+// Candidate - пир, раздающий куски файла
+type Candidate struct {
+	ID     string
+	Addr   string        // основной адрес
+	Addrs  []string      // все объявленные адреса
+	Pieces []uint64      // номера доступных кусочков
+	Seeder bool          // у пира весь файл
+	Age    time.Duration // сколько прошло с последнего обращения пира к трекеру
+}
+
+// Request - параметры запроса пира
+type Request struct {
+	Requester string     // peer_id запрашивающего пира, его не возвращаем
+	MaxPeers  int        // сколько пиров вернуть, 0 - всех
+	Wanted    []uint64   // нужные куски, пусто - любые
+	Rand      *rand.Rand // nil - общий генератор math/rand
+}
+
+type ranked struct {
+	Candidate
+	key float64
+}
+
+// Select возвращает пиров для запрашивающего в случайном порядке, в котором
+// чаще оказываются впереди пиры с редкими нужными кусками (обычно сиды)
+// и недавно обращавшиеся к трекеру. Так нагрузка расходится по рою,
+// а не падает целиком на первого сида.
+//
+// Если заданы нужные куски, у пиров остаются только они, а пиры без них
+// не возвращаются. Пиры, про куски которых ничего не известно, идут в конце.
+func Select(candidates []Candidate, req Request) []Candidate {
+	wanted := make(map[uint64]bool, len(req.Wanted))
+	for _, w := range req.Wanted {
+		wanted[w] = true
+	}
+
+	pool := make([]Candidate, 0, len(candidates))
+	copies := make(map[uint64]int) // у скольких пиров есть кусок
+
+	for _, c := range candidates {
+		if c.ID == req.Requester {
+			continue
+		}
+
+		if len(wanted) > 0 {
+			c.Pieces = filter(c.Pieces, wanted)
+			if len(c.Pieces) == 0 && !c.Seeder {
+				continue
+			}
+		}
+
+		for _, piece := range c.Pieces {
+			copies[piece]++
+		}
+
+		pool = append(pool, c)
+	}
+
+	float, shuffle := rand.Float64, rand.Shuffle
+	if req.Rand != nil {
+		float, shuffle = req.Rand.Float64, req.Rand.Shuffle
+	}
+
+	weighted := make([]ranked, 0, len(pool))
+	var idle []Candidate
+
+	for _, c := range pool {
+		w := weight(c, copies)
+		if w == 0 {
+			idle = append(idle, c)
+			continue
+		}
+
+		// взвешенная выборка без возвращения (Efraimidis-Spirakis):
+		// ключ u^(1/w), сравниваем его логарифм
+		weighted = append(weighted, ranked{Candidate: c, key: math.Log(float()) / w})
+	}
+
+	sort.SliceStable(weighted, func(i, j int) bool {
+		return weighted[i].key > weighted[j].key
+	})
+
+	shuffle(len(idle), func(i, j int) {
+		idle[i], idle[j] = idle[j], idle[i]
+	})
+
+	res := make([]Candidate, 0, len(pool))
+	for _, r := range weighted {
+		res = append(res, r.Candidate)
+	}
+	res = append(res, idle...)
+
+	if req.MaxPeers > 0 && len(res) > req.MaxPeers {
+		res = res[:req.MaxPeers]
+	}
+
+	return res
+}
+
+// weight - вес пира: сумма редкостей его кусков, уменьшенная со временем
+// с последнего обращения к трекеру. Сид без известных кусков
+// (BitTorrent-клиент в рое без описания файла) весит как один кусок.
+func weight(c Candidate, copies map[uint64]int) float64 {
+	var rarity float64
+	for _, piece := range c.Pieces {
+		rarity += 1 / float64(copies[piece])
+	}
+
+	if rarity == 0 && c.Seeder {
+		rarity = 1
+	}
+
+	return rarity / (1 + float64(c.Age)/float64(recentWindow))
+}
+
+// filter - куски пира из нужных, в новом срезе
+func filter(pieces []uint64, wanted map[uint64]bool) []uint64 {
+	var res []uint64
+	for _, piece := range pieces {
+		if wanted[piece] {
+			res = append(res, piece)
+		}
+	}
+
+	return res
+}
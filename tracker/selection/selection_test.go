@@ -0,0 +1,192 @@
+package selection
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func ids(cs []Candidate) []string {
+	res := make([]string, 0, len(cs))
+	for _, c := range cs {
+		res = append(res, c.ID)
+	}
+
+	return res
+}
+
+func TestSelectFilters(t *testing.T) {
+	candidates := []Candidate{
+		{ID: "me", Pieces: []uint64{0, 1}},
+		{ID: "a", Pieces: []uint64{0}},
+		{ID: "b", Pieces: []uint64{1, 2}},
+		{ID: "seed", Seeder: true},
+		{ID: "c"},
+	}
+
+	tests := []struct {
+		name string
+		req  Request
+		want []string // без учета порядка
+	}{
+		{
+			name: "requester is skipped",
+			req:  Request{Requester: "me"},
+			want: []string{"a", "b", "seed", "c"},
+		},
+		{
+			name: "peers without wanted pieces are dropped, seeders stay",
+			req:  Request{Requester: "me", Wanted: []uint64{2}},
+			want: []string{"b", "seed"},
+		},
+		{
+			name: "max peers",
+			req:  Request{Requester: "me", MaxPeers: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.req.Rand = rand.New(rand.NewSource(1))
+			got := Select(candidates, tt.req)
+
+			if tt.req.MaxPeers > 0 {
+				if len(got) != tt.req.MaxPeers {
+					t.Errorf("got %d peers, want %d", len(got), tt.req.MaxPeers)
+				}
+				return
+			}
+
+			set := make(map[string]bool)
+			for _, id := range ids(got) {
+				set[id] = true
+			}
+			if len(set) != len(tt.want) || len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", ids(got), tt.want)
+			}
+			for _, id := range tt.want {
+				if !set[id] {
+					t.Errorf("got %v, want %v", ids(got), tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSelectKeepsOnlyWantedPieces(t *testing.T) {
+	candidates := []Candidate{{ID: "a", Pieces: []uint64{0, 1, 2, 3}}}
+
+	got := Select(candidates, Request{Wanted: []uint64{1, 3}, Rand: rand.New(rand.NewSource(1))})
+	if len(got) != 1 || !reflect.DeepEqual(got[0].Pieces, []uint64{1, 3}) {
+		t.Fatalf("got %+v, want a with pieces [1 3]", got)
+	}
+
+	// срез кандидата не меняется
+	if !reflect.DeepEqual(candidates[0].Pieces, []uint64{0, 1, 2, 3}) {
+		t.Errorf("candidate pieces changed: %v", candidates[0].Pieces)
+	}
+}
+
+func TestSelectUnknownPiecesLast(t *testing.T) {
+	candidates := []Candidate{
+		{ID: "x"},
+		{ID: "a", Pieces: []uint64{0}},
+		{ID: "y"},
+		{ID: "seed", Seeder: true},
+	}
+
+	for seed := int64(0); seed < 20; seed++ {
+		got := ids(Select(candidates, Request{Rand: rand.New(rand.NewSource(seed))}))
+
+		// пиры без известных кусков (и не сиды) идут в конце
+		tail := map[string]bool{got[2]: true, got[3]: true}
+		if !tail["x"] || !tail["y"] {
+			t.Fatalf("seed %d: got %v, want x and y last", seed, got)
+		}
+	}
+}
+
+func TestSelectDeterministic(t *testing.T) {
+	var candidates []Candidate
+	for i := 0; i < 50; i++ {
+		candidates = append(candidates, Candidate{
+			ID:     string(rune('A' + i)),
+			Pieces: []uint64{uint64(i % 7)},
+			Age:    time.Duration(i) * time.Minute,
+		})
+	}
+
+	a := ids(Select(candidates, Request{Rand: rand.New(rand.NewSource(42))}))
+	b := ids(Select(candidates, Request{Rand: rand.New(rand.NewSource(42))}))
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("same seed gave different orders:\n%v\n%v", a, b)
+	}
+}
+
+func TestSelectPrefersRareAndRecent(t *testing.T) {
+	const runs = 2000
+
+	tests := []struct {
+		name       string
+		candidates []Candidate
+		favourite  string
+	}{
+		{
+			name: "rare pieces",
+			candidates: []Candidate{
+				{ID: "rare", Pieces: []uint64{0, 1, 2, 3}},
+				{ID: "common", Pieces: []uint64{0}},
+				{ID: "common2", Pieces: []uint64{0}},
+			},
+			favourite: "rare",
+		},
+		{
+			name: "recent peer",
+			candidates: []Candidate{
+				{ID: "recent", Pieces: []uint64{0}},
+				{ID: "idle", Pieces: []uint64{1}, Age: time.Hour},
+			},
+			favourite: "recent",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rnd := rand.New(rand.NewSource(7))
+
+			first := 0
+			for i := 0; i < runs; i++ {
+				if Select(tt.candidates, Request{Rand: rnd})[0].ID == tt.favourite {
+					first++
+				}
+			}
+
+			// выборка случайная, но любимец впереди заметно чаще остальных
+			if first < runs*2/3 {
+				t.Errorf("%s is first in %d of %d runs", tt.favourite, first, runs)
+			}
+		})
+	}
+}
+
+func TestWeight(t *testing.T) {
+	copies := map[uint64]int{0: 1, 1: 2}
+
+	tests := []struct {
+		name string
+		c    Candidate
+		want float64
+	}{
+		{name: "rarity sum", c: Candidate{Pieces: []uint64{0, 1}}, want: 1.5},
+		{name: "seeder without pieces", c: Candidate{Seeder: true}, want: 1},
+		{name: "unknown pieces", c: Candidate{}, want: 0},
+		{name: "halved after window", c: Candidate{Pieces: []uint64{0}, Age: recentWindow}, want: 0.5},
+	}
+
+	for _, tt := range tests {
+		if got := weight(tt.c, copies); got != tt.want {
+			t.Errorf("%s: weight = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -1,212 +1,378 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"crypto/ed25519"
 	"errors"
 	"sync"
+	"time"
 
 	"github.com/elizarpif/grpctorrent/api"
+	"github.com/elizarpif/grpctorrent/api/auth"
+	"github.com/elizarpif/grpctorrent/api/bitfield"
+	"github.com/elizarpif/grpctorrent/tracker/selection"
 	"github.com/elizarpif/logger"
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/google/uuid"
 
-	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
-type Peer struct {
-	addr  string
-	id    uuid.UUID
-	files map[string]*availableFile // мапа хэш - колиечство доступных кусков
-}
-
-type availableFile struct {
-	hash   string        // хэш файла
-	pieces map[uint]bool // доступные куски для скачивания
-}
+// больше кусков в одном файле трекер не принимает
+const maxPieces = 1 << 20
 
-func newAvailableFile(hash string, pieceNum uint) *availableFile {
-	pieces := make(map[uint]bool)
-	pieces[pieceNum] = true
+type Server struct {
+	swarms  map[string]*swarm   // хэш файла к рою
+	peers   map[uuid.UUID]*Peer // сессии пиров по peer_id
+	clients map[uuid.UUID]*Peer // сессии BitTorrent-клиентов из http и udp announce
 
-	return &availableFile{hash: hash, pieces: pieces}
-}
+	clientsSwept time.Time // когда последний раз забывали молчащих клиентов
 
-func NewPeer(addr string, id uuid.UUID) *Peer {
-	return &Peer{addr: addr, id: id, files: make(map[string]*availableFile)}
-}
+	groups map[string][]string // peer_id к группам, которые назначил администратор
 
-type Server struct {
-	hashPeers map[string][]*Peer       // хэш файла к пирам
-	hashFiles map[string]*api.FileInfo // хэш файла к файлу
-	peers     map[string]*Peer         // пиры по address
+	tokenKey   ed25519.PrivateKey // подпись токенов доступа к приватным файлам
+	adminToken string             // пустой токен отключает админское API
 
+	// mutex защищает только мапы swarms, peers, clients, groups и clientsSwept.
+	// Порядок захвата: мьютекс роя, затем mutex сервера, затем мьютекс пира.
 	mutex *sync.RWMutex
 }
 
-func NewServer() *Server {
+func NewServer(tokenKey ed25519.PrivateKey, adminToken string) *Server {
 	return &Server{
-		hashPeers: make(map[string][]*Peer),
-		hashFiles: make(map[string]*api.FileInfo),
-		peers:     make(map[string]*Peer),
+		tokenKey:   tokenKey,
+		adminToken: adminToken,
+
+		swarms:  make(map[string]*swarm),
+		peers:   make(map[uuid.UUID]*Peer),
+		clients: make(map[uuid.UUID]*Peer),
+		groups:  make(map[string][]string),
 
 		mutex: &sync.RWMutex{},
 	}
 }
 
-func (s *Server) GetFileInfo(ctx context.Context, file *api.DownloadFileRequest) (*api.FileInfo, error) {
-	is, ok := s.hashFiles[file.Hash]
-	if !ok {
-		return nil, errors.New("cannot find file")
-	}
-
-	return is, nil
-}
+// getSwarm возвращает рой файла, при create создает его, если роя еще нет
+func (s *Server) getSwarm(hash string, create bool) *swarm {
+	s.mutex.RLock()
+	sw, ok := s.swarms[hash]
+	s.mutex.RUnlock()
 
-func getPeerAddrFromMetadata(ctx context.Context) (string, error) {
-	md, ok := metadata.FromIncomingContext(ctx)
-	if !ok {
-		return "", errors.New("missing metadata")
+	if ok || !create {
+		return sw
 	}
 
-	addr, ok := md["address"]
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	sw, ok = s.swarms[hash]
 	if !ok {
-		return "", errors.New("invalid metadata")
+		sw = newSwarm()
+		s.swarms[hash] = sw
 	}
 
-	return addr[0], nil
+	return sw
 }
 
-func (s *Server) addPeer(ctx context.Context, clientID uuid.UUID) (*Peer, error) {
-	addr, err := getPeerAddrFromMetadata(ctx)
-	if err != nil {
-		logger.GetLogger(ctx).WithError(err).Error("cannot get peer from context")
-		return nil, errors.New("cannot get peer from context")
+func (s *Server) GetFileInfo(ctx context.Context, file *api.DownloadFileRequest) (*api.FileInfo, error) {
+	sw := s.getSwarm(file.Hash, false)
+	if sw == nil {
+		return nil, errors.New("cannot find file")
+	}
+
+	is := sw.fileInfo()
+	if is == nil {
+		return nil, errors.New("cannot find file")
 	}
 
-	isPeer := NewPeer(addr, clientID)
-	s.peers[addr] = isPeer // добавляем в мапу пиров
+	// о приватном файле посторонний не должен узнать даже то, что он есть
+	if _, allowed := sw.access(s.requester(ctx)); !allowed {
+		return nil, errors.New("cannot find file")
+	}
 
-	return isPeer, nil
+	return is, nil
 }
 
 func (s *Server) Upload(ctx context.Context, file *api.UploadFileRequest) (*empty.Empty, error) {
-	clientID := uuid.MustParse(file.ClientId)
-
-	// добавляем пира в список
-	isPeer, err := s.addPeer(ctx, clientID)
+	ident, err := getPeerFromContext(ctx, file.ClientId)
 	if err != nil {
+		logger.GetLogger(ctx).WithError(err).Error("cannot get peer from context")
 		return nil, err
 	}
 
-	// добавляем информацию о файле в мапу
-	s.hashFiles[file.Hash] = &api.FileInfo{
-		Name:        file.Name,
-		PieceLength: file.PieceLength,
-		Pieces:      file.Pieces,
-		Length:      file.Length,
-		Hash:        file.Hash,
+	// добавляем пира в список или обновляем его адреса
+	isPeer := s.session(ident)
+
+	err = checkPieces(file)
+	if err != nil {
+		logger.GetLogger(ctx).WithError(err).WithField("hash", file.Hash).Error("rejected file metadata")
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
 	newPieceInfo := &availableFile{
 		hash:   file.Hash,
 		pieces: make(map[uint]bool),
+		seeder: true,
 	}
 
 	for i := 0; i < int(file.Pieces); i++ {
 		newPieceInfo.pieces[uint(i)] = true
 	}
 
+	err = verifyPublisher(file)
+	if err != nil {
+		logger.GetLogger(ctx).WithError(err).WithField("hash", file.Hash).Error("rejected file metadata")
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	visibility := file.Visibility
+	if file.Private {
+		visibility = api.Visibility_PRIVATE
+	}
+
+	groups := s.groupsOf(isPeer.id)
+	sw := s.getSwarm(file.Hash, true)
+
+	sw.mutex.Lock()
+	defer sw.mutex.Unlock()
+
+	// права в запросе заданы явно; без них повторная загрузка права не трогает
+	changesACL := visibility != api.Visibility_PUBLIC || len(file.AllowedPeers) > 0 || len(file.AllowedGroups) > 0
+
+	// первый загрузивший становится владельцем, только он задает права доступа к файлу
+	if sw.info == nil {
+		sw.setACL(isPeer.id, visibility, file.AllowedPeers, file.AllowedGroups)
+		sw.describe(describeFile(file, sw.visibility))
+	}
+
+	// трекер не может сверить описание с md5 содержимого, поэтому другое описание
+	// того же хэша принимается только от владельца и только пока его никто не подтвердил.
+	// Права при этом не меняются: иначе любой пир забрал бы себе чужой файл.
+	if !sameContent(sw.info, file) {
+		if sw.owner != isPeer.id {
+			return nil, status.Error(codes.AlreadyExists, "file is already described with other pieces")
+		}
+
+		if sw.confirmed {
+			return nil, status.Error(codes.AlreadyExists, "file is already confirmed with other pieces")
+		}
+
+		logger.GetLogger(ctx).WithField("hash", file.Hash).Info("unconfirmed file description replaced by its owner")
+
+		sw.replace(file.Hash)
+		sw.describe(describeFile(file, sw.visibility))
+	}
+
+	if sw.owner != isPeer.id {
+		if !sw.authorized(isPeer.id, groups) || changesACL {
+			return nil, status.Error(codes.PermissionDenied, "only the owner can change file access")
+		}
+
+		// другой пир пришел с теми же кусками: у него то же содержимое, описание подтверждено.
+		// Пир, заведенный уже после загрузки, мог создать сам владелец, такой пир не подтверждает.
+		if isPeer.created.Before(sw.uploaded) {
+			sw.confirmed = true
+		}
+	} else {
+		// права, измененные через SetFileACL, сохраняются, пока владелец не задаст новые
+		if changesACL {
+			sw.setACL(isPeer.id, visibility, file.AllowedPeers, file.AllowedGroups)
+		}
+
+		// имя и подпись владелец может обновить, куски при этом те же
+		sw.info = describeFile(file, sw.visibility)
+	}
+
 	// добавляем информацию о загруженном файле к пиру
-	isPeer.files[file.Hash] = newPieceInfo
+	isPeer.setFile(newPieceInfo)
 
-	// добавляем пира к мапе хэш-пиры
-	peers := s.hashPeers[file.Hash]
-	peers = append(peers, isPeer)
-	s.hashPeers[file.Hash] = peers
+	// добавляем пира к раздающим файл, если его там еще нет
+	if findPeer(sw.peers, isPeer.id) == nil {
+		sw.peers = append(sw.peers, isPeer)
+	}
 
 	return &empty.Empty{}, nil
 }
 
 func (s *Server) GetPeers(ctx context.Context, request *api.GetPeersRequest) (*api.ListPeers, error) {
-	peerID := uuid.MustParse(request.PeerId)
-
-	addr, err := getPeerAddrFromMetadata(ctx)
+	ident, err := getPeerFromContext(ctx, request.PeerId)
 	if err != nil {
 		logger.GetLogger(ctx).WithError(err).Error("cannot get peer from context")
-		return nil, errors.New("cannot get peer from context")
+		return nil, err
 	}
 
-	s.peers[addr] = NewPeer(addr, peerID)
+	// сессия сохраняется вместе с уже известными файлами пира
+	s.session(ident)
 
 	resp := &api.ListPeers{}
 
-	peers := s.hashPeers[request.HashFile]
-	for _, p := range peers {
-		if p.addr == addr {
-			continue
-		}
+	sw := s.getSwarm(request.HashFile, false)
+	if sw == nil {
+		return resp, nil
+	}
 
-		respPeer := &api.ListPeers_Peer{
-			Address: p.addr,
-		}
+	private, allowed := sw.access(ident.id, s.groupsOf(ident.id))
+	if !allowed {
+		return nil, status.Error(codes.PermissionDenied, "file is private")
+	}
+
+	if private {
+		var expires time.Time
+		resp.AccessToken, expires = s.issueToken(request.HashFile, ident)
+		resp.TokenExpires = expires.Unix()
+	}
+
+	candidates, err := sw.candidates(request.HashFile)
+	if err != nil {
+		logger.GetLogger(ctx).WithError(err).Error("cannot collect peers")
+		return nil, err
+	}
+
+	maxPeers := int(request.MaxPeers)
+	if maxPeers == 0 {
+		maxPeers = defaultNumWant
+	}
+	if maxPeers > maxNumWant {
+		maxPeers = maxNumWant
+	}
+
+	selected := selection.Select(candidates, selection.Request{
+		Requester: ident.id.String(),
+		MaxPeers:  maxPeers,
+		Wanted:    request.WantedPieces,
+	})
+
+	var size uint64
+	if info := sw.fileInfo(); info != nil {
+		size = info.Pieces
+	}
 
-		is, ok := p.files[request.HashFile]
-		if !ok {
-			logger.GetLogger(ctx).Error("files in peer doesnt exist!")
-			return nil, errors.New("files in peer doesnt exist!")
+	for _, c := range selected {
+		peer := &api.ListPeers_Peer{
+			Address:   c.Addr,
+			PeerId:    c.ID,
+			Addresses: c.Addrs,
 		}
 
-		for k := range is.pieces {
-			respPeer.SerialPieces = append(respPeer.SerialPieces, uint64(k))
+		if request.Bitfield {
+			peer.Pieces = bitfield.FromPieces(size, c.Pieces).Encode()
+		} else {
+			peer.SerialPieces = c.Pieces
 		}
 
-		resp.Peers = append(resp.Peers, respPeer)
+		resp.Peers = append(resp.Peers, peer)
 	}
 
+	resp.Count = uint64(len(resp.Peers))
+
 	return resp, nil
 }
 
 func (s *Server) PostPieceInfo(ctx context.Context, info *api.PieceInfo) (*empty.Empty, error) {
-	addr, err := getPeerAddrFromMetadata(ctx)
+	ident, err := getPeerFromContext(ctx, "")
 	if err != nil {
 		return nil, err
 	}
 
-	// получаем список пиров по хешу файла
-	peers, ok := s.hashPeers[info.HashFile]
+	// получаем сессию пира, она появляется при Upload или GetPeers
+	currentPeer, ok := s.getPeer(ident.id)
 	if !ok {
-		return nil, errors.New("hash doesnt exists")
+		return nil, errors.New("peer doesnt exists")
 	}
 
-	// ищем текущего пира в списке пиров по хешу
-	peer := findPeer(peers, addr)
+	err = s.addPieces(currentPeer, s.groupsOf(ident.id), info)
+	if err != nil {
+		return nil, err
+	}
 
-	s.mutex.Lock()
+	return &empty.Empty{}, nil
+}
+
+// PostPieceReport принимает накопленные пиром куски сразу по нескольким файлам.
+// Файл, куски которого принять нельзя, не мешает остальным: его хэш возвращается в rejected.
+func (s *Server) PostPieceReport(ctx context.Context, report *api.PieceReport) (*api.PieceReportResult, error) {
+	ident, err := getPeerFromContext(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// отчет может прийти после перезапуска трекера, сессию заводим заново
+	currentPeer := s.session(ident)
+	groups := s.groupsOf(ident.id)
 
-	// если его нет...
-	if peer == nil {
-		// получаем текущего пира из мапы всех пиров
-		currentPeer, ok := s.peers[addr]
-		if !ok {
-			return nil, errors.New("peer doesnt exists")
+	resp := &api.PieceReportResult{}
+	for _, info := range report.Files {
+		err := s.addPieces(currentPeer, groups, info)
+		if err != nil {
+			logger.GetLogger(ctx).WithError(err).WithField("hash", info.HashFile).Error("rejected piece report")
+			resp.Rejected = append(resp.Rejected, info.HashFile)
 		}
+	}
 
-		currentPeer.files[info.HashFile] = newAvailableFile(info.HashFile, uint(info.Serial))
-		s.hashPeers[info.HashFile] = append(s.hashPeers[info.HashFile], currentPeer)
-	} else {
-		curPiecesMap := peer.files[info.HashFile].pieces
-		curPiecesMap[uint(info.Serial)] = true
+	return resp, nil
+}
+
+// addPieces отмечает куски файла у пира
+func (s *Server) addPieces(currentPeer *Peer, groups []string, info *api.PieceInfo) error {
+	// получаем рой по хешу файла
+	sw := s.getSwarm(info.HashFile, false)
+	if sw == nil {
+		return errors.New("hash doesnt exists")
 	}
 
-	s.mutex.Unlock()
-	// check is file piece added
+	sw.mutex.Lock()
+	defer sw.mutex.Unlock()
 
-	return &empty.Empty{}, nil
+	// в приватный рой куски могут добавлять только допущенные пиры
+	if !sw.authorized(currentPeer.id, groups) {
+		return status.Error(codes.PermissionDenied, "file is private")
+	}
+
+	if sw.info == nil {
+		return status.Error(codes.InvalidArgument, "no such piece")
+	}
+
+	// кусок один или сразу несколько битовым полем
+	serials := []uint64{info.Serial}
+	if info.Pieces != nil {
+		pieces, err := bitfield.Decode(info.Pieces, sw.info.Pieces)
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		serials = pieces.Pieces()
+	} else if info.Serial >= sw.info.Pieces {
+		return status.Error(codes.InvalidArgument, "no such piece")
+	}
+
+	// если пира еще нет среди раздающих...
+	if findPeer(sw.peers, currentPeer.id) == nil {
+		is := newAvailableFile(info.HashFile, serials)
+		currentPeer.setFile(is)
+		sw.peers = append(sw.peers, currentPeer)
+		sw.markSeeder(is)
+
+		return nil
+	}
+
+	is, ok := currentPeer.file(info.HashFile)
+	if !ok {
+		return errors.New("files in peer doesnt exist!")
+	}
+
+	for _, serial := range serials {
+		is.pieces[uint(serial)] = true
+	}
+	sw.markSeeder(is)
+
+	return nil
 }
 
-func findPeer(peers []*Peer, addr string) *Peer {
+func findPeer(peers []*Peer, id uuid.UUID) *Peer {
 	for _, p := range peers {
-		if p.addr == addr {
+		if p.id == id {
 			return p
 		}
 	}
@@ -215,18 +381,115 @@ func findPeer(peers []*Peer, addr string) *Peer {
 }
 
 func (s *Server) GetAvailableFiles(ctx context.Context, e *empty.Empty) (*api.ListFiles, error) {
+	s.mutex.RLock()
+	swarms := make([]*swarm, 0, len(s.swarms))
+	for _, sw := range s.swarms {
+		swarms = append(swarms, sw)
+	}
+	s.mutex.RUnlock()
+
+	id, groups := s.requester(ctx)
 	resp := &api.ListFiles{}
 
-	for _, v := range s.hashFiles {
+	for _, sw := range swarms {
+		v := sw.fileInfo()
+		if v == nil || !sw.listed(id, groups) {
+			continue
+		}
+
 		resp.Files = append(resp.Files, &api.FileInfo{
-			Name:        v.Name,
-			PieceLength: v.PieceLength,
-			Pieces:      v.Pieces,
-			Length:      v.Length,
-			Hash:        v.Hash,
+			Name:         v.Name,
+			PieceLength:  v.PieceLength,
+			Pieces:       v.Pieces,
+			Length:       v.Length,
+			Hash:         v.Hash,
+			Private:      v.Private,
+			Visibility:   v.Visibility,
+			PublisherKey: v.PublisherKey,
 		})
 	}
 
 	resp.Count = uint64(len(resp.Files))
 	return resp, nil
 }
+
+// describeFile - описание файла на трекере по запросу владельца
+func describeFile(file *api.UploadFileRequest, visibility api.Visibility) *api.FileInfo {
+	return &api.FileInfo{
+		Name:         file.Name,
+		PieceLength:  file.PieceLength,
+		Pieces:       file.Pieces,
+		Length:       file.Length,
+		Hash:         file.Hash,
+		Private:      visibility == api.Visibility_PRIVATE,
+		Visibility:   visibility,
+		PieceHashes:  file.PieceHashes,
+		PublisherKey: file.PublisherKey,
+		Signature:    file.Signature,
+		PieceHash:    file.PieceHash,
+	}
+}
+
+// sameContent - описывает ли загрузка то же содержимое, что и описание на трекере.
+// Имя и издатель не сравниваются: один и тот же файл могут опубликовать разные пиры.
+func sameContent(info *api.FileInfo, file *api.UploadFileRequest) bool {
+	if info.Length != file.Length || info.PieceLength != file.PieceLength ||
+		info.Pieces != file.Pieces || info.PieceHash != file.PieceHash ||
+		len(info.PieceHashes) != len(file.PieceHashes) {
+		return false
+	}
+
+	for i := range info.PieceHashes {
+		if !bytes.Equal(info.PieceHashes[i], file.PieceHashes[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// checkPieces сверяет число кусков с длиной файла. По числу кусков трекер
+// выделяет память в Scrape и GetPeers, поэтому оно еще и ограничено.
+func checkPieces(file *api.UploadFileRequest) error {
+	if file.PieceLength == 0 {
+		return errors.New("piece length must be positive")
+	}
+
+	pieces := file.Length / file.PieceLength
+	if file.Length%file.PieceLength != 0 {
+		pieces++
+	}
+
+	if file.Pieces != pieces {
+		return errors.New("pieces dont match file length")
+	}
+
+	if file.Pieces > maxPieces {
+		return errors.New("too many pieces")
+	}
+
+	return nil
+}
+
+// verifyPublisher проверяет подпись издателя, если файл подписан
+func verifyPublisher(file *api.UploadFileRequest) error {
+	if len(file.Signature) == 0 {
+		if len(file.PublisherKey) > 0 {
+			return errors.New("publisher key without signature")
+		}
+		return nil
+	}
+
+	if uint64(len(file.PieceHashes)) != file.Pieces {
+		return errors.New("piece hashes dont match pieces")
+	}
+
+	return auth.VerifyFile(file.PublisherKey, &auth.FileMeta{
+		Name:        file.Name,
+		Length:      file.Length,
+		PieceLength: file.PieceLength,
+		Hash:        file.Hash,
+		PieceHashes: file.PieceHashes,
+		PieceHash:   file.PieceHash.String(),
+	}, file.Signature)
+}
@@ -0,0 +1,480 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/elizarpif/grpctorrent/api"
+	"github.com/elizarpif/grpctorrent/api/auth"
+	"github.com/elizarpif/grpctorrent/api/bitfield"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/google/uuid"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	testPieces      = 8
+	testPieceLength = 4
+)
+
+// testPeer - пир с подписью, запросы от него идут с личностью, как после перехватчика
+type testPeer struct {
+	id   uuid.UUID
+	addr string
+	key  ed25519.PublicKey
+}
+
+func newTestPeer(t *testing.T, n int) *testPeer {
+	t.Helper()
+
+	key, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &testPeer{id: uuid.MustParse(auth.PeerID(key)), addr: "localhost:" + strconv.Itoa(9100+n), key: key}
+}
+
+func (p *testPeer) ctx() context.Context {
+	return context.WithValue(context.Background(), identityKey{}, &auth.Identity{
+		PeerID:    p.id.String(),
+		Address:   p.addr,
+		PublicKey: p.key,
+	})
+}
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewServer(key, "admin")
+}
+
+// register заводит сессию пира на трекере
+func register(t *testing.T, s *Server, p *testPeer) {
+	t.Helper()
+
+	if _, err := s.GetPeers(p.ctx(), &api.GetPeersRequest{HashFile: "unknown", PeerId: p.id.String()}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// uploadRequest - описание файла hash; variant меняет хэши кусков, то есть содержимое
+func uploadRequest(p *testPeer, hash string, variant int) *api.UploadFileRequest {
+	req := &api.UploadFileRequest{
+		ClientId:    p.id.String(),
+		Name:        hash + ".bin",
+		PieceLength: testPieceLength,
+		Pieces:      testPieces,
+		Length:      testPieces*testPieceLength - 1,
+		Hash:        hash,
+		PieceHash:   api.PieceHash_SHA256,
+	}
+
+	for i := 0; i < testPieces; i++ {
+		sum := sha256.Sum256([]byte(fmt.Sprint(hash, variant, i)))
+		req.PieceHashes = append(req.PieceHashes, sum[:])
+	}
+
+	return req
+}
+
+// TestServerConcurrentRequests гоняет все методы трекера одновременно по общим роям.
+// Смысл - в запуске с -race: блокировки роев, сервера и сессий не должны пропускать гонок
+// и захватываться в разном порядке.
+func TestServerConcurrentRequests(t *testing.T) {
+	const (
+		workers = 16
+		rounds  = 300
+		files   = 6
+	)
+
+	s := newTestServer(t)
+
+	peers := make([]*testPeer, 10)
+	for i := range peers {
+		peers[i] = newTestPeer(t, i)
+	}
+
+	hashes := make([]string, files)
+	for i := range hashes {
+		hashes[i] = fmt.Sprintf("%032x", i+1)
+	}
+
+	// владельцы файлов: каждый второй файл приватный, с допущенным пиром и группой
+	for i, hash := range hashes {
+		owner := peers[i%len(peers)]
+		req := uploadRequest(owner, hash, 0)
+		if i%2 == 1 {
+			req.Visibility = api.Visibility_PRIVATE
+			req.AllowedPeers = []string{peers[(i+1)%len(peers)].id.String()}
+			req.AllowedGroups = []string{"team"}
+		}
+
+		if _, err := s.Upload(owner.ctx(), req); err != nil {
+			t.Fatalf("upload %s: %v", hash, err)
+		}
+	}
+
+	ops := []func(rnd *rand.Rand, p *testPeer, hash string){
+		// повторная загрузка: владельцем, другим пиром с тем же или другим содержимым, новым файлом
+		func(rnd *rand.Rand, p *testPeer, hash string) {
+			_, _ = s.Upload(p.ctx(), uploadRequest(p, hash, rnd.Intn(2)))
+		},
+		func(rnd *rand.Rand, p *testPeer, hash string) {
+			_, _ = s.Upload(p.ctx(), uploadRequest(p, fmt.Sprintf("%032x", 100+rnd.Intn(20)), rnd.Intn(2)))
+		},
+		func(rnd *rand.Rand, p *testPeer, hash string) {
+			_, _ = s.GetPeers(p.ctx(), &api.GetPeersRequest{
+				HashFile:     hash,
+				PeerId:       p.id.String(),
+				Bitfield:     rnd.Intn(2) == 0,
+				WantedPieces: []uint64{uint64(rnd.Intn(testPieces))},
+			})
+		},
+		func(rnd *rand.Rand, p *testPeer, hash string) {
+			_, _ = s.PostPieceInfo(p.ctx(), &api.PieceInfo{HashFile: hash, Serial: uint64(rnd.Intn(testPieces))})
+		},
+		func(rnd *rand.Rand, p *testPeer, hash string) {
+			pieces := bitfield.FromPieces(testPieces, []uint64{uint64(rnd.Intn(testPieces)), uint64(rnd.Intn(testPieces))})
+			_, _ = s.PostPieceReport(p.ctx(), &api.PieceReport{Files: []*api.PieceInfo{
+				{HashFile: hash, Pieces: pieces.Encode()},
+				{HashFile: hashes[rnd.Intn(len(hashes))], Serial: uint64(rnd.Intn(testPieces))},
+			}})
+		},
+		func(rnd *rand.Rand, p *testPeer, hash string) {
+			_, _ = s.GetAvailableFiles(p.ctx(), &empty.Empty{})
+			_, _ = s.GetAvailableFiles(context.Background(), &empty.Empty{})
+		},
+		func(rnd *rand.Rand, p *testPeer, hash string) {
+			_, _ = s.GetFileInfo(p.ctx(), &api.DownloadFileRequest{Hash: hash})
+		},
+		func(rnd *rand.Rand, p *testPeer, hash string) {
+			_, _ = s.Scrape(p.ctx(), &api.ScrapeRequest{})
+			_, _ = s.Scrape(context.Background(), &api.ScrapeRequest{Hashes: []string{hash}})
+		},
+		func(rnd *rand.Rand, p *testPeer, hash string) {
+			visibility := api.Visibility(rnd.Intn(len(api.Visibility_name)))
+			_, _ = s.SetFileACL(context.Background(), &api.FileACL{
+				Hash:          hash,
+				Visibility:    visibility,
+				AllowedPeers:  []string{p.id.String()},
+				AllowedGroups: []string{"team"},
+			})
+			_, _ = s.GetFileACL(context.Background(), &api.DownloadFileRequest{Hash: hash})
+		},
+		func(rnd *rand.Rand, p *testPeer, hash string) {
+			var groups []string
+			if rnd.Intn(2) == 0 {
+				groups = []string{"team"}
+			}
+			_, _ = s.SetPeerGroups(context.Background(), &api.PeerGroups{PeerId: p.id.String(), Groups: groups})
+		},
+		// BitTorrent-клиенты по http и udp
+		func(rnd *rand.Rand, p *testPeer, hash string) {
+			events := []string{"", "started", "completed", "stopped"}
+			res, err := s.announce(&announce{
+				hash:   hash,
+				peerID: []byte(fmt.Sprintf("-XX0001-%012d", rnd.Intn(5))),
+				addr:   "127.0.0.1:" + strconv.Itoa(6881+rnd.Intn(5)),
+				left:   uint64(rnd.Intn(2)),
+				event:  events[rnd.Intn(len(events))],
+			})
+			if err == nil {
+				_ = s.peerDicts(res.peers)
+			}
+		},
+		func(rnd *rand.Rand, p *testPeer, hash string) {
+			_, _, _, _ = s.scrape(hash)
+			_ = s.scrapeAll()
+		},
+	}
+
+	wg := &sync.WaitGroup{}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+
+			rnd := rand.New(rand.NewSource(int64(w)))
+			for i := 0; i < rounds; i++ {
+				p := peers[rnd.Intn(len(peers))]
+				hash := hashes[rnd.Intn(len(hashes))]
+
+				ops[rnd.Intn(len(ops))](rnd, p, hash)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	checkSwarms(t, s)
+}
+
+// checkSwarms проверяет, что после гонки рои остались согласованными
+func checkSwarms(t *testing.T, s *Server) {
+	t.Helper()
+
+	for hash, sw := range s.swarms {
+		for _, peers := range [][]*Peer{sw.peers, sw.clients} {
+			seen := make(map[uuid.UUID]bool)
+			for _, p := range peers {
+				if seen[p.id] {
+					t.Errorf("swarm %s lists peer %s twice", hash, p.id)
+				}
+				seen[p.id] = true
+			}
+		}
+
+		if sw.info == nil {
+			continue
+		}
+
+		if sw.info.Hash != hash || sw.info.Pieces != testPieces || len(sw.info.PieceHashes) != testPieces {
+			t.Errorf("swarm %s has an inconsistent description %+v", hash, sw.info)
+		}
+
+		candidates, err := sw.candidates(hash)
+		if err != nil {
+			t.Errorf("swarm %s: %v", hash, err)
+		}
+
+		for _, c := range candidates {
+			for _, piece := range c.Pieces {
+				if piece >= testPieces {
+					t.Errorf("swarm %s: peer %s has piece %d past the end", hash, c.ID, piece)
+				}
+			}
+		}
+	}
+}
+
+// TestServerConcurrentSameFile - много пиров одновременно загружают и качают один файл:
+// в рое каждый пир один раз, запрашивающий не получает себя, а трекер не теряет пиров
+func TestServerConcurrentSameFile(t *testing.T) {
+	const n = 32
+
+	s := newTestServer(t)
+	hash := fmt.Sprintf("%032x", 42)
+
+	// пиры знакомы трекеру до загрузки файла, поэтому их загрузки подтверждают описание
+	peers := make([]*testPeer, n)
+	for i := range peers {
+		peers[i] = newTestPeer(t, i+1)
+		register(t, s, peers[i])
+	}
+
+	owner := newTestPeer(t, 0)
+	if _, err := s.Upload(owner.ctx(), uploadRequest(owner, hash, 0)); err != nil {
+		t.Fatal(err)
+	}
+
+	wg := &sync.WaitGroup{}
+	errs := make(chan error, 3*n)
+
+	for i, p := range peers {
+		wg.Add(1)
+		go func(i int, p *testPeer) {
+			defer wg.Done()
+
+			// половина загружает файл целиком, половина сообщает скачанные куски
+			if i%2 == 0 {
+				if _, err := s.Upload(p.ctx(), uploadRequest(p, hash, 0)); err != nil {
+					errs <- err
+				}
+			} else {
+				_, err := s.PostPieceReport(p.ctx(), &api.PieceReport{Files: []*api.PieceInfo{{HashFile: hash, Serial: uint64(i % testPieces)}}})
+				if err != nil {
+					errs <- err
+				}
+			}
+
+			list, err := s.GetPeers(p.ctx(), &api.GetPeersRequest{HashFile: hash, PeerId: p.id.String(), MaxPeers: maxNumWant})
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			for _, peer := range list.Peers {
+				if peer.PeerId == p.id.String() {
+					errs <- fmt.Errorf("peer %s got itself", p.id)
+				}
+			}
+		}(i, p)
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+
+	checkSwarms(t, s)
+
+	sw := s.getSwarm(hash, false)
+	if len(sw.peers) != n+1 {
+		t.Errorf("swarm has %d peers, want %d", len(sw.peers), n+1)
+	}
+	if !sw.confirmed {
+		t.Error("description uploaded by other peers is not confirmed")
+	}
+
+	resp, err := s.Scrape(context.Background(), &api.ScrapeRequest{Hashes: []string{hash}})
+	if err != nil || len(resp.Files) != 1 {
+		t.Fatalf("Scrape = %v, %v", resp, err)
+	}
+	if resp.Files[0].Seeders != n/2+1 || resp.Files[0].Leechers != n/2 {
+		t.Errorf("seeders %d, leechers %d, want %d and %d", resp.Files[0].Seeders, resp.Files[0].Leechers, n/2+1, n/2)
+	}
+}
+
+// withACL делает загружаемый файл приватным и допускает к нему пира p
+func withACL(req *api.UploadFileRequest, p *testPeer) *api.UploadFileRequest {
+	req.Visibility = api.Visibility_PRIVATE
+	req.AllowedPeers = []string{p.id.String()}
+
+	return req
+}
+
+// TestUploadConflictingDescription - другое описание того же хэша не дает чужому пиру
+// ни заменить описание, ни стать владельцем, ни выгнать раздающих
+func TestUploadConflictingDescription(t *testing.T) {
+	s := newTestServer(t)
+	hash := fmt.Sprintf("%032x", 7)
+
+	owner, allowed, stranger := newTestPeer(t, 0), newTestPeer(t, 1), newTestPeer(t, 2)
+
+	if _, err := s.Upload(owner.ctx(), withACL(uploadRequest(owner, hash, 0), allowed)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		peer *testPeer
+		req  *api.UploadFileRequest
+		code codes.Code
+	}{
+		{name: "stranger", peer: stranger, req: uploadRequest(stranger, hash, 1), code: codes.AlreadyExists},
+		{name: "allowed peer", peer: allowed, req: uploadRequest(allowed, hash, 1), code: codes.AlreadyExists},
+		{name: "stranger granting itself access", peer: stranger, req: withACL(uploadRequest(stranger, hash, 1), stranger), code: codes.AlreadyExists},
+		{name: "stranger granting itself, same pieces", peer: stranger, req: withACL(uploadRequest(stranger, hash, 0), stranger), code: codes.PermissionDenied},
+		{name: "stranger with the same pieces", peer: stranger, req: uploadRequest(stranger, hash, 0), code: codes.PermissionDenied},
+	}
+
+	for _, tt := range tests {
+		_, err := s.Upload(tt.peer.ctx(), tt.req)
+		if status.Code(err) != tt.code {
+			t.Errorf("%s: Upload = %v, want %v", tt.name, err, tt.code)
+		}
+	}
+
+	sw := s.getSwarm(hash, false)
+	acl := sw.acl(hash)
+	if acl.Owner != owner.id.String() || acl.Visibility != api.Visibility_PRIVATE ||
+		len(acl.AllowedPeers) != 1 || acl.AllowedPeers[0] != allowed.id.String() {
+		t.Errorf("acl changed: %+v", acl)
+	}
+
+	if !sameContent(sw.fileInfo(), uploadRequest(owner, hash, 0)) {
+		t.Error("description replaced by another peer")
+	}
+
+	if len(sw.peers) != 1 || sw.peers[0].id != owner.id {
+		t.Errorf("seeders changed: %d peers", len(sw.peers))
+	}
+}
+
+// TestUploadReplaceByOwner - владелец меняет неподтвержденное описание, права остаются прежними;
+// подтвержденное описание не меняет и он
+func TestUploadReplaceByOwner(t *testing.T) {
+	s := newTestServer(t)
+	hash := fmt.Sprintf("%032x", 8)
+
+	owner, allowed := newTestPeer(t, 0), newTestPeer(t, 1)
+	register(t, s, allowed)
+
+	if _, err := s.Upload(owner.ctx(), withACL(uploadRequest(owner, hash, 0), allowed)); err != nil {
+		t.Fatal(err)
+	}
+
+	// новые куски без полей доступа: файл остается приватным
+	if _, err := s.Upload(owner.ctx(), uploadRequest(owner, hash, 1)); err != nil {
+		t.Fatalf("owner replace: %v", err)
+	}
+
+	sw := s.getSwarm(hash, false)
+	if !sameContent(sw.fileInfo(), uploadRequest(owner, hash, 1)) {
+		t.Error("owner's description not replaced")
+	}
+	if acl := sw.acl(hash); acl.Owner != owner.id.String() || acl.Visibility != api.Visibility_PRIVATE || len(acl.AllowedPeers) != 1 {
+		t.Errorf("acl changed by replace: %+v", acl)
+	}
+
+	// допущенный пир подтверждает новое описание
+	if _, err := s.Upload(allowed.ctx(), uploadRequest(allowed, hash, 1)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Upload(owner.ctx(), uploadRequest(owner, hash, 0)); status.Code(err) != codes.AlreadyExists {
+		t.Errorf("owner replaced a confirmed description: %v", err)
+	}
+}
+
+// TestUploadConfirmation - подтверждает описание только пир, которого трекер знал до загрузки
+func TestUploadConfirmation(t *testing.T) {
+	tests := []struct {
+		name      string
+		early     bool // сессия пира заведена до загрузки файла
+		owner     bool // загружает сам владелец
+		confirmed bool
+	}{
+		{name: "peer known before the upload", early: true, confirmed: true},
+		{name: "peer created after the upload"},
+		{name: "owner again", owner: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t)
+			hash := fmt.Sprintf("%032x", 9)
+
+			owner, other := newTestPeer(t, 0), newTestPeer(t, 1)
+			if tt.owner {
+				other = owner
+			}
+			if tt.early {
+				register(t, s, other)
+			}
+
+			if _, err := s.Upload(owner.ctx(), uploadRequest(owner, hash, 0)); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := s.Upload(other.ctx(), uploadRequest(other, hash, 0)); err != nil {
+				t.Fatal(err)
+			}
+
+			sw := s.getSwarm(hash, false)
+			if sw.confirmed != tt.confirmed {
+				t.Errorf("confirmed = %v, want %v", sw.confirmed, tt.confirmed)
+			}
+
+			// неподтвержденное описание владелец еще может заменить
+			_, err := s.Upload(owner.ctx(), uploadRequest(owner, hash, 1))
+			if (err == nil) == tt.confirmed {
+				t.Errorf("owner replace after upload: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/elizarpif/grpctorrent/api/auth"
+	"github.com/google/uuid"
+)
+
+// Peer - сессия пира на трекере. Пир определяется своим peer_id,
+// адреса, которые он объявляет, могут меняться между запросами.
+type Peer struct {
+	id    uuid.UUID
+	addrs []string                  // объявленные адреса, первый - основной
+	files map[string]*availableFile // мапа хэш - колиечство доступных кусков
+	seen  time.Time                 // последнее обращение к трекеру
+
+	created time.Time // когда трекер завел сессию, не меняется
+
+	clientID []byte // peer_id BitTorrent-клиента, у пиров с подписью пусто
+
+	mutex *sync.RWMutex // защищает addrs, seen и мапу files, сами availableFile защищены мьютексом роя
+}
+
+type availableFile struct {
+	hash   string        // хэш файла
+	pieces map[uint]bool // доступные куски для скачивания
+	seeder bool          // у пира весь файл
+	seen   time.Time     // последнее объявление файла BitTorrent-клиентом
+}
+
+func newAvailableFile(hash string, serials []uint64) *availableFile {
+	pieces := make(map[uint]bool, len(serials))
+	for _, serial := range serials {
+		pieces[uint(serial)] = true
+	}
+
+	return &availableFile{hash: hash, pieces: pieces}
+}
+
+func NewPeer(id uuid.UUID, addrs []string) *Peer {
+	return &Peer{id: id, addrs: addrs, files: make(map[string]*availableFile), created: time.Now(), mutex: &sync.RWMutex{}}
+}
+
+func (p *Peer) file(hash string) (*availableFile, bool) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	f, ok := p.files[hash]
+	return f, ok
+}
+
+func (p *Peer) setFile(f *availableFile) {
+	p.mutex.Lock()
+	p.files[f.hash] = f
+	p.mutex.Unlock()
+}
+
+func (p *Peer) removeFile(hash string) {
+	p.mutex.Lock()
+	delete(p.files, hash)
+	p.mutex.Unlock()
+}
+
+// addresses - копия текущих адресов пира
+func (p *Peer) addresses() []string {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return append([]string(nil), p.addrs...)
+}
+
+// setAddresses обновляет адреса и время последнего обращения
+func (p *Peer) setAddresses(addrs []string) {
+	p.mutex.Lock()
+	p.addrs = addrs
+	p.seen = time.Now()
+	p.mutex.Unlock()
+}
+
+// lastSeen - когда пир последний раз обращался к трекеру
+func (p *Peer) lastSeen() time.Time {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.seen
+}
+
+// identity - кто прислал запрос: peer_id, ключ подписи и объявленные адреса
+type identity struct {
+	id    uuid.UUID
+	key   ed25519.PublicKey
+	addrs []string
+}
+
+// getPeerFromContext возвращает личность пира, проверенную перехватчиком по подписи.
+// bodyID - peer_id из тела запроса, если он там есть, он должен совпадать с подписанным.
+func getPeerFromContext(ctx context.Context, bodyID string) (*identity, error) {
+	signed, ok := ctx.Value(identityKey{}).(*auth.Identity)
+	if !ok {
+		return nil, errors.New("unauthenticated request")
+	}
+
+	if bodyID != "" && bodyID != signed.PeerID {
+		return nil, errors.New("peer id in request doesnt match signature")
+	}
+
+	id, err := uuid.Parse(signed.PeerID)
+	if err != nil {
+		return nil, errors.New("invalid peer id")
+	}
+
+	// адресов может быть несколько, через запятую
+	var addrs []string
+	for _, a := range strings.Split(signed.Address, ",") {
+		a = strings.TrimSpace(a)
+		if a != "" {
+			addrs = append(addrs, a)
+		}
+	}
+
+	if len(addrs) == 0 {
+		return nil, errors.New("missing peer address")
+	}
+
+	return &identity{id: id, key: signed.PublicKey, addrs: addrs}, nil
+}
+
+// session возвращает сессию пира, создавая ее при первом обращении,
+// и обновляет объявленные адреса. Адреса пир объявляет сам, поэтому меняет
+// он только свои: чужая сессия с тем же адресом остается в роях как была.
+func (s *Server) session(ident *identity) *Peer {
+	s.mutex.Lock()
+	p, ok := s.peers[ident.id]
+	if !ok {
+		p = NewPeer(ident.id, nil)
+		s.peers[ident.id] = p
+	}
+	s.mutex.Unlock()
+
+	p.setAddresses(ident.addrs)
+
+	return p
+}
+
+// clientSession - сессия BitTorrent-клиента. Такие клиенты не подписывают запросы,
+// их сессии хранятся отдельно от пиров и ничего в них не меняют.
+// Сессии клиентов, которые давно не объявлялись, забываются.
+func (s *Server) clientSession(id uuid.UUID, peerID []byte, addr string) *Peer {
+	s.mutex.Lock()
+	s.sweepClients(time.Now())
+
+	p, ok := s.clients[id]
+	if !ok {
+		p = NewPeer(id, nil)
+		p.clientID = append([]byte(nil), peerID...)
+		s.clients[id] = p
+	}
+	s.mutex.Unlock()
+
+	p.setAddresses([]string{addr})
+
+	return p
+}
+
+// sweepClients забывает клиентов, молчащих дольше clientTTL. Из роев их убирает expireClients:
+// объявление по любому файлу не старше последнего обращения, так что и там они уже устарели.
+// Вызывается под mutex сервера.
+func (s *Server) sweepClients(now time.Time) {
+	if now.Sub(s.clientsSwept) < clientTTL/2 {
+		return
+	}
+
+	for id, p := range s.clients {
+		if now.Sub(p.lastSeen()) > clientTTL {
+			delete(s.clients, id)
+		}
+	}
+
+	s.clientsSwept = now
+}
+
+// clientPeerID - peer_id BitTorrent-клиента как он его прислал,
+// у пиров с подписью это их peer_id
+func (s *Server) clientPeerID(id string) string {
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return id
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if p, ok := s.clients[uid]; ok {
+		return string(p.clientID)
+	}
+
+	return id
+}
+
+func (s *Server) getPeer(id uuid.UUID) (*Peer, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	p, ok := s.peers[id]
+	return p, ok
+}
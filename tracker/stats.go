@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"sort"
+
+	"github.com/elizarpif/grpctorrent/api"
+)
+
+// Scrape отдает состояние роев: сколько сидов и личеров, сколько раз файл скачали
+// и у скольких пиров есть каждый кусок. Видны только файлы, доступные запросившему.
+func (s *Server) Scrape(ctx context.Context, req *api.ScrapeRequest) (*api.ScrapeResponse, error) {
+	id, groups := s.requester(ctx)
+
+	hashes := req.Hashes
+	if len(hashes) == 0 {
+		s.mutex.RLock()
+		for hash := range s.swarms {
+			hashes = append(hashes, hash)
+		}
+		s.mutex.RUnlock()
+
+		sort.Strings(hashes)
+	}
+
+	resp := &api.ScrapeResponse{}
+
+	for _, hash := range hashes {
+		sw := s.getSwarm(hash, false)
+		if sw == nil {
+			continue
+		}
+
+		// без списка хэшей - как в каталоге, по хэшу - как в GetFileInfo
+		if len(req.Hashes) == 0 && !sw.listed(id, groups) {
+			continue
+		}
+		if _, allowed := sw.access(id, groups); !allowed {
+			continue
+		}
+
+		seeders, leechers, completed := sw.stats(hash)
+
+		resp.Files = append(resp.Files, &api.SwarmStats{
+			Hash:         hash,
+			Seeders:      uint64(seeders),
+			Leechers:     uint64(leechers),
+			Completed:    uint64(completed),
+			Availability: sw.availability(hash),
+		})
+	}
+
+	return resp, nil
+}
+
+// availability - у скольких пиров роя есть каждый кусок; пока файл не загружен, пусто
+func (sw *swarm) availability(hash string) []uint64 {
+	sw.mutex.RLock()
+	defer sw.mutex.RUnlock()
+
+	if sw.info == nil {
+		return nil
+	}
+
+	counts := make([]uint64, sw.info.Pieces)
+
+	for _, p := range sw.peers {
+		f, ok := p.file(hash)
+		if !ok {
+			continue
+		}
+
+		for piece := range f.pieces {
+			if uint64(piece) < sw.info.Pieces {
+				counts[piece]++
+			}
+		}
+	}
+
+	return counts
+}
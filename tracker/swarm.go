@@ -0,0 +1,263 @@
+package main
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/elizarpif/grpctorrent/api"
+	"github.com/elizarpif/grpctorrent/tracker/selection"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
+)
+
+// swarm - рой одного файла со своей блокировкой,
+// запросы по разным файлам не мешают друг другу
+type swarm struct {
+	info    *api.FileInfo // nil, пока файл не загружен на трекер; заменяется целиком, на месте не меняется
+	peers   []*Peer       // пиры с подписью, раздающие файл, в порядке появления
+	clients []*Peer       // BitTorrent-клиенты из http и udp announce, пирам они не отдаются
+
+	// права доступа к файлу
+	owner         uuid.UUID // кто первым загрузил файл
+	visibility    api.Visibility
+	allowedPeers  map[string]bool // peer_id, которым разрешено скачивание приватного файла
+	allowedGroups map[string]bool // группы пиров, которым разрешено скачивание приватного файла
+
+	uploaded        time.Time // когда загружено текущее описание
+	completed       int       // сколько раз файл скачали целиком пиры
+	clientCompleted int       // и BitTorrent-клиенты
+	confirmed       bool      // другой пир загрузил файл с теми же кусками, описание больше не заменяется
+
+	mutex *sync.RWMutex // защищает поля роя и куски availableFile этого файла
+}
+
+func newSwarm() *swarm {
+	return &swarm{
+		allowedPeers:  make(map[string]bool),
+		allowedGroups: make(map[string]bool),
+		mutex:         &sync.RWMutex{},
+	}
+}
+
+// authorized - может ли пир участвовать в рое. Вызывается под мьютексом роя.
+func (sw *swarm) authorized(id uuid.UUID, groups []string) bool {
+	if sw.visibility != api.Visibility_PRIVATE || id == sw.owner || sw.allowedPeers[id.String()] {
+		return true
+	}
+
+	for _, g := range groups {
+		if sw.allowedGroups[g] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// listed - показывать ли файл пиру в каталоге. Анонимный запрос приходит с uuid.Nil.
+func (sw *swarm) listed(id uuid.UUID, groups []string) bool {
+	sw.mutex.RLock()
+	defer sw.mutex.RUnlock()
+
+	switch sw.visibility {
+	case api.Visibility_PUBLIC:
+		return true
+	case api.Visibility_UNLISTED:
+		return id == sw.owner
+	default:
+		return sw.authorized(id, groups)
+	}
+}
+
+// access возвращает, приватный ли рой и допущен ли в него пир
+func (sw *swarm) access(id uuid.UUID, groups []string) (private, allowed bool) {
+	sw.mutex.RLock()
+	defer sw.mutex.RUnlock()
+
+	return sw.visibility == api.Visibility_PRIVATE, sw.authorized(id, groups)
+}
+
+// setACL заменяет права доступа к файлу. Вызывается под мьютексом роя.
+func (sw *swarm) setACL(owner uuid.UUID, visibility api.Visibility, peers, groups []string) {
+	sw.owner = owner
+	sw.visibility = visibility
+
+	sw.allowedPeers = make(map[string]bool, len(peers))
+	for _, id := range peers {
+		sw.allowedPeers[id] = true
+	}
+
+	sw.allowedGroups = make(map[string]bool, len(groups))
+	for _, g := range groups {
+		sw.allowedGroups[g] = true
+	}
+
+	if sw.info != nil {
+		info := proto.Clone(sw.info).(*api.FileInfo)
+		info.Private = visibility == api.Visibility_PRIVATE
+		info.Visibility = visibility
+		sw.info = info
+	}
+}
+
+// acl - права доступа к файлу в виде ответа админского API
+func (sw *swarm) acl(hash string) *api.FileACL {
+	sw.mutex.RLock()
+	defer sw.mutex.RUnlock()
+
+	return &api.FileACL{
+		Hash:          hash,
+		Owner:         sw.owner.String(),
+		Visibility:    sw.visibility,
+		AllowedPeers:  sortedKeys(sw.allowedPeers),
+		AllowedGroups: sortedKeys(sw.allowedGroups),
+	}
+}
+
+func (sw *swarm) fileInfo() *api.FileInfo {
+	sw.mutex.RLock()
+	defer sw.mutex.RUnlock()
+
+	return sw.info
+}
+
+// candidates собирает раздающих пиров с их кусками
+func (sw *swarm) candidates(hash string) ([]selection.Candidate, error) {
+	sw.mutex.RLock()
+	defer sw.mutex.RUnlock()
+
+	return collectCandidates(hash, sw.peers)
+}
+
+// clientCandidates - то же по BitTorrent-клиентам
+func (sw *swarm) clientCandidates(hash string) ([]selection.Candidate, error) {
+	sw.mutex.RLock()
+	defer sw.mutex.RUnlock()
+
+	return collectCandidates(hash, sw.clients)
+}
+
+// collectCandidates вызывается под мьютексом роя
+func collectCandidates(hash string, peers []*Peer) ([]selection.Candidate, error) {
+	candidates := make([]selection.Candidate, 0, len(peers))
+	for _, p := range peers {
+		is, ok := p.file(hash)
+		if !ok {
+			return nil, errors.New("files in peer doesnt exist!")
+		}
+
+		addrs := p.addresses()
+		if len(addrs) == 0 {
+			continue
+		}
+
+		candidate := selection.Candidate{
+			ID:     p.id.String(),
+			Addr:   addrs[0],
+			Addrs:  addrs,
+			Seeder: is.seeder,
+			Age:    time.Since(p.lastSeen()),
+		}
+		for k := range is.pieces {
+			candidate.Pieces = append(candidate.Pieces, uint64(k))
+		}
+
+		candidates = append(candidates, candidate)
+	}
+
+	return candidates, nil
+}
+
+// markSeeder отмечает пира сидом, когда у него собрались все куски.
+// Вызывается под мьютексом роя.
+func (sw *swarm) markSeeder(f *availableFile) {
+	if f.seeder || sw.info == nil || uint64(len(f.pieces)) < sw.info.Pieces {
+		return
+	}
+
+	f.seeder = true
+	sw.completed++
+}
+
+// describe задает новое описание файла. Вызывается под мьютексом роя.
+func (sw *swarm) describe(info *api.FileInfo) {
+	sw.info = info
+	sw.uploaded = time.Now()
+}
+
+// replace освобождает рой под новое описание файла: раздающие старое
+// описание из роя убираются. Вызывается под мьютексом роя.
+func (sw *swarm) replace(hash string) {
+	for _, p := range append(sw.peers, sw.clients...) {
+		p.removeFile(hash)
+	}
+
+	sw.peers = nil
+	sw.clients = nil
+	sw.completed = 0
+	sw.clientCompleted = 0
+	sw.confirmed = false
+}
+
+// removeClient убирает BitTorrent-клиента из роя. Вызывается под мьютексом роя.
+func (sw *swarm) removeClient(id uuid.UUID, hash string) {
+	for i, p := range sw.clients {
+		if p.id == id {
+			p.removeFile(hash)
+			sw.clients = append(sw.clients[:i:i], sw.clients[i+1:]...)
+			return
+		}
+	}
+}
+
+// expireClients убирает клиентов, которые давно не объявлялись в этом рое.
+// Вызывается под мьютексом роя.
+func (sw *swarm) expireClients(hash string, now time.Time) {
+	alive := sw.clients[:0]
+	for _, p := range sw.clients {
+		if f, ok := p.file(hash); ok && now.Sub(f.seen) <= clientTTL {
+			alive = append(alive, p)
+			continue
+		}
+
+		p.removeFile(hash)
+	}
+
+	// хвост обнуляем, чтобы убранные сессии не держались в памяти
+	for i := len(alive); i < len(sw.clients); i++ {
+		sw.clients[i] = nil
+	}
+	sw.clients = alive
+}
+
+// stats - сколько в рое сидов и личеров среди пиров и сколько раз они скачали файл целиком
+func (sw *swarm) stats(hash string) (seeders, leechers, completed int) {
+	sw.mutex.RLock()
+	defer sw.mutex.RUnlock()
+
+	seeders, leechers = countSeeders(hash, sw.peers)
+	return seeders, leechers, sw.completed
+}
+
+// clientStats - то же по BitTorrent-клиентам
+func (sw *swarm) clientStats(hash string) (seeders, leechers, completed int) {
+	sw.mutex.RLock()
+	defer sw.mutex.RUnlock()
+
+	seeders, leechers = countSeeders(hash, sw.clients)
+	return seeders, leechers, sw.clientCompleted
+}
+
+// countSeeders вызывается под мьютексом роя
+func countSeeders(hash string, peers []*Peer) (seeders, leechers int) {
+	for _, p := range peers {
+		if f, ok := p.file(hash); ok && f.seeder {
+			seeders++
+		} else {
+			leechers++
+		}
+	}
+
+	return seeders, leechers
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/elizarpif/grpctorrent/api"
+	"github.com/elizarpif/grpctorrent/api/auth"
+	"github.com/golang/protobuf/ptypes/empty"
+)
+
+// сколько действует токен доступа к приватному файлу
+const tokenTTL = time.Hour
+
+// loadTokenKey читает seed ключа подписи токенов из файла, при отсутствии файла создает его.
+// С пустым путем ключ живет до перезапуска трекера.
+func loadTokenKey(path string) (ed25519.PrivateKey, error) {
+	if path == "" {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		return key, err
+	}
+
+	seed, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+
+		return key, ioutil.WriteFile(path, key.Seed(), 0o600)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(seed) != ed25519.SeedSize {
+		return nil, errors.New("invalid token key in " + path)
+	}
+
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// issueToken выдает пиру токен на скачивание кусков файла. Раздающий примет токен
+// только в запросе, подписанном тем же ключом, которым пир подписал GetPeers.
+func (s *Server) issueToken(hash string, ident *identity) (string, time.Time) {
+	expires := time.Now().Add(tokenTTL)
+
+	return auth.IssueToken(s.tokenKey, &auth.AccessToken{
+		Hash:      hash,
+		PeerID:    ident.id.String(),
+		PublicKey: ident.key,
+		Expires:   expires,
+	}), expires
+}
+
+func (s *Server) GetTrackerKey(ctx context.Context, e *empty.Empty) (*api.TrackerKey, error) {
+	return &api.TrackerKey{PublicKey: s.tokenKey.Public().(ed25519.PublicKey)}, nil
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/elizarpif/logger"
+)
+
+// udp-трекер по BEP 15: connect выдает connection_id, с ним клиент
+// объявляется и спрашивает статистику одним datagram-запросом
+const (
+	udpProtocolID = 0x41727101980
+
+	udpActionConnect  = 0
+	udpActionAnnounce = 1
+	udpActionScrape   = 2
+	udpActionError    = 3
+
+	udpHeaderLen   = 16 // connection_id, action и transaction_id
+	udpAnnounceLen = 98
+
+	// connection_id живет от одного до двух таких периодов
+	udpConnectionTTL = time.Minute
+
+	// больше хэшей в одном scrape не поместится в ответ
+	udpMaxScrape = 74
+)
+
+// события announce в порядке номеров протокола
+var udpEvents = []string{"", "completed", "started", "stopped"}
+
+type udpTracker struct {
+	server *Server
+	conn   net.PacketConn
+	secret []byte // ключ, которым подписываются connection_id
+}
+
+func newUDPTracker(server *Server, conn net.PacketConn) (*udpTracker, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+
+	return &udpTracker{server: server, conn: conn, secret: secret}, nil
+}
+
+// serve обрабатывает запросы, пока соединение не закроют
+func (t *udpTracker) serve(ctx context.Context) error {
+	buf := make([]byte, 2048)
+
+	for {
+		n, addr, err := t.conn.ReadFrom(buf)
+		if err != nil {
+			return err
+		}
+
+		resp := t.handle(ctx, buf[:n], addr)
+		if resp == nil {
+			continue
+		}
+
+		if _, err := t.conn.WriteTo(resp, addr); err != nil {
+			logger.GetLogger(ctx).WithError(err).WithField("remote", addr.String()).Error("cannot write udp response")
+		}
+	}
+}
+
+// handle возвращает ответ на пакет или nil, если отвечать не на что
+func (t *udpTracker) handle(ctx context.Context, req []byte, addr net.Addr) []byte {
+	if len(req) < udpHeaderLen {
+		return nil
+	}
+
+	connID := binary.BigEndian.Uint64(req[0:8])
+	action := binary.BigEndian.Uint32(req[8:12])
+	tid := req[12:16]
+
+	if action == udpActionConnect {
+		if connID != udpProtocolID {
+			return nil
+		}
+
+		resp := make([]byte, 16)
+		binary.BigEndian.PutUint32(resp[0:4], udpActionConnect)
+		copy(resp[4:8], tid)
+		binary.BigEndian.PutUint64(resp[8:16], t.connectionID(addr, time.Now()))
+
+		return resp
+	}
+
+	if !t.validConnection(connID, addr) {
+		return udpError(tid, "invalid connection id")
+	}
+
+	switch action {
+	case udpActionAnnounce:
+		return t.announce(ctx, req, tid, addr)
+	case udpActionScrape:
+		return t.scrape(req, tid)
+	default:
+		return udpError(tid, "unknown action")
+	}
+}
+
+func (t *udpTracker) announce(ctx context.Context, req, tid []byte, addr net.Addr) []byte {
+	if len(req) < udpAnnounceLen {
+		return udpError(tid, "short announce")
+	}
+
+	event := binary.BigEndian.Uint32(req[80:84])
+	if int(event) >= len(udpEvents) {
+		return udpError(tid, "unknown event")
+	}
+
+	ip, ok := udpIP(addr)
+	if !ok {
+		return udpError(tid, "invalid remote address")
+	}
+
+	port := binary.BigEndian.Uint16(req[96:98])
+	if port == 0 {
+		return udpError(tid, "invalid port")
+	}
+
+	res, err := t.server.announce(&announce{
+		hash:    hex.EncodeToString(req[16:36]),
+		peerID:  req[36:56],
+		addr:    net.JoinHostPort(ip.String(), strconv.Itoa(int(port))),
+		left:    binary.BigEndian.Uint64(req[64:72]),
+		event:   udpEvents[event],
+		numWant: int(int32(binary.BigEndian.Uint32(req[92:96]))),
+	})
+	if err != nil {
+		logger.GetLogger(ctx).WithError(err).WithField("remote", addr.String()).Error("udp announce failed")
+		return udpError(tid, err.Error())
+	}
+
+	resp := make([]byte, 20, 20+6*len(res.peers))
+	binary.BigEndian.PutUint32(resp[0:4], udpActionAnnounce)
+	copy(resp[4:8], tid)
+	binary.BigEndian.PutUint32(resp[8:12], announceInterval)
+	binary.BigEndian.PutUint32(resp[12:16], uint32(res.leechers))
+	binary.BigEndian.PutUint32(resp[16:20], uint32(res.seeders))
+
+	// клиенту по IPv4 отдаем IPv4-адреса, по IPv6 - IPv6
+	v4, v6 := compactPeers(res.peers)
+	if ip.To4() != nil {
+		return append(resp, v4...)
+	}
+
+	return append(resp, v6...)
+}
+
+func (t *udpTracker) scrape(req, tid []byte) []byte {
+	hashes := req[udpHeaderLen:]
+	if len(hashes)%btIDLen != 0 || len(hashes)/btIDLen > udpMaxScrape {
+		return udpError(tid, "invalid info hashes")
+	}
+
+	resp := make([]byte, 8, 8+12*len(hashes)/btIDLen)
+	binary.BigEndian.PutUint32(resp[0:4], udpActionScrape)
+	copy(resp[4:8], tid)
+
+	for i := 0; i < len(hashes); i += btIDLen {
+		// неизвестный и приватный рой выглядят одинаково - пустыми
+		seeders, leechers, completed, _ := t.server.scrape(hex.EncodeToString(hashes[i : i+btIDLen]))
+
+		entry := make([]byte, 12)
+		binary.BigEndian.PutUint32(entry[0:4], uint32(seeders))
+		binary.BigEndian.PutUint32(entry[4:8], uint32(completed))
+		binary.BigEndian.PutUint32(entry[8:12], uint32(leechers))
+		resp = append(resp, entry...)
+	}
+
+	return resp
+}
+
+// connectionID - подпись адреса клиента и периода времени, хранить выданные id не нужно
+func (t *udpTracker) connectionID(addr net.Addr, now time.Time) uint64 {
+	period := now.Unix() / int64(udpConnectionTTL/time.Second)
+
+	mac := hmac.New(sha256.New, t.secret)
+	mac.Write([]byte(addr.String()))
+	_ = binary.Write(mac, binary.BigEndian, period)
+
+	return binary.BigEndian.Uint64(mac.Sum(nil))
+}
+
+// validConnection принимает id текущего и предыдущего периода
+func (t *udpTracker) validConnection(id uint64, addr net.Addr) bool {
+	now := time.Now()
+
+	return id == t.connectionID(addr, now) || id == t.connectionID(addr, now.Add(-udpConnectionTTL))
+}
+
+func udpIP(addr net.Addr) (net.IP, bool) {
+	udp, ok := addr.(*net.UDPAddr)
+	if !ok || udp.IP == nil {
+		return nil, false
+	}
+
+	return udp.IP, true
+}
+
+func udpError(tid []byte, msg string) []byte {
+	resp := make([]byte, 8, 8+len(msg))
+	binary.BigEndian.PutUint32(resp[0:4], udpActionError)
+	copy(resp[4:8], tid)
+
+	return append(resp, msg...)
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elizarpif/grpctorrent/api"
+)
+
+// udpClient - клиент udp-трекера на петлевом сокете
+type udpClient struct {
+	t    *testing.T
+	conn *net.UDPConn
+	tid  uint32
+}
+
+// startUDPTracker запускает udp-трекер на свободном петлевом порту
+func startUDPTracker(t *testing.T, s *Server) (*udpTracker, *udpClient) {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("udp is unavailable: %v", err)
+	}
+
+	tracker, err := newUDPTracker(s, conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() { _ = tracker.serve(context.Background()) }()
+	t.Cleanup(func() { conn.Close() })
+
+	client, err := net.DialUDP("udp", nil, conn.LocalAddr().(*net.UDPAddr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	return tracker, &udpClient{t: t, conn: client}
+}
+
+// request отправляет пакет с заголовком connID, action и новым transaction_id
+// и возвращает действие и тело ответа; transaction_id ответа сверяется
+func (c *udpClient) request(connID uint64, action uint32, body []byte) (uint32, []byte) {
+	c.t.Helper()
+
+	c.tid++
+
+	req := make([]byte, udpHeaderLen, udpHeaderLen+len(body))
+	binary.BigEndian.PutUint64(req[0:8], connID)
+	binary.BigEndian.PutUint32(req[8:12], action)
+	binary.BigEndian.PutUint32(req[12:16], c.tid)
+
+	if _, err := c.conn.Write(append(req, body...)); err != nil {
+		c.t.Fatal(err)
+	}
+
+	_ = c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	buf := make([]byte, 2048)
+	n, err := c.conn.Read(buf)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+
+	if n < 8 {
+		c.t.Fatalf("short response %x", buf[:n])
+	}
+	if tid := binary.BigEndian.Uint32(buf[4:8]); tid != c.tid {
+		c.t.Fatalf("transaction id %d, want %d", tid, c.tid)
+	}
+
+	return binary.BigEndian.Uint32(buf[0:4]), buf[8:n]
+}
+
+func (c *udpClient) connect() uint64 {
+	c.t.Helper()
+
+	action, body := c.request(udpProtocolID, udpActionConnect, nil)
+	if action != udpActionConnect || len(body) != 8 {
+		c.t.Fatalf("connect: action %d, body %x", action, body)
+	}
+
+	return binary.BigEndian.Uint64(body)
+}
+
+// announceBody - тело announce по BEP 15 после заголовка
+func announceBody(peerID string, left uint64, event uint32, port uint16) []byte {
+	b := make([]byte, udpAnnounceLen-udpHeaderLen)
+	copy(b[0:20], testInfoHash)
+	copy(b[20:40], peerID)
+	binary.BigEndian.PutUint64(b[48:56], left)
+	binary.BigEndian.PutUint32(b[64:68], event)
+	binary.BigEndian.PutUint32(b[76:80], ^uint32(0)) // num_want -1 - по умолчанию
+	binary.BigEndian.PutUint16(b[80:82], port)
+
+	return b
+}
+
+func TestUDPTracker(t *testing.T) {
+	s, _ := torrentServer(t, api.Visibility_PUBLIC)
+	_, c := startUDPTracker(t, s)
+
+	connID := c.connect()
+
+	// сид объявляется, пиров ему пока нет
+	action, body := c.request(connID, udpActionAnnounce, announceBody(newBTClient(1).peerID, 0, 2, 6881))
+	if action != udpActionAnnounce || len(body) != 12 {
+		t.Fatalf("announce: action %d, body %x", action, body)
+	}
+	if interval, leechers, seeders := binary.BigEndian.Uint32(body[0:4]), binary.BigEndian.Uint32(body[4:8]),
+		binary.BigEndian.Uint32(body[8:12]); interval != announceInterval || leechers != 0 || seeders != 1 {
+		t.Errorf("interval %d, leechers %d, seeders %d", interval, leechers, seeders)
+	}
+
+	// второй клиент получает первого: 127.0.0.1 и порт из запроса, а не порт сокета
+	action, body = c.request(connID, udpActionAnnounce, announceBody(newBTClient(2).peerID, 100, 0, 6882))
+	if action != udpActionAnnounce || string(body[12:]) != "\x7f\x00\x00\x01\x1a\xe1" {
+		t.Errorf("peers %x, want 127.0.0.1:6881", body[12:])
+	}
+
+	// scrape известного и неизвестного хэша: complete, downloaded, incomplete по 4 байта
+	action, body = c.request(connID, udpActionScrape, []byte(testInfoHash+strings.Repeat("\xcd", btIDLen)))
+	if action != udpActionScrape || len(body) != 24 {
+		t.Fatalf("scrape: action %d, body %x", action, body)
+	}
+	if string(body[:12]) != "\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x01" || string(body[12:]) != strings.Repeat("\x00", 12) {
+		t.Errorf("scrape = %x", body)
+	}
+
+	// stopped убирает клиента
+	c.request(connID, udpActionAnnounce, announceBody(newBTClient(2).peerID, 100, 3, 6882))
+	_, body = c.request(connID, udpActionScrape, []byte(testInfoHash))
+	if binary.BigEndian.Uint32(body[8:12]) != 0 {
+		t.Errorf("leechers after stopped: %x", body)
+	}
+}
+
+func TestUDPTrackerErrors(t *testing.T) {
+	s, _ := torrentServer(t, api.Visibility_PUBLIC)
+	tracker, c := startUDPTracker(t, s)
+
+	connID := c.connect()
+	addr := c.conn.LocalAddr()
+
+	tests := []struct {
+		name   string
+		connID uint64
+		action uint32
+		body   []byte
+	}{
+		{name: "made up connection id", connID: connID + 1, action: udpActionAnnounce, body: announceBody("-XX0001-000000000001", 0, 0, 6881)},
+		{name: "expired connection id", connID: tracker.connectionID(addr, time.Now().Add(-2*udpConnectionTTL)), action: udpActionScrape, body: []byte(testInfoHash)},
+		{name: "connection id of another client", connID: tracker.connectionID(&net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1}, time.Now()), action: udpActionScrape, body: []byte(testInfoHash)},
+		{name: "unknown action", connID: connID, action: 7},
+		{name: "short announce", connID: connID, action: udpActionAnnounce, body: make([]byte, 10)},
+		{name: "unknown event", connID: connID, action: udpActionAnnounce, body: announceBody("-XX0001-000000000001", 0, 4, 6881)},
+		{name: "zero port", connID: connID, action: udpActionAnnounce, body: announceBody("-XX0001-000000000001", 0, 0, 0)},
+		{name: "unknown file", connID: connID, action: udpActionAnnounce, body: func() []byte {
+			b := announceBody("-XX0001-000000000001", 0, 0, 6881)
+			copy(b, strings.Repeat("\xcd", btIDLen))
+			return b
+		}()},
+		{name: "cut info hash", connID: connID, action: udpActionScrape, body: []byte(testInfoHash[:10])},
+		{name: "too many hashes", connID: connID, action: udpActionScrape, body: []byte(strings.Repeat(testInfoHash, udpMaxScrape+1))},
+	}
+
+	for _, tt := range tests {
+		action, body := c.request(tt.connID, tt.action, tt.body)
+		if action != udpActionError || len(body) == 0 {
+			t.Errorf("%s: action %d, body %q, want an error", tt.name, action, body)
+		}
+	}
+
+	// предыдущий период еще действует, и scrape на пределе принимается
+	previous := tracker.connectionID(addr, time.Now().Add(-udpConnectionTTL))
+	if action, body := c.request(previous, udpActionScrape, []byte(strings.Repeat(testInfoHash, udpMaxScrape))); action != udpActionScrape || len(body) != 12*udpMaxScrape {
+		t.Errorf("previous period: action %d, %d bytes", action, len(body))
+	}
+}
+
+func TestUDPTrackerIgnores(t *testing.T) {
+	s, _ := torrentServer(t, api.Visibility_PUBLIC)
+	tracker, _ := startUDPTracker(t, s)
+
+	addr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 6881}
+
+	// на обрывок и на connect без protocol_id трекер не отвечает вовсе
+	connect := make([]byte, udpHeaderLen)
+	binary.BigEndian.PutUint32(connect[8:12], udpActionConnect)
+
+	for name, req := range map[string][]byte{"short packet": make([]byte, 15), "connect without protocol id": connect} {
+		if resp := tracker.handle(context.Background(), req, addr); resp != nil {
+			t.Errorf("%s: response %x", name, resp)
+		}
+	}
+}